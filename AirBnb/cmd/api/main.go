@@ -1,6 +1,7 @@
 package main
 
 import (
+	"AirBnb/internal/calendarsync"
 	"AirBnb/internal/database"
 	"AirBnb/internal/server"
 	"context"
@@ -53,6 +54,22 @@ func main() {
 		}
 	}()
 
+	// Import the external calendars of every property in the background
+	syncInterval := calendarsync.DefaultInterval
+	if value := os.Getenv("CALENDAR_SYNC_INTERVAL"); value != "" {
+		interval, err := time.ParseDuration(value)
+		if err != nil || interval <= 0 {
+			log.Fatal("Invalid CALENDAR_SYNC_INTERVAL:", value)
+		}
+		syncInterval = interval
+	}
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	go calendarsync.New(db).Run(backgroundCtx, syncInterval)
+
+	// Remind guests of their stay the day before check-in
+	go server.Mailer().RunCheckInReminders(backgroundCtx, db, time.Hour)
+
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
@@ -69,5 +86,9 @@ func main() {
 
 	// Wait for the graceful shutdown to complete
 	<-done
+
+	// Stop the background jobs and deliver the emails that are still queued
+	stopBackground()
+	server.Mailer().Close()
 	log.Println("Graceful shutdown complete.")
 }
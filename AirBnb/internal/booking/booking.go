@@ -0,0 +1,140 @@
+// Package booking checks a requested stay against the booking rules a host set on a property.
+package booking
+
+import (
+	"AirBnb/internal/models"
+	"AirBnb/internal/utils"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Rule names reported by RuleError.
+const (
+	RuleMinNights    = "min_nights"
+	RuleMaxNights    = "max_nights"
+	RuleCheckInDay   = "check_in_day"
+	RuleBlockedDates = "blocked_dates"
+)
+
+// RuleError explains which booking rule a stay breaks. It is meant to be sent to the guest as is.
+type RuleError struct {
+	Rule    string   `json:"rule"`
+	Message string   `json:"message"`
+	Limit   int      `json:"limit,omitempty"`
+	Allowed []string `json:"allowed,omitempty"`
+	// Err is an optional sentinel error the rule error wraps
+	Err error `json:"-"`
+}
+
+func (e *RuleError) Error() string {
+	return e.Message
+}
+
+func (e *RuleError) Unwrap() error {
+	return e.Err
+}
+
+// Rules are the stay restrictions of a property. A MaxNights of 0 means no limit and an empty
+// CheckInDays allows every weekday.
+type Rules struct {
+	MinNights   int
+	MaxNights   int
+	CheckInDays []time.Weekday
+}
+
+// RulesFromProperty builds the stay rules of a property. Unknown weekday names are ignored.
+func RulesFromProperty(property models.Property) Rules {
+	days, _ := ParseWeekdays(SplitWeekdays(property.CheckInDays))
+	return Rules{
+		MinNights:   property.MinNights,
+		MaxNights:   property.MaxNights,
+		CheckInDays: days,
+	}
+}
+
+// Check returns a *RuleError for the first rule the stay from start to end breaks, or nil.
+func (r Rules) Check(start, end time.Time) error {
+	nights := utils.NightsBetween(start, end)
+
+	if r.MinNights > 1 && nights < r.MinNights {
+		return &RuleError{
+			Rule:    RuleMinNights,
+			Message: fmt.Sprintf("This property requires a stay of at least %d nights", r.MinNights),
+			Limit:   r.MinNights,
+		}
+	}
+
+	if r.MaxNights > 0 && nights > r.MaxNights {
+		return &RuleError{
+			Rule:    RuleMaxNights,
+			Message: fmt.Sprintf("This property allows a stay of at most %d nights", r.MaxNights),
+			Limit:   r.MaxNights,
+		}
+	}
+
+	if len(r.CheckInDays) > 0 {
+		checkIn := utils.TruncateToDay(start).Weekday()
+		for _, day := range r.CheckInDays {
+			if day == checkIn {
+				return nil
+			}
+		}
+
+		allowed := make([]string, 0, len(r.CheckInDays))
+		for _, day := range r.CheckInDays {
+			allowed = append(allowed, strings.ToLower(day.String()))
+		}
+		return &RuleError{
+			Rule:    RuleCheckInDay,
+			Message: fmt.Sprintf("Check-in is only possible on %s", strings.Join(allowed, ", ")),
+			Allowed: allowed,
+		}
+	}
+
+	return nil
+}
+
+// ParseWeekdays converts weekday names such as "friday" to weekdays, dropping duplicates.
+func ParseWeekdays(names []string) ([]time.Weekday, error) {
+	days := make([]time.Weekday, 0, len(names))
+	seen := make(map[time.Weekday]bool)
+	for _, name := range names {
+		day, ok := weekdays[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return nil, fmt.Errorf("%q is not a weekday", name)
+		}
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, day)
+		}
+	}
+	return days, nil
+}
+
+// FormatWeekdays is the inverse of ParseWeekdays and SplitWeekdays; it is how weekdays are stored on a property.
+func FormatWeekdays(days []time.Weekday) string {
+	names := make([]string, 0, len(days))
+	for _, day := range days {
+		names = append(names, strings.ToLower(day.String()))
+	}
+	return strings.Join(names, ",")
+}
+
+// SplitWeekdays splits the stored comma separated weekday names.
+func SplitWeekdays(value string) []string {
+	if value == "" {
+		return nil
+	}
+	return strings.Split(value, ",")
+}
+
+var weekdays = map[string]time.Weekday{
+	"sunday":    time.Sunday,
+	"monday":    time.Monday,
+	"tuesday":   time.Tuesday,
+	"wednesday": time.Wednesday,
+	"thursday":  time.Thursday,
+	"friday":    time.Friday,
+	"saturday":  time.Saturday,
+}
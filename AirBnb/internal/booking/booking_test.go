@@ -0,0 +1,80 @@
+package booking
+
+import (
+	"AirBnb/internal/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestCheck(t *testing.T) {
+	rules := RulesFromProperty(models.Property{MinNights: 2, MaxNights: 14, CheckInDays: "friday,saturday"})
+
+	// 7 March 2025 is a Friday
+	tests := []struct {
+		name  string
+		start time.Time
+		end   time.Time
+		rule  string
+	}{
+		{"valid stay", date(2025, 3, 7), date(2025, 3, 10), ""},
+		{"too short", date(2025, 3, 7), date(2025, 3, 8), RuleMinNights},
+		{"too long", date(2025, 3, 7), date(2025, 3, 22), RuleMaxNights},
+		{"wrong check-in day", date(2025, 3, 10), date(2025, 3, 13), RuleCheckInDay},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := rules.Check(test.start, test.end)
+			if test.rule == "" {
+				if err != nil {
+					t.Fatalf("expected no error, got %v", err)
+				}
+				return
+			}
+
+			var ruleErr *RuleError
+			if !errors.As(err, &ruleErr) {
+				t.Fatalf("expected a rule error, got %v", err)
+			}
+			if ruleErr.Rule != test.rule {
+				t.Errorf("expected rule %s, got %s", test.rule, ruleErr.Rule)
+			}
+		})
+	}
+}
+
+func TestCheckReportsLimits(t *testing.T) {
+	err := Rules{MinNights: 3}.Check(date(2025, 3, 7), date(2025, 3, 8))
+	var ruleErr *RuleError
+	if !errors.As(err, &ruleErr) || ruleErr.Limit != 3 {
+		t.Fatalf("expected the minimum of 3 nights to be reported, got %+v", err)
+	}
+
+	err = Rules{CheckInDays: []time.Weekday{time.Saturday}}.Check(date(2025, 3, 7), date(2025, 3, 8))
+	if !errors.As(err, &ruleErr) || len(ruleErr.Allowed) != 1 || ruleErr.Allowed[0] != "saturday" {
+		t.Fatalf("expected saturday to be the allowed check-in day, got %+v", err)
+	}
+
+	if err := (Rules{}).Check(date(2025, 3, 7), date(2025, 3, 8)); err != nil {
+		t.Fatalf("expected a property without rules to accept any stay, got %v", err)
+	}
+}
+
+func TestWeekdays(t *testing.T) {
+	days, err := ParseWeekdays([]string{"Friday", " saturday", "friday"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if got := FormatWeekdays(days); got != "friday,saturday" {
+		t.Errorf("expected friday,saturday, got %s", got)
+	}
+
+	if _, err := ParseWeekdays([]string{"someday"}); err == nil {
+		t.Error("expected an error for an unknown weekday")
+	}
+}
@@ -0,0 +1,182 @@
+// Package calendarsync imports the iCal feeds hosts register for their properties, so nights
+// booked on other platforms are blocked here.
+package calendarsync
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/ical"
+	"AirBnb/internal/models"
+	"AirBnb/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"syscall"
+	"time"
+)
+
+const (
+	// DefaultInterval is how often Run imports every calendar.
+	DefaultInterval = 30 * time.Minute
+	// maxFeedSize bounds how much of a feed is read, feeds of a single listing are far smaller.
+	maxFeedSize  = 5 * 1024 * 1024
+	fetchTimeout = 30 * time.Second
+	dialTimeout  = 10 * time.Second
+)
+
+// ErrForbiddenAddress is returned when a feed resolves to a loopback, private, link-local or
+// unspecified address, so hosts cannot make the server fetch internal services.
+var ErrForbiddenAddress = errors.New("calendar feeds must be served from a public address")
+
+// Store is the part of database.Service the syncer needs.
+type Store interface {
+	FindExternalCalendars() ([]models.ExternalCalendar, error)
+	ReplaceExternalCalendarBlocks(calendarID uint, blocks []database.BlockedDate, syncedAt time.Time) error
+	RecordExternalCalendarError(calendarID uint, syncErr string) error
+}
+
+type Syncer struct {
+	store  Store
+	client *http.Client
+	now    func() time.Time
+}
+
+func New(store Store) *Syncer {
+	return &Syncer{
+		store:  store,
+		client: newFeedClient(),
+		now:    time.Now,
+	}
+}
+
+// newFeedClient returns an HTTP client that only connects to public addresses. The check runs
+// on the resolved address of every connection, redirects included, so DNS names pointing at
+// internal addresses are refused too. Proxies are not used since they would hide the address.
+func newFeedClient() *http.Client {
+	dialer := &net.Dialer{Timeout: dialTimeout, Control: allowPublicAddress}
+	return &http.Client{
+		Timeout: fetchTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: dialTimeout,
+			MaxIdleConns:        10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+	}
+}
+
+func allowPublicAddress(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip := net.ParseIP(host)
+	if ip == nil || !isPublicIP(ip) {
+		return ErrForbiddenAddress
+	}
+	return nil
+}
+
+func isPublicIP(ip net.IP) bool {
+	return !ip.IsLoopback() &&
+		!ip.IsPrivate() &&
+		!ip.IsLinkLocalUnicast() &&
+		!ip.IsLinkLocalMulticast() &&
+		!ip.IsInterfaceLocalMulticast() &&
+		!ip.IsMulticast() &&
+		!ip.IsUnspecified()
+}
+
+// Run imports every calendar right away and then once per interval until ctx is cancelled.
+func (s *Syncer) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.SyncAll(ctx); err != nil {
+			log.Printf("calendar sync: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// SyncAll imports every registered calendar. A failing feed does not stop the others; the
+// returned error joins the failures.
+func (s *Syncer) SyncAll(ctx context.Context) error {
+	calendars, err := s.store.FindExternalCalendars()
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, calendar := range calendars {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := s.Sync(ctx, calendar); err != nil {
+			errs = append(errs, fmt.Errorf("calendar %d: %w", calendar.ID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Sync imports a single calendar. When the feed cannot be fetched or parsed, the blocks of the
+// previous import are kept and the error is recorded on the calendar.
+func (s *Syncer) Sync(ctx context.Context, calendar models.ExternalCalendar) error {
+	blocks, err := s.fetchBlocks(ctx, calendar.URL)
+	if err != nil {
+		if recordErr := s.store.RecordExternalCalendarError(calendar.ID, err.Error()); recordErr != nil {
+			return errors.Join(err, recordErr)
+		}
+		return err
+	}
+
+	return s.store.ReplaceExternalCalendarBlocks(calendar.ID, blocks, s.now().UTC())
+}
+
+func (s *Syncer) fetchBlocks(ctx context.Context, url string) ([]database.BlockedDate, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/calendar")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("feed returned status %d", resp.StatusCode)
+	}
+
+	events, err := ical.Parse(io.LimitReader(resp.Body, maxFeedSize))
+	if err != nil {
+		return nil, fmt.Errorf("invalid feed: %w", err)
+	}
+
+	// Events that are already over cannot block a booking
+	today := utils.TruncateToDay(s.now())
+	blocks := make([]database.BlockedDate, 0, len(events))
+	for _, event := range events {
+		if !event.End.After(today) {
+			continue
+		}
+		blocks = append(blocks, database.BlockedDate{
+			StartDate: event.Start,
+			EndDate:   event.End,
+			Summary:   event.Summary,
+			UID:       event.UID,
+		})
+	}
+	return blocks, nil
+}
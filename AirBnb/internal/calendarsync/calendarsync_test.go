@@ -0,0 +1,171 @@
+package calendarsync
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/models"
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+const feed = "BEGIN:VCALENDAR\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:past\r\n" +
+	"DTSTART;VALUE=DATE:20250101\r\n" +
+	"DTEND;VALUE=DATE:20250105\r\n" +
+	"END:VEVENT\r\n" +
+	"BEGIN:VEVENT\r\n" +
+	"UID:upcoming\r\n" +
+	"SUMMARY:Not available\r\n" +
+	"DTSTART;VALUE=DATE:20250610\r\n" +
+	"DTEND;VALUE=DATE:20250614\r\n" +
+	"END:VEVENT\r\n" +
+	"END:VCALENDAR\r\n"
+
+type fakeStore struct {
+	calendars []models.ExternalCalendar
+	blocks    map[uint][]database.BlockedDate
+	syncedAt  map[uint]time.Time
+	errors    map[uint]string
+}
+
+func newFakeStore(calendars ...models.ExternalCalendar) *fakeStore {
+	return &fakeStore{
+		calendars: calendars,
+		blocks:    make(map[uint][]database.BlockedDate),
+		syncedAt:  make(map[uint]time.Time),
+		errors:    make(map[uint]string),
+	}
+}
+
+func (s *fakeStore) FindExternalCalendars() ([]models.ExternalCalendar, error) {
+	return s.calendars, nil
+}
+
+func (s *fakeStore) ReplaceExternalCalendarBlocks(calendarID uint, blocks []database.BlockedDate, syncedAt time.Time) error {
+	s.blocks[calendarID] = blocks
+	s.syncedAt[calendarID] = syncedAt
+	delete(s.errors, calendarID)
+	return nil
+}
+
+func (s *fakeStore) RecordExternalCalendarError(calendarID uint, syncErr string) error {
+	s.errors[calendarID] = syncErr
+	return nil
+}
+
+// newTestSyncer uses a plain client since the feeds of the tests are served on the loopback address.
+func newTestSyncer(store Store) *Syncer {
+	syncer := New(store)
+	syncer.client = &http.Client{Timeout: fetchTimeout}
+	syncer.now = func() time.Time { return time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC) }
+	return syncer
+}
+
+func TestSyncAllImportsUpcomingEvents(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/calendar.ics":
+			w.Header().Set("Content-Type", "text/calendar")
+			w.Write([]byte(feed))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	store := newFakeStore(
+		models.ExternalCalendar{ID: 1, PropertyID: 10, URL: server.URL + "/calendar.ics"},
+		models.ExternalCalendar{ID: 2, PropertyID: 10, URL: server.URL + "/missing.ics"},
+	)
+	// The second calendar already has blocks from an earlier import
+	store.blocks[2] = []database.BlockedDate{{UID: "kept"}}
+
+	err := newTestSyncer(store).SyncAll(context.Background())
+	if err == nil {
+		t.Fatal("expected the failing feed to be reported")
+	}
+
+	blocks := store.blocks[1]
+	if len(blocks) != 1 {
+		t.Fatalf("expected only the upcoming event to be imported, got %+v", blocks)
+	}
+	if blocks[0].UID != "upcoming" || blocks[0].Summary != "Not available" {
+		t.Errorf("unexpected block %+v", blocks[0])
+	}
+	if !blocks[0].StartDate.Equal(time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)) || !blocks[0].EndDate.Equal(time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected 10 to 14 June, got %v to %v", blocks[0].StartDate, blocks[0].EndDate)
+	}
+	if store.syncedAt[1].IsZero() {
+		t.Error("expected the calendar to be marked as synced")
+	}
+
+	if store.errors[2] == "" {
+		t.Error("expected the error of the missing feed to be recorded")
+	}
+	if len(store.blocks[2]) != 1 || store.blocks[2][0].UID != "kept" {
+		t.Errorf("expected the blocks of a failed sync to be kept, got %+v", store.blocks[2])
+	}
+}
+
+func TestSyncRejectsInvalidFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("BEGIN:VEVENT\r\nDTSTART:tomorrow\r\nEND:VEVENT\r\n"))
+	}))
+	defer server.Close()
+
+	store := newFakeStore()
+	calendar := models.ExternalCalendar{ID: 3, URL: server.URL}
+	if err := newTestSyncer(store).Sync(context.Background(), calendar); err == nil {
+		t.Fatal("expected an error for an invalid feed")
+	}
+	if store.errors[3] == "" {
+		t.Error("expected the parse error to be recorded")
+	}
+	if _, synced := store.syncedAt[3]; synced {
+		t.Error("expected the calendar not to be marked as synced")
+	}
+}
+
+func TestSyncRefusesInternalAddresses(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(feed))
+	}))
+	defer server.Close()
+
+	store := newFakeStore()
+	calendar := models.ExternalCalendar{ID: 4, URL: server.URL}
+	err := New(store).Sync(context.Background(), calendar)
+	if !errors.Is(err, ErrForbiddenAddress) {
+		t.Fatalf("expected ErrForbiddenAddress for a loopback feed, got %v", err)
+	}
+	if store.errors[4] == "" {
+		t.Error("expected the refusal to be recorded")
+	}
+}
+
+func TestIsPublicIP(t *testing.T) {
+	tests := map[string]bool{
+		"93.184.216.34":    true,
+		"2606:4700::1111":  true,
+		"127.0.0.1":        false,
+		"10.1.2.3":         false,
+		"172.16.0.1":       false,
+		"192.168.1.1":      false,
+		"169.254.169.254":  false,
+		"0.0.0.0":          false,
+		"::1":              false,
+		"fe80::1":          false,
+		"fd00::1":          false,
+		"::ffff:127.0.0.1": false,
+	}
+	for address, want := range tests {
+		if got := isPublicIP(net.ParseIP(address)); got != want {
+			t.Errorf("isPublicIP(%s) = %v, want %v", address, got, want)
+		}
+	}
+}
@@ -0,0 +1,280 @@
+package controllers
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/mailer"
+	"AirBnb/internal/models"
+	"AirBnb/internal/payments"
+	"AirBnb/internal/utils"
+	"errors"
+	"html"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type AdminController struct {
+	db       database.Service    // The database service to interact with the database.
+	provider payments.Provider   // The payment provider that holds and moves the guests' money.
+	mailer   *mailer.Mailer      // The mailer that tells guests and hosts about cancellations.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewAdminController(db database.Service, provider payments.Provider, mailer *mailer.Mailer) *AdminController {
+	return &AdminController{
+		db:       db,
+		provider: provider,
+		mailer:   mailer,
+		validate: validator.New(),
+	}
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the user moderation logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type ListUsersRequest struct {
+	Query  string `query:"q" validate:"max=255"`
+	Status string `query:"status" validate:"omitempty,oneof=active suspended"`
+	Page   int    `query:"page" validate:"min=0"`
+	Limit  int    `query:"limit" validate:"min=0,max=100"`
+}
+
+func (ac *AdminController) GetUsers(c *fiber.Ctx) error {
+	var req ListUsersRequest
+	if err := c.QueryParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameters", err.Error())
+	}
+
+	if err := ac.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	if req.Page == 0 {
+		req.Page = 1
+	}
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	users, total, err := ac.db.FindUsers(database.UserFilter{Query: req.Query, Status: req.Status}, req.Page, req.Limit)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch users", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   users,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  req.Page,
+			"limit": req.Limit,
+		},
+	})
+}
+
+func (ac *AdminController) SuspendUser(c *fiber.Ctx) error {
+	return ac.setUserActive(c, false, "User successfully suspended")
+}
+
+func (ac *AdminController) ReactivateUser(c *fiber.Ctx) error {
+	return ac.setUserActive(c, true, "User successfully reactivated")
+}
+
+func (ac *AdminController) setUserActive(c *fiber.Ctx, active bool, message string) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	userID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID", nil)
+	}
+
+	// Staff cannot lock themselves out
+	if userID == claims.UserID {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "You cannot change the status of your own account", nil)
+	}
+
+	user, err := ac.db.SetUserActive(uint(userID), active)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update user", err.Error())
+	}
+
+	if user == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "User not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    user,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the property moderation logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (ac *AdminController) UnpublishProperty(c *fiber.Ctx) error {
+	return ac.setPropertyPublished(c, false, "Property successfully unpublished")
+}
+
+func (ac *AdminController) PublishProperty(c *fiber.Ctx) error {
+	return ac.setPropertyPublished(c, true, "Property successfully published")
+}
+
+func (ac *AdminController) setPropertyPublished(c *fiber.Ctx, published bool, message string) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	property, err := ac.db.SetPropertyPublished(uint(propertyID), published)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update property", err.Error())
+	}
+
+	if property == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    property,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the reservation moderation logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+// CancelReservation cancels any reservation that has not started yet, without the guest's
+// 24 hour cancellation window, and refunds the guest in full.
+func (ac *AdminController) CancelReservation(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	reservationID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation ID", nil)
+	}
+
+	reservation, err := ac.db.UpdateReservationStatus(uint(reservationID), models.ReservationCancelled, uint(claims.UserID))
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidReservationTransition) {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, "Reservation can no longer be cancelled", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to cancel reservation", err.Error())
+	}
+
+	if reservation == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Reservation not found", nil)
+	}
+
+	if err := releasePayment(c.UserContext(), ac.db, ac.provider, reservation, false); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Reservation cancelled but the refund failed", err.Error())
+	}
+
+	ac.mailer.NotifyBookingCancelled(reservation.CreatedBy, *reservation)
+	ac.mailer.NotifyBookingCancelled(reservationHost(ac.db, reservation), *reservation)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Reservation successfully cancelled",
+		"data":    reservation,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the moderation queue logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (ac *AdminController) GetReports(c *fiber.Ctx) error {
+	status := c.Query("status", models.ReportOpen)
+	switch status {
+	case models.ReportOpen, models.ReportResolved, models.ReportDismissed:
+	default:
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid report status", nil)
+	}
+
+	// Get pagination parameters from query
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 || limit < 1 || limit > 100 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid pagination parameters", nil)
+	}
+
+	reports, total, err := ac.db.FindPropertyReports(status, page, limit)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reports", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   reports,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
+
+type CloseReportRequest struct {
+	Note string `json:"note" validate:"max=2000"`
+	// Unpublish also takes the reported property offline when resolving the report
+	Unpublish bool `json:"unpublish"`
+}
+
+func (ac *AdminController) ResolveReport(c *fiber.Ctx) error {
+	return ac.closeReport(c, models.ReportResolved, "Report successfully resolved")
+}
+
+func (ac *AdminController) DismissReport(c *fiber.Ctx) error {
+	return ac.closeReport(c, models.ReportDismissed, "Report successfully dismissed")
+}
+
+func (ac *AdminController) closeReport(c *fiber.Ctx, status string, message string) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	reportID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid report ID", nil)
+	}
+
+	var req CloseReportRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+		}
+	}
+
+	if err := ac.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	existingReport, err := ac.db.FindPropertyReportById(uint(reportID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch report", err.Error())
+	}
+
+	if existingReport == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Report not found", nil)
+	}
+
+	report, err := ac.db.ClosePropertyReport(existingReport.ID, status, uint(claims.UserID), html.EscapeString(req.Note))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update report", err.Error())
+	}
+
+	if report == nil {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "This report has already been closed", nil)
+	}
+
+	if status == models.ReportResolved && req.Unpublish {
+		if _, err := ac.db.SetPropertyPublished(report.PropertyID, false); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to unpublish property", err.Error())
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    report,
+	})
+}
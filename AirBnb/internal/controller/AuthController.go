@@ -2,6 +2,7 @@ package controllers
 
 import (
 	"AirBnb/internal/database"
+	"AirBnb/internal/mailer"
 	"AirBnb/internal/utils"
 	"fmt"
 	"html"
@@ -17,13 +18,15 @@ import (
 
 type AuthController struct {
 	db       database.Service    // The database service to interact with the database.
+	mailer   *mailer.Mailer      // The mailer that queues verification and password reset emails.
 	validate *validator.Validate // Validator instance for validating user inputs.
 }
 
-// NewAuthController creates a new instance of AuthController with a database service.
-func NewAuthController(db database.Service) *AuthController {
+// NewAuthController creates a new instance of AuthController with a database service and a mailer.
+func NewAuthController(db database.Service, mailer *mailer.Mailer) *AuthController {
 	return &AuthController{
 		db:       db,              // Setting the provided database service.
+		mailer:   mailer,          // Setting the provided mailer.
 		validate: validator.New(), // Initializing a new validator instance.
 	}
 }
@@ -177,20 +180,9 @@ func (ac *AuthController) Register(c *fiber.Ctx) error {
 	}
 
 	// Step 10: Send verification email
-	// After user creation, send a verification email with the token.
-	// Send the verification email asynchronously using a goroutine
-	go func() {
-		// Assume you have a function that sends the email
-		err := utils.SendVerificationEmail(newUser.Email, newUser.Token)
-		if err != nil {
-			// Return an error response if sending the verification email fails.
-			c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":  "Failed to send verification email", // Error message.
-				"status": fiber.StatusInternalServerError,     // Internal server error status code.
-				"TO":     newUser.Email,
-			})
-		}
-	}()
+	// After user creation, queue a verification email with the token.
+	// The mailer delivers it in the background and retries failed sends.
+	ac.mailer.SendVerificationEmail(*newUser)
 
 	// Step 11: Respond with success
 	// Return a success response, asking the user to verify their email.
@@ -294,6 +286,13 @@ func (ac *AuthController) Login(c *fiber.Ctx) error {
 		})
 	}
 
+	if !user.IsActive {
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error":  "This account has been deactivated",
+			"status": fiber.StatusForbidden,
+		})
+	}
+
 	JWT, err := utils.GenerateToken(int(user.ID), user.Avatar, user.Email, user.Name, user.Token, user.IsActive, user.IsStaff, user.IsVerified)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
@@ -362,18 +361,10 @@ func (ac *AuthController) ForgotPassword(c *fiber.Ctx) error {
 		})
 	}
 
-	go func() {
-		// Assume you have a function that sends the email
-		err := utils.SendVerificationPassword(findUser.Email, findUser.Token)
-		if err != nil {
-			// Return an error response if sending the verification email fails.
-			c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-				"error":  "Failed to send verification email", // Error message.
-				"status": fiber.StatusInternalServerError,     // Internal server error status code.
-				"TO":     findUser.Email,
-			})
-		}
-	}()
+	// Queue the reset email only for known users, the response is the same either way
+	if findUser != nil {
+		ac.mailer.SendPasswordReset(*findUser)
+	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Reset Password Email have been send please check you email", // Success message.
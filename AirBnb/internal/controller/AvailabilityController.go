@@ -0,0 +1,303 @@
+package controllers
+
+import (
+	"AirBnb/internal/booking"
+	"AirBnb/internal/database"
+	"AirBnb/internal/utils"
+	"errors"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+// maxAvailabilityNights caps how many nights a single availability request can return.
+const maxAvailabilityNights = 366
+
+type AvailabilityController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewAvailabilityController(db database.Service) *AvailabilityController {
+	return &AvailabilityController{
+		db:       db,
+		validate: validator.New(),
+	}
+}
+
+type NightAvailability struct {
+	Date      string `json:"date"`
+	Available bool   `json:"available"`
+	Blocked   bool   `json:"blocked,omitempty"`
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the availability logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (ac *AvailabilityController) GetPropertyAvailability(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	// Default to the next 30 nights starting today
+	from := utils.TruncateToDay(time.Now())
+	if value := c.Query("from"); value != "" {
+		if from, err = utils.ParseDate(value); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid from date", err.Error())
+		}
+	}
+
+	to := from.AddDate(0, 0, 30)
+	if value := c.Query("to"); value != "" {
+		if to, err = utils.ParseDate(value); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid to date", err.Error())
+		}
+	}
+
+	nights := utils.NightsBetween(from, to)
+	if nights < 1 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "The to date must be after the from date", nil)
+	}
+	if nights > maxAvailabilityNights {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Availability can be requested for at most 366 nights", nil)
+	}
+
+	property, err := ac.db.FindPropertyById(propertyID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
+	}
+
+	if property == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	reservations, err := ac.db.FindPropertyReservations(property.ID, from, to)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservations", err.Error())
+	}
+
+	blockedDates, err := ac.db.FindPropertyBlockedDates(property.ID, from, to)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch blocked dates", err.Error())
+	}
+
+	// Mark every night covered by a reservation as booked
+	booked := make(map[string]bool)
+	for _, reservation := range reservations {
+		for night := utils.TruncateToDay(reservation.StartDate); night.Before(utils.TruncateToDay(reservation.EndDate)); night = night.AddDate(0, 0, 1) {
+			booked[night.Format(utils.DateLayout)] = true
+		}
+	}
+
+	// Blocked nights are unavailable too but are counted apart from the bookings
+	blocked := make(map[string]bool)
+	for _, blockedDate := range blockedDates {
+		for night := utils.TruncateToDay(blockedDate.StartDate); night.Before(utils.TruncateToDay(blockedDate.EndDate)); night = night.AddDate(0, 0, 1) {
+			blocked[night.Format(utils.DateLayout)] = true
+		}
+	}
+
+	calendar := make([]NightAvailability, 0, nights)
+	bookedNights, blockedNights := 0, 0
+	for night := from; night.Before(to); night = night.AddDate(0, 0, 1) {
+		date := night.Format(utils.DateLayout)
+		switch {
+		case booked[date]:
+			bookedNights++
+		case blocked[date]:
+			blockedNights++
+		}
+		calendar = append(calendar, NightAvailability{
+			Date:      date,
+			Available: !booked[date] && !blocked[date],
+			Blocked:   blocked[date] && !booked[date],
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   calendar,
+		"meta": fiber.Map{
+			"property_id":    property.ID,
+			"from":           from.Format(utils.DateLayout),
+			"to":             to.Format(utils.DateLayout),
+			"booked_nights":  bookedNights,
+			"blocked_nights": blockedNights,
+			"free_nights":    nights - bookedNights - blockedNights,
+			"min_nights":     property.MinNights,
+			"max_nights":     property.MaxNights,
+			"check_in_days":  booking.SplitWeekdays(property.CheckInDays),
+		},
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the blocked dates logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type CreateBlockedDateRequest struct {
+	StartDate string `json:"start_date" validate:"required"`
+	EndDate   string `json:"end_date" validate:"required"`
+	Note      string `json:"note" validate:"max=200"`
+}
+
+func (ac *AvailabilityController) GetBlockedDates(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	// Default to every block from today on
+	from := utils.TruncateToDay(time.Now())
+	if value := c.Query("from"); value != "" {
+		if from, err = utils.ParseDate(value); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid from date", err.Error())
+		}
+	}
+
+	to := from.AddDate(10, 0, 0)
+	if value := c.Query("to"); value != "" {
+		if to, err = utils.ParseDate(value); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid to date", err.Error())
+		}
+	}
+
+	blockedDates, err := ac.db.FindPropertyBlockedDates(uint(propertyID), from, to)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch blocked dates", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   blockedDates,
+	})
+}
+
+func (ac *AvailabilityController) CreateBlockedDate(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	var req CreateBlockedDateRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := ac.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	startDate, err := utils.ParseDate(req.StartDate)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid start date", err.Error())
+	}
+
+	endDate, err := utils.ParseDate(req.EndDate)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid end date", err.Error())
+	}
+
+	if !endDate.After(startDate) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "The end date must be after the start date", nil)
+	}
+
+	blockedDate, err := ac.db.CreateBlockedDate(uint(propertyID), database.BlockedDate{
+		StartDate: startDate,
+		EndDate:   endDate,
+		Summary:   req.Note,
+	})
+	if err != nil {
+		if errors.Is(err, database.ErrReservationOverlap) {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, "Some of these nights are already booked", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to block dates", err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Dates successfully blocked",
+		"data":    blockedDate,
+	})
+}
+
+func (ac *AvailabilityController) DeleteBlockedDate(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	blockID, err := c.ParamsInt("blockId")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid blocked date ID", nil)
+	}
+
+	blockedDate, err := ac.db.DeleteBlockedDate(uint(propertyID), uint(blockID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to unblock dates", err.Error())
+	}
+
+	if blockedDate == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Blocked date not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Dates successfully unblocked",
+		"data":    blockedDate,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the stay rules logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type UpdateStayRulesRequest struct {
+	MinNights   int      `json:"min_nights" validate:"min=1,max=365"`
+	MaxNights   int      `json:"max_nights" validate:"min=0,max=365"`
+	CheckInDays []string `json:"check_in_days" validate:"max=7"`
+}
+
+func (ac *AvailabilityController) UpdateStayRules(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	req := UpdateStayRulesRequest{MinNights: 1}
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := ac.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	if req.MaxNights > 0 && req.MaxNights < req.MinNights {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "The maximum stay cannot be shorter than the minimum stay", nil)
+	}
+
+	checkInDays, err := booking.ParseWeekdays(req.CheckInDays)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid check-in days", err.Error())
+	}
+
+	property, err := ac.db.UpdatePropertyStayRules(uint(propertyID), database.StayRules{
+		MinNights:   req.MinNights,
+		MaxNights:   req.MaxNights,
+		CheckInDays: booking.FormatWeekdays(checkInDays),
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update stay rules", err.Error())
+	}
+
+	if property == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stay rules successfully updated",
+		"data":    property,
+	})
+}
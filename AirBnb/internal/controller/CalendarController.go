@@ -0,0 +1,224 @@
+package controllers
+
+import (
+	"AirBnb/internal/calendarsync"
+	"AirBnb/internal/database"
+	"AirBnb/internal/ical"
+	"AirBnb/internal/models"
+	"AirBnb/internal/utils"
+	"bytes"
+	"fmt"
+	"log"
+	"net/url"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+// calendarFeedMonths is how far ahead the exported feed lists reservations.
+const calendarFeedMonths = 24
+
+type CalendarController struct {
+	db       database.Service     // The database service to interact with the database.
+	validate *validator.Validate  // Validator instance for validating user inputs.
+	syncer   *calendarsync.Syncer // Imports external calendars on demand.
+}
+
+func NewCalendarController(db database.Service) *CalendarController {
+	return &CalendarController{
+		db:       db,
+		validate: validator.New(),
+		syncer:   calendarsync.New(db),
+	}
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the calendar feed logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (cc *CalendarController) ExportCalendar(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	property, err := cc.db.FindPropertyById(propertyID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
+	}
+
+	if property == nil || !property.IsPublished {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	// Recent stays are kept so other platforms do not see a checked-in guest disappear
+	from := utils.TruncateToDay(time.Now()).AddDate(0, -1, 0)
+	to := from.AddDate(0, calendarFeedMonths+1, 0)
+	reservations, err := cc.db.FindPropertyReservations(property.ID, from, to)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservations", err.Error())
+	}
+
+	// The feed is public, so events only say the nights are taken and never who took them
+	events := make([]ical.Event, 0, len(reservations))
+	for _, reservation := range reservations {
+		switch reservation.Status {
+		case models.ReservationConfirmed, models.ReservationCheckedIn, models.ReservationCompleted:
+			events = append(events, ical.Event{
+				UID:     fmt.Sprintf("reservation-%d@airbnb", reservation.ID),
+				Summary: "Reserved",
+				Start:   utils.TruncateToDay(reservation.StartDate),
+				End:     utils.TruncateToDay(reservation.EndDate),
+			})
+		}
+	}
+
+	var buffer bytes.Buffer
+	if err := ical.Write(&buffer, property.Title, events, time.Now()); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to export calendar", err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("inline; filename=\"property_%d.ics\"", property.ID))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the external calendars logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type CreateExternalCalendarRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	URL  string `json:"url" validate:"required,url,max=2000"`
+}
+
+func (cc *CalendarController) GetExternalCalendars(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	calendars, err := cc.db.FindPropertyExternalCalendars(uint(propertyID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch calendars", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   calendars,
+	})
+}
+
+func (cc *CalendarController) CreateExternalCalendar(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	var req CreateExternalCalendarRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := cc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	// Platforms often share feeds as webcal:// links, which are plain HTTPS
+	feedURL, err := url.Parse(req.URL)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid calendar URL", err.Error())
+	}
+	switch feedURL.Scheme {
+	case "http", "https":
+	case "webcal":
+		feedURL.Scheme = "https"
+	default:
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "The calendar URL must use http, https or webcal", nil)
+	}
+
+	calendar, err := cc.db.CreateExternalCalendar(database.ExternalCalendar{
+		PropertyID: uint(propertyID),
+		Name:       req.Name,
+		URL:        feedURL.String(),
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create calendar", err.Error())
+	}
+
+	// Import right away so the host sees the result, a failed import is retried by the sync job
+	if err := cc.syncer.Sync(c.UserContext(), *calendar); err != nil {
+		log.Printf("Failed to sync calendar %d: %v", calendar.ID, err)
+	}
+	if synced, err := cc.db.FindExternalCalendarById(calendar.PropertyID, calendar.ID); err == nil && synced != nil {
+		calendar = synced
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Calendar successfully added",
+		"data":    calendar,
+	})
+}
+
+func (cc *CalendarController) SyncExternalCalendar(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	calendarID, err := c.ParamsInt("calendarId")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid calendar ID", nil)
+	}
+
+	calendar, err := cc.db.FindExternalCalendarById(uint(propertyID), uint(calendarID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch calendar", err.Error())
+	}
+
+	if calendar == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Calendar not found", nil)
+	}
+
+	if err := cc.syncer.Sync(c.UserContext(), *calendar); err != nil {
+		// The fetch error is recorded on the calendar and not echoed back
+		return utils.SendErrorResponse(c, fiber.StatusBadGateway, "Failed to sync calendar", nil)
+	}
+
+	calendar, err = cc.db.FindExternalCalendarById(calendar.PropertyID, calendar.ID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch calendar", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Calendar successfully synced",
+		"data":    calendar,
+	})
+}
+
+func (cc *CalendarController) DeleteExternalCalendar(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	calendarID, err := c.ParamsInt("calendarId")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid calendar ID", nil)
+	}
+
+	calendar, err := cc.db.DeleteExternalCalendar(uint(propertyID), uint(calendarID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete calendar", err.Error())
+	}
+
+	if calendar == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Calendar not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Calendar successfully deleted",
+		"data":    calendar,
+	})
+}
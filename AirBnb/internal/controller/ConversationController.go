@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"AirBnb/internal/database"
+	"AirBnb/internal/mailer"
 	"AirBnb/internal/utils"
+	"html"
 	"strconv"
 
 	"github.com/go-playground/validator"
@@ -11,22 +13,24 @@ import (
 
 type ConversationController struct {
 	db       database.Service    // The database service to interact with the database.
+	mailer   *mailer.Mailer      // The mailer that tells receivers about new messages.
 	validate *validator.Validate // Validator instance for validating user inputs.
 }
 
-func NewConversationController(db database.Service) *ConversationController {
+func NewConversationController(db database.Service, mailer *mailer.Mailer) *ConversationController {
 	return &ConversationController{
 		db:       db,
+		mailer:   mailer,
 		validate: validator.New(),
 	}
 }
 
 // ----------------------------------------------------------------------------------------------------
-// ------------------------------ these is the start of the registration logic -------------------------
+// ------------------------------ these is the start of the send message logic -------------------------
 // ----------------------------------------------------------------------------------------------------
 
 type ConversationRequest struct {
-	Message string `json:"message" validate:"required"`
+	Message string `json:"message" validate:"required,max=5000"`
 }
 
 func (cc *ConversationController) CreateConversationMessage(c *fiber.Ctx) error {
@@ -39,6 +43,10 @@ func (cc *ConversationController) CreateConversationMessage(c *fiber.Ctx) error
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid receiver ID", err.Error())
 	}
 
+	if receiverID == claims.UserID {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "You cannot send a message to yourself", nil)
+	}
+
 	// Parse the request body
 	var req ConversationRequest
 	if err := c.BodyParser(&req); err != nil {
@@ -50,29 +58,133 @@ func (cc *ConversationController) CreateConversationMessage(c *fiber.Ctx) error
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
 	}
 
+	// Make sure the receiver exists
+	receiver, err := cc.db.FindUserById(uint(receiverID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch receiver", err.Error())
+	}
+
+	if receiver == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Receiver not found", nil)
+	}
+
 	// Find or create a conversation between the sender and receiver
-	conversation, err := cc.db.FindOrCreateConversation(uint(claims.UserID), uint(receiverID))
+	conversation, err := cc.db.FindOrCreateConversation(uint(claims.UserID), receiver.ID)
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to find or create conversation", err.Error())
 	}
 
 	// Create the message
 	message := database.ConversationMessage{
-		Body:           req.Message,
+		Body:           html.EscapeString(req.Message),
 		ConversationID: conversation.ID,
 		CreatedByID:    uint(claims.UserID),
-		// SentToID:       uint(receiverID),
+		SentToID:       receiver.ID,
 	}
 
 	// Save the message to the database
-	if err := cc.db.CreateConversationMessage(message); err != nil {
+	newMessage, err := cc.db.CreateConversationMessage(message)
+	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create message", err.Error())
 	}
 
+	// Let the receiver know, the email template escapes the raw message itself
+	sender, err := cc.db.FindUserById(uint(claims.UserID))
+	if err == nil && sender != nil {
+		cc.mailer.NotifyNewMessage(*receiver, *sender, conversation.ID, req.Message)
+	}
+
 	// Return a success response
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"status":  "success",
 		"message": "Message created successfully",
-		"data":    message,
+		"data":    newMessage,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the inbox logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (cc *ConversationController) GetConversations(c *fiber.Ctx) error {
+	// Extract user claims from the context
+	claims := c.Locals("user").(*utils.Claims)
+
+	conversations, err := cc.db.FindUserConversations(uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch conversations", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   conversations,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the thread logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (cc *ConversationController) GetConversationMessages(c *fiber.Ctx) error {
+	conversationID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid conversation ID", nil)
+	}
+
+	// Get pagination parameters from query
+	before := c.QueryInt("before", 0)
+	limit := c.QueryInt("limit", 50)
+	if before < 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid before parameter", nil)
+	}
+	if limit < 1 || limit > 100 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Limit must be between 1 and 100", nil)
+	}
+
+	messages, err := cc.db.FindConversationMessages(uint(conversationID), uint(before), limit)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch messages", err.Error())
+	}
+
+	// The ID of the oldest message on the page is the cursor of the next page
+	var nextBefore uint
+	if len(messages) == limit {
+		nextBefore = messages[len(messages)-1].ID
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   messages,
+		"meta": fiber.Map{
+			"limit":       limit,
+			"next_before": nextBefore,
+		},
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the mark as read logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (cc *ConversationController) MarkConversationRead(c *fiber.Ctx) error {
+	// Extract user claims from the context
+	claims := c.Locals("user").(*utils.Claims)
+
+	conversationID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid conversation ID", nil)
+	}
+
+	updated, err := cc.db.MarkConversationRead(uint(conversationID), uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to mark messages as read", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  fiber.StatusOK,
+		"message": "Messages marked as read",
+		"data": fiber.Map{
+			"updated": updated,
+		},
 	})
 }
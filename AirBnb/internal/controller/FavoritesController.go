@@ -73,3 +73,34 @@ func (fc *FavoritesController) DeleteFromFavorites(c *fiber.Ctx) error {
 		"data":   property,
 	})
 }
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the list favorites logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (fc *FavoritesController) GetFavorites(c *fiber.Ctx) error {
+	// Extract user claims from the context
+	claims := c.Locals("user").(*utils.Claims)
+
+	// Get pagination parameters from query
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 || limit < 1 || limit > 100 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid pagination parameters", nil)
+	}
+
+	properties, total, err := fc.db.FindUserFavorites(uint(claims.UserID), page, limit)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch favorites", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   properties,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  page,
+			"limit": limit,
+		},
+	})
+}
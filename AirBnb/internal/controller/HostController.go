@@ -0,0 +1,274 @@
+package controllers
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/models"
+	"AirBnb/internal/utils"
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type HostController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewHostController(db database.Service) *HostController {
+	return &HostController{
+		db:       db,
+		validate: validator.New(),
+	}
+}
+
+type PropertyOccupancy struct {
+	PropertyID    uint    `json:"property_id"`
+	Title         string  `json:"title"`
+	BookedNights  int     `json:"booked_nights"`
+	OccupancyRate float64 `json:"occupancy_rate"`
+}
+
+type MonthOccupancy struct {
+	Month           string              `json:"month"`
+	BookedNights    int                 `json:"booked_nights"`
+	AvailableNights int                 `json:"available_nights"`
+	OccupancyRate   float64             `json:"occupancy_rate"`
+	Properties      []PropertyOccupancy `json:"properties"`
+}
+
+// parseDateRange reads the from and to query parameters, defaulting to the current year.
+func parseDateRange(c *fiber.Ctx) (time.Time, time.Time, error) {
+	now := time.Now().UTC()
+	from := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0)
+
+	var err error
+	if value := c.Query("from"); value != "" {
+		if from, err = utils.ParseDate(value); err != nil {
+			return from, to, err
+		}
+	}
+	if value := c.Query("to"); value != "" {
+		if to, err = utils.ParseDate(value); err != nil {
+			return from, to, err
+		}
+	}
+	if !to.After(from) {
+		return from, to, fmt.Errorf("the to date must be after the from date")
+	}
+
+	return from, to, nil
+}
+
+// overlappingNights counts the nights of a reservation that fall in the [from, to) range.
+func overlappingNights(reservation models.Reservation, from, to time.Time) int {
+	start := utils.TruncateToDay(reservation.StartDate)
+	if start.Before(from) {
+		start = from
+	}
+	end := utils.TruncateToDay(reservation.EndDate)
+	if end.After(to) {
+		end = to
+	}
+	if !end.After(start) {
+		return 0
+	}
+	return utils.NightsBetween(start, end)
+}
+
+func occupancyRate(booked, available int) float64 {
+	if available == 0 {
+		return 0
+	}
+	return math.Round(float64(booked)/float64(available)*10000) / 10000
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the occupancy logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (hc *HostController) GetOccupancy(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	year := c.QueryInt("year", time.Now().Year())
+	if year < 2000 || year > 2100 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid year", nil)
+	}
+
+	yearStart := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	yearEnd := yearStart.AddDate(1, 0, 0)
+
+	properties, err := hc.db.FindLandlordProperties(uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch properties", err.Error())
+	}
+
+	reservations, err := hc.db.FindLandlordReservations(uint(claims.UserID), database.ReservationFilter{
+		From: yearStart,
+		To:   yearEnd,
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservations", err.Error())
+	}
+
+	months := make([]MonthOccupancy, 0, 12)
+	for monthStart := yearStart; monthStart.Before(yearEnd); monthStart = monthStart.AddDate(0, 1, 0) {
+		monthEnd := monthStart.AddDate(0, 1, 0)
+		daysInMonth := utils.NightsBetween(monthStart, monthEnd)
+
+		// Only accepted reservations occupy the property
+		bookedByProperty := make(map[uint]int)
+		for _, reservation := range reservations {
+			switch reservation.Status {
+			case models.ReservationConfirmed, models.ReservationCheckedIn, models.ReservationCompleted:
+				bookedByProperty[reservation.PropertyID] += overlappingNights(reservation, monthStart, monthEnd)
+			}
+		}
+
+		month := MonthOccupancy{
+			Month:           monthStart.Format("2006-01"),
+			AvailableNights: daysInMonth * len(properties),
+			Properties:      make([]PropertyOccupancy, 0, len(properties)),
+		}
+		for _, property := range properties {
+			booked := bookedByProperty[property.ID]
+			month.BookedNights += booked
+			month.Properties = append(month.Properties, PropertyOccupancy{
+				PropertyID:    property.ID,
+				Title:         property.Title,
+				BookedNights:  booked,
+				OccupancyRate: occupancyRate(booked, daysInMonth),
+			})
+		}
+		month.OccupancyRate = occupancyRate(month.BookedNights, month.AvailableNights)
+
+		months = append(months, month)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   months,
+		"meta": fiber.Map{
+			"year": year,
+		},
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the earnings logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (hc *HostController) GetEarnings(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid date range", err.Error())
+	}
+
+	earnings, err := hc.db.FindLandlordEarnings(uint(claims.UserID), from, to)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch earnings", err.Error())
+	}
+
+	var gross, net float64
+	var reservations int64
+	for _, property := range earnings {
+		gross += property.GrossEarnings
+		net += property.NetEarnings
+		reservations += property.Reservations
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data": fiber.Map{
+			"total_gross_earnings": math.Round(gross*100) / 100,
+			"total_net_earnings":   math.Round(net*100) / 100,
+			"reservations":         reservations,
+			"properties":           earnings,
+		},
+		"meta": fiber.Map{
+			"from": from.Format(utils.DateLayout),
+			"to":   to.Format(utils.DateLayout),
+		},
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the CSV export logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (hc *HostController) ExportBookings(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	from, to, err := parseDateRange(c)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid date range", err.Error())
+	}
+
+	reservations, err := hc.db.FindLandlordReservations(uint(claims.UserID), database.ReservationFilter{
+		From: from,
+		To:   to,
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservations", err.Error())
+	}
+
+	reservationIDs := make([]uint, len(reservations))
+	for i, reservation := range reservations {
+		reservationIDs[i] = reservation.ID
+	}
+	payouts, err := hc.db.FindReservationPayouts(reservationIDs)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch payouts", err.Error())
+	}
+
+	var buffer bytes.Buffer
+	writer := csv.NewWriter(&buffer)
+	writer.Write([]string{
+		"reservation_id", "property_id", "property_title", "guest_name", "guest_email", "status",
+		"start_date", "end_date", "nights", "guests", "gross_price", "net_payout", "created_at",
+	})
+	for _, reservation := range reservations {
+		writer.Write([]string{
+			strconv.FormatUint(uint64(reservation.ID), 10),
+			strconv.FormatUint(uint64(reservation.PropertyID), 10),
+			csvSafe(reservation.Property.Title),
+			csvSafe(reservation.CreatedBy.Name),
+			csvSafe(reservation.CreatedBy.Email),
+			reservation.Status,
+			reservation.StartDate.Format(utils.DateLayout),
+			reservation.EndDate.Format(utils.DateLayout),
+			strconv.Itoa(reservation.NumberOfNights),
+			strconv.Itoa(reservation.Guests),
+			strconv.FormatFloat(reservation.TotalPrice, 'f', 2, 64),
+			strconv.FormatFloat(float64(payouts[reservation.ID])/100, 'f', 2, 64),
+			reservation.CreatedAt.Format(time.RFC3339),
+		})
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to export bookings", err.Error())
+	}
+
+	fileName := fmt.Sprintf("bookings_%s_%s.csv", from.Format(utils.DateLayout), to.Format(utils.DateLayout))
+	c.Set(fiber.HeaderContentType, "text/csv")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", fileName))
+	return c.Status(fiber.StatusOK).Send(buffer.Bytes())
+}
+
+// csvSafe stops spreadsheet applications from running user supplied text as a formula by
+// prefixing cells that start with a formula character with a quote.
+func csvSafe(value string) string {
+	if value != "" && strings.ContainsRune("=+-@\t\r", rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/models"
+	"AirBnb/internal/payments"
+	"AirBnb/internal/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PaymentController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewPaymentController(db database.Service) *PaymentController {
+	return &PaymentController{
+		db:       db,
+		validate: validator.New(),
+	}
+}
+
+// errCaptureFailed wraps the provider's error when the guest's payment could not be captured.
+var errCaptureFailed = errors.New("payment could not be captured")
+
+// capturePayment runs confirm, which is ConfirmReservation or CaptureReservationPayment, with a
+// callback that captures the reservation's payment at the provider. When the provider took the
+// money but confirm still fails, for example because the transaction could not be committed, the
+// charge is refunded so that the guest never pays for a stay that was not confirmed.
+func capturePayment(ctx context.Context, provider payments.Provider, confirm func(capture func(payment *models.Payment) error) error) error {
+	var captured *models.Payment
+
+	err := confirm(func(payment *models.Payment) error {
+		if err := provider.Capture(ctx, payment.ProviderPaymentID, payment.Amount); err != nil {
+			return fmt.Errorf("%w: %v", errCaptureFailed, err)
+		}
+		captured = payment
+		return nil
+	})
+	if err != nil && captured != nil {
+		if refundErr := provider.Refund(ctx, captured.ProviderPaymentID, captured.Amount); refundErr != nil {
+			log.Printf("Failed to refund payment %d after its reservation could not be confirmed: %v", captured.ID, refundErr)
+		}
+	}
+
+	return err
+}
+
+// releasePayment gives the guest's money back after a reservation was cancelled or declined.
+// An authorization is voided. A captured payment is refunded in full, or according to the
+// property's cancellation policy when the guest cancelled.
+func releasePayment(ctx context.Context, db database.Service, provider payments.Provider, reservation *models.Reservation, applyPolicy bool) error {
+	payment, err := db.FindPaymentByReservationId(reservation.ID)
+	if err != nil || payment == nil {
+		return err
+	}
+
+	switch payment.Status {
+	case models.PaymentAuthorized:
+		if err := provider.Void(ctx, payment.ProviderPaymentID); err != nil {
+			return err
+		}
+		_, err = db.RecordPaymentVoid(payment.ID)
+		return err
+
+	case models.PaymentCaptured, models.PaymentPartiallyRefunded:
+		refund := payment.CapturedAmount - payment.RefundedAmount
+		if applyPolicy {
+			refund = min(refund, payments.RefundAmount(reservation.Property.CancellationPolicy, payment.CapturedAmount, reservation.StartDate, time.Now()))
+		}
+		if refund <= 0 {
+			return nil
+		}
+
+		if err := provider.Refund(ctx, payment.ProviderPaymentID, refund); err != nil {
+			return err
+		}
+		_, err = db.RecordPaymentRefund(payment.ID, refund)
+		return err
+	}
+
+	return nil
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the payment logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+// GetReservationPayment shows the guest how much was charged and refunded for their reservation.
+func (pc *PaymentController) GetReservationPayment(c *fiber.Ctx) error {
+	reservationID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation ID", nil)
+	}
+
+	payment, err := pc.db.FindPaymentByReservationId(uint(reservationID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch payment", err.Error())
+	}
+
+	if payment == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Payment not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   payment,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the ledger logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+// GetReservationLedger lists the payment and the ledger transactions of a reservation together
+// with the balance of every account, for landlords and staff.
+func (pc *PaymentController) GetReservationLedger(c *fiber.Ctx) error {
+	reservationID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation ID", nil)
+	}
+
+	payment, err := pc.db.FindPaymentByReservationId(uint(reservationID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch payment", err.Error())
+	}
+
+	transactions, err := pc.db.FindReservationLedger(uint(reservationID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch ledger", err.Error())
+	}
+
+	// Balances are credits minus debits, so amounts owed to the landlord and fees earned are positive
+	balances := make(map[string]int64)
+	for _, transaction := range transactions {
+		for _, entry := range transaction.Entries {
+			balances[entry.Account] += entry.Credit - entry.Debit
+		}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data": fiber.Map{
+			"payment":      payment,
+			"transactions": transactions,
+			"balances":     balances,
+		},
+	})
+}
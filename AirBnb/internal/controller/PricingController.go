@@ -0,0 +1,212 @@
+package controllers
+
+import (
+	"AirBnb/internal/booking"
+	"AirBnb/internal/database"
+	"AirBnb/internal/pricing"
+	"AirBnb/internal/utils"
+	"errors"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type PricingController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewPricingController(db database.Service) *PricingController {
+	return &PricingController{
+		db:       db,
+		validate: validator.New(),
+	}
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the quote logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (pc *PricingController) GetQuote(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	start, err := utils.ParseDate(c.Query("start"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid start date", err.Error())
+	}
+
+	end, err := utils.ParseDate(c.Query("end"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid end date", err.Error())
+	}
+
+	if utils.NightsBetween(start, end) < 1 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "The end date must be after the start date", nil)
+	}
+
+	guests := c.QueryInt("guests", 1)
+	if guests < 1 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Guests must be at least 1", nil)
+	}
+
+	property, err := pc.db.FindPropertyById(propertyID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
+	}
+
+	if property == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	if guests > property.Guests {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Too many guests for this property", nil)
+	}
+
+	var ruleErr *booking.RuleError
+	if err := booking.RulesFromProperty(*property).Check(start, end); errors.As(err, &ruleErr) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, ruleErr.Message, ruleErr)
+	}
+
+	quote := pricing.Quote(pricing.RulesFromProperty(*property), start, end, guests)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   quote,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the pricing rules logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type UpdatePricingRequest struct {
+	WeekendPrice       int    `json:"weekend_price" validate:"min=0"`
+	CleaningFee        int    `json:"cleaning_fee" validate:"min=0"`
+	BaseGuests         int    `json:"base_guests" validate:"min=0"`
+	ExtraGuestFee      int    `json:"extra_guest_fee" validate:"min=0"`
+	WeeklyDiscount     int    `json:"weekly_discount" validate:"min=0,max=100"`
+	MonthlyDiscount    int    `json:"monthly_discount" validate:"min=0,max=100"`
+	CancellationPolicy string `json:"cancellation_policy" validate:"omitempty,oneof=flexible moderate strict"`
+}
+
+func (pc *PricingController) UpdatePricing(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	var req UpdatePricingRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := pc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	property, err := pc.db.UpdatePropertyPricing(uint(propertyID), database.PropertyPricing{
+		WeekendPrice:       req.WeekendPrice,
+		CleaningFee:        req.CleaningFee,
+		BaseGuests:         req.BaseGuests,
+		ExtraGuestFee:      req.ExtraGuestFee,
+		WeeklyDiscount:     req.WeeklyDiscount,
+		MonthlyDiscount:    req.MonthlyDiscount,
+		CancellationPolicy: req.CancellationPolicy,
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update pricing", err.Error())
+	}
+
+	if property == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Pricing successfully updated",
+		"data":    property,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the seasonal price logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type CreateSeasonalPriceRequest struct {
+	StartDate     string `json:"start_date" validate:"required"`
+	EndDate       string `json:"end_date" validate:"required"`
+	PricePerNight int    `json:"price_per_night" validate:"required,min=1"`
+}
+
+func (pc *PricingController) CreateSeasonalPrice(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	var req CreateSeasonalPriceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := pc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	startDate, err := utils.ParseDate(req.StartDate)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid start date", err.Error())
+	}
+
+	endDate, err := utils.ParseDate(req.EndDate)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid end date", err.Error())
+	}
+
+	if !endDate.After(startDate) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "The end date must be after the start date", nil)
+	}
+
+	seasonalPrice, err := pc.db.CreateSeasonalPrice(database.SeasonalPrice{
+		PropertyID:    uint(propertyID),
+		StartDate:     startDate,
+		EndDate:       endDate,
+		PricePerNight: req.PricePerNight,
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Seasonal price successfully created",
+		"data":    seasonalPrice,
+	})
+}
+
+func (pc *PricingController) DeleteSeasonalPrice(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	seasonID, err := c.ParamsInt("seasonId")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid seasonal price ID", nil)
+	}
+
+	seasonalPrice, err := pc.db.DeleteSeasonalPrice(uint(propertyID), uint(seasonID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete seasonal price", err.Error())
+	}
+
+	if seasonalPrice == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Seasonal price not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Seasonal price successfully deleted",
+		"data":    seasonalPrice,
+	})
+}
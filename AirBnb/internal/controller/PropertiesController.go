@@ -3,16 +3,13 @@ package controllers
 import (
 	"AirBnb/internal/database"
 	"AirBnb/internal/utils"
+	"errors"
 	"fmt"
 	"html"
-	"mime/multipart"
-	"os"
-	"path/filepath"
 	"strconv"
 
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
-	"gorm.io/gorm"
 )
 
 type PropertiesController struct {
@@ -31,14 +28,19 @@ func NewPropertiesController(db database.Service) *PropertiesController {
 // ------------------------------ these is the start of the registration logic -------------------------
 // ----------------------------------------------------------------------------------------------------
 type RegisterPropertyRequest struct {
-	Title         string `form:"title" validate:"required,max=255"`
-	Description   string `form:"description" validate:"required,max=255"`
-	PricePerNight int    `form:"price_per_night" validate:"required,min=1"`
-	Bedrooms      int    `form:"bed_room" validate:"required,min=1"`
-	Guests        int    `form:"guests" validate:"required,min=1"`
-	Country       string `form:"country" validate:"required,max=255"`
-	CountryCode   string `form:"country_code" validate:"required,max=255"`
-	Category      string `form:"category" validate:"required,max=255"`
+	Title         string   `form:"title" validate:"required,max=255"`
+	Description   string   `form:"description" validate:"required,max=255"`
+	PricePerNight int      `form:"price_per_night" validate:"required,min=1"`
+	Bedrooms      int      `form:"bed_room" validate:"required,min=1"`
+	Guests        int      `form:"guests" validate:"required,min=1"`
+	Country       string   `form:"country" validate:"required,max=255"`
+	CountryCode   string   `form:"country_code" validate:"required,max=255"`
+	Address       string   `form:"address" validate:"required,max=255"`
+	City          string   `form:"city" validate:"required,max=255"`
+	Latitude      *float64 `form:"latitude" validate:"required,min=-90,max=90"`
+	Longitude     *float64 `form:"longitude" validate:"required,min=-180,max=180"`
+	Category      string   `form:"category" validate:"required,max=255"`
+	InstantBook   bool     `form:"instant_book"`
 }
 
 func (pc *PropertiesController) RegisterProperty(c *fiber.Ctx) error {
@@ -60,58 +62,13 @@ func (pc *PropertiesController) RegisterProperty(c *fiber.Ctx) error {
 	// Handle file upload
 	file, err := c.FormFile("Image")
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Profile picture is required", err.Error())
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Property image is required", err.Error())
 	}
 
-	// Create a channel for the upload result
-	uploadChan := make(chan struct {
-		filePath string
-		err      error
-	})
-
-	// Upload the file in a goroutine
-	go func(file *multipart.FileHeader) {
-		const maxFileSize = 10 * 1024 * 1024 // 10 MB
-
-		var result struct {
-			filePath string
-			err      error
-		}
-
-		// Check file size
-		if file.Size > maxFileSize {
-			result.err = fmt.Errorf("file size exceeds the limit of 10 MB")
-			uploadChan <- result
-			return
-		}
-
-		// Create uploads directory if it doesn't exist
-		uploadDir := "./image"
-		if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-			if err := os.Mkdir(uploadDir, os.ModePerm); err != nil {
-				result.err = err
-				uploadChan <- result
-				return
-			}
-		}
-
-		// Save the file
-		fileName := utils.GenerateUniqueFilename(file.Filename)
-		filePath := fmt.Sprintf("%s/%s", uploadDir, fileName)
-		if err := c.SaveFile(file, filePath); err != nil {
-			result.err = err
-			uploadChan <- result
-			return
-		}
-
-		result.filePath = filePath
-		uploadChan <- result
-	}(file)
-
-	// Wait for the upload result
-	uploadResult := <-uploadChan
-	if uploadResult.err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to upload profile picture", uploadResult.err.Error())
+	// Save the image and its thumbnail
+	image, err := savePropertyImage(c, file)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Failed to upload property image", err.Error())
 	}
 
 	// Prepare the property data for creation
@@ -123,17 +80,30 @@ func (pc *PropertiesController) RegisterProperty(c *fiber.Ctx) error {
 		Guests:        req.Guests,        // No need to escape integers
 		Country:       html.EscapeString(req.Country),
 		CountryCode:   html.EscapeString(req.CountryCode),
+		Address:       html.EscapeString(req.Address),
+		City:          html.EscapeString(req.City),
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
 		Category:      html.EscapeString(req.Category),
-		Image:         uploadResult.filePath, // Use the uploaded file path
+		Image:         image.Path, // Use the uploaded file path
+		InstantBook:   req.InstantBook,
 		LandlordID:    uint(claims.UserID),
 	}
 
 	// Create the property in the database
 	newProperty, err := pc.db.CreateProperty(CreatePropertyData)
 	if err != nil {
+		removeImageFiles(image.Path, image.ThumbnailPath)
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create property", err.Error())
 	}
 
+	// The registration image becomes the first image and cover of the gallery
+	gallery, err := pc.db.CreatePropertyImages(newProperty.ID, []database.PropertyImage{image})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save property image", err.Error())
+	}
+	newProperty.Images = gallery
+
 	// Return the created property
 	// return c.Status(fiber.StatusCreated).JSON(newProperty)
 
@@ -173,23 +143,26 @@ func (pc *PropertiesController) DeleteProperty(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
 	}
 
-	// Store the image path before deletion
-	imagePath := property.Image
+	// Store the image paths before deletion
+	imagePaths := []string{property.Image}
+	for _, image := range property.Images {
+		if image.Path != property.Image {
+			imagePaths = append(imagePaths, image.Path)
+		}
+		imagePaths = append(imagePaths, image.ThumbnailPath)
+	}
 
 	// Delete the property from the database
 	_, err = pc.db.DeleteProperty(uint(id))
 	if err != nil {
+		if errors.Is(err, database.ErrPropertyHasPayments) {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, "Properties with paid reservations cannot be deleted", nil)
+		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete property", err.Error())
 	}
 
-	go func() {
-		// Delete the associated image file
-		if imagePath != "" {
-			if err := os.Remove(imagePath); err != nil {
-				fmt.Printf("Failed to delete image: %v\n", err)
-			}
-		}
-	}()
+	// Delete the associated image files
+	go removeImageFiles(imagePaths...)
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Property successfully deleted",
@@ -204,14 +177,19 @@ func (pc *PropertiesController) DeleteProperty(c *fiber.Ctx) error {
 // ----------------------------------------------------------------------------------------------------
 
 type UpdatePropertyRequest struct {
-	Title         string `form:"title" validate:"required,max=255"`
-	Description   string `form:"description" validate:"required,max=255"`
-	PricePerNight int    `form:"price_per_night" validate:"required,min=1"`
-	Bedrooms      int    `form:"bed_room" validate:"required,min=1"`
-	Guests        int    `form:"guests" validate:"required,min=1"`
-	Country       string `form:"country" validate:"required,max=255"`
-	CountryCode   string `form:"country_code" validate:"required,max=255"`
-	Category      string `form:"category" validate:"required,max=255"`
+	Title         string   `form:"title" validate:"required,max=255"`
+	Description   string   `form:"description" validate:"required,max=255"`
+	PricePerNight int      `form:"price_per_night" validate:"required,min=1"`
+	Bedrooms      int      `form:"bed_room" validate:"required,min=1"`
+	Guests        int      `form:"guests" validate:"required,min=1"`
+	Country       string   `form:"country" validate:"required,max=255"`
+	CountryCode   string   `form:"country_code" validate:"required,max=255"`
+	Address       string   `form:"address" validate:"required,max=255"`
+	City          string   `form:"city" validate:"required,max=255"`
+	Latitude      *float64 `form:"latitude" validate:"required,min=-90,max=90"`
+	Longitude     *float64 `form:"longitude" validate:"required,min=-180,max=180"`
+	Category      string   `form:"category" validate:"required,max=255"`
+	InstantBook   bool     `form:"instant_book"`
 }
 
 func (pc *PropertiesController) UpdateProperty(c *fiber.Ctx) error {
@@ -240,49 +218,21 @@ func (pc *PropertiesController) UpdateProperty(c *fiber.Ctx) error {
 	// Find the property by ID
 	FindProperty, err := pc.db.FindPropertyById(id)
 	if err != nil {
-		if err == gorm.ErrRecordNotFound {
-			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
-		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update property", err.Error())
 	}
 
-	// Delete the old image in a goroutine
-	go func() {
-		if FindProperty.Image != "" {
-			if err := os.Remove(FindProperty.Image); err != nil {
-				fmt.Printf("Failed to delete image: %v\n", err)
-			}
-		}
-	}()
-
-	// Handle file upload
-	file, err := c.FormFile("Image")
-	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Profile picture is required", err.Error())
-	}
-
-	// Validate file type
-	allowedTypes := map[string]bool{
-		"image/jpeg": true,
-		"image/png":  true,
-		"image/gif":  true,
-	}
-	if !allowedTypes[file.Header.Get("Content-Type")] {
-		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid file type. Only JPEG, PNG, and GIF are allowed", nil)
+	if FindProperty == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
 	}
 
-	// Upload the file
-	uploadDir := "./image"
-	if _, err := os.Stat(uploadDir); os.IsNotExist(err) {
-		if err := os.Mkdir(uploadDir, os.ModePerm); err != nil {
-			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create upload directory", err.Error())
+	// A new image is optional, when given it is added to the gallery and becomes the cover
+	var newImage *database.PropertyImage
+	if file, err := c.FormFile("Image"); err == nil {
+		image, err := savePropertyImage(c, file)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Failed to upload property image", err.Error())
 		}
-	}
-
-	fileName := utils.GenerateUniqueFilename(file.Filename)
-	filePath := filepath.Join(uploadDir, fileName)
-	if err := c.SaveFile(file, filePath); err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save uploaded file", err.Error())
+		newImage = &image
 	}
 
 	// Prepare the update data
@@ -294,18 +244,46 @@ func (pc *PropertiesController) UpdateProperty(c *fiber.Ctx) error {
 		Guests:        req.Guests,
 		Country:       html.EscapeString(req.Country),
 		CountryCode:   html.EscapeString(req.CountryCode),
+		Address:       html.EscapeString(req.Address),
+		City:          html.EscapeString(req.City),
+		Latitude:      req.Latitude,
+		Longitude:     req.Longitude,
 		Category:      html.EscapeString(req.Category),
-		Image:         filePath,
+		InstantBook:   req.InstantBook,
 		LandlordID:    uint(claims.UserID),
 	}
 
-	UpdateProperty, err := pc.db.UpdateProperty(uint(id), UpdatePropertyData)
-
 	// Update the property in the database
-	if err != nil {
+	if _, err := pc.db.UpdateProperty(uint(id), UpdatePropertyData); err != nil {
+		if newImage != nil {
+			removeImageFiles(newImage.Path, newImage.ThumbnailPath)
+		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update property", err.Error())
 	}
 
+	if newImage != nil {
+		gallery, err := pc.db.CreatePropertyImages(uint(id), []database.PropertyImage{*newImage})
+		if err != nil {
+			removeImageFiles(newImage.Path, newImage.ThumbnailPath)
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save property image", err.Error())
+		}
+
+		// New images are appended to the end of the gallery
+		if _, err := pc.db.SetPropertyCoverImage(uint(id), gallery[len(gallery)-1].ID); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to set cover image", err.Error())
+		}
+
+		// Properties registered before galleries existed only have the legacy image file
+		if len(FindProperty.Images) == 0 {
+			go removeImageFiles(FindProperty.Image)
+		}
+	}
+
+	UpdateProperty, err := pc.db.FindPropertyById(id)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
+	}
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Property registered successfully",
 		"data": fiber.Map{
@@ -322,13 +300,149 @@ func (pc *PropertiesController) UpdateProperty(c *fiber.Ctx) error {
 // ------------------------------ these is the start of the Find ALL logic -------------------------
 // ----------------------------------------------------------------------------------------------------
 
+type SearchPropertiesRequest struct {
+	Country      string `query:"country" validate:"max=255"`
+	CountryCode  string `query:"country_code" validate:"max=255"`
+	Category     string `query:"category" validate:"max=255"`
+	MinPrice     int    `query:"min_price" validate:"min=0"`
+	MaxPrice     int    `query:"max_price" validate:"min=0"`
+	MinBedrooms  int    `query:"bedrooms" validate:"min=0"`
+	MinBathrooms int    `query:"bathrooms" validate:"min=0"`
+	MinGuests    int    `query:"guests" validate:"min=0"`
+	CheckIn      string `query:"check_in"`
+	CheckOut     string `query:"check_out"`
+	// A location search either measures from lat/lng, optionally within radius_km,
+	// or covers the map view between min_lat/min_lng and max_lat/max_lng.
+	Latitude     *float64 `query:"lat" validate:"omitempty,min=-90,max=90"`
+	Longitude    *float64 `query:"lng" validate:"omitempty,min=-180,max=180"`
+	RadiusKm     float64  `query:"radius_km" validate:"min=0,max=500"`
+	MinLatitude  *float64 `query:"min_lat" validate:"omitempty,min=-90,max=90"`
+	MinLongitude *float64 `query:"min_lng" validate:"omitempty,min=-180,max=180"`
+	MaxLatitude  *float64 `query:"max_lat" validate:"omitempty,min=-90,max=90"`
+	MaxLongitude *float64 `query:"max_lng" validate:"omitempty,min=-180,max=180"`
+	Sort         string   `query:"sort" validate:"omitempty,oneof=newest price_asc price_desc most_favorited distance"`
+	Cursor       string   `query:"cursor"`
+	Limit        int      `query:"limit" validate:"min=0,max=100"`
+}
+
+// parseLocationSearch checks that the location parameters come in complete sets. A bounding box
+// without lat/lng is measured from its center.
+func parseLocationSearch(req SearchPropertiesRequest) (*database.GeoPoint, *database.BoundingBox, error) {
+	var center *database.GeoPoint
+	var bounds *database.BoundingBox
+
+	if (req.Latitude == nil) != (req.Longitude == nil) {
+		return nil, nil, fmt.Errorf("lat and lng must be given together")
+	}
+	if req.Latitude != nil {
+		center = &database.GeoPoint{Latitude: *req.Latitude, Longitude: *req.Longitude}
+	}
+
+	if req.RadiusKm > 0 && center == nil {
+		return nil, nil, fmt.Errorf("radius_km requires lat and lng")
+	}
+
+	boxValues := 0
+	for _, value := range []*float64{req.MinLatitude, req.MinLongitude, req.MaxLatitude, req.MaxLongitude} {
+		if value != nil {
+			boxValues++
+		}
+	}
+	switch boxValues {
+	case 0:
+	case 4:
+		if *req.MinLatitude > *req.MaxLatitude {
+			return nil, nil, fmt.Errorf("min_lat must not be greater than max_lat")
+		}
+		bounds = &database.BoundingBox{
+			MinLatitude:  *req.MinLatitude,
+			MinLongitude: *req.MinLongitude,
+			MaxLatitude:  *req.MaxLatitude,
+			MaxLongitude: *req.MaxLongitude,
+		}
+		if center == nil {
+			lat, lng := utils.BoundsCenter(bounds.MinLatitude, bounds.MinLongitude, bounds.MaxLatitude, bounds.MaxLongitude)
+			center = &database.GeoPoint{Latitude: lat, Longitude: lng}
+		}
+	default:
+		return nil, nil, fmt.Errorf("a bounding box needs min_lat, min_lng, max_lat and max_lng")
+	}
+
+	return center, bounds, nil
+}
+
 func (pc *PropertiesController) GetAllProperties(c *fiber.Ctx) error {
-	// Get pagination parameters from query
-	page, _ := strconv.Atoi(c.Query("page", "1"))
-	limit, _ := strconv.Atoi(c.Query("limit", "0")) // 0 means no limit
+	var req SearchPropertiesRequest
 
-	properties, total, err := pc.db.FindAllProperties(page, limit)
+	// Parse the query string
+	if err := c.QueryParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid query parameters", err.Error())
+	}
+
+	// Validate the request struct
+	if err := pc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	if req.Limit == 0 {
+		req.Limit = 20
+	}
+
+	center, bounds, err := parseLocationSearch(req)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid location", err.Error())
+	}
+
+	// Location searches are ordered by distance unless another order is asked for
+	if req.Sort == "" && center != nil {
+		req.Sort = database.SortDistance
+	}
+	if req.Sort == "" {
+		req.Sort = database.SortNewest
+	}
+	if req.Sort == database.SortDistance && center == nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Sorting by distance requires lat and lng or a bounding box", nil)
+	}
+
+	filter := database.PropertyFilter{
+		Country:      req.Country,
+		CountryCode:  req.CountryCode,
+		Category:     req.Category,
+		MinPrice:     req.MinPrice,
+		MaxPrice:     req.MaxPrice,
+		MinBedrooms:  req.MinBedrooms,
+		MinBathrooms: req.MinBathrooms,
+		MinGuests:    req.MinGuests,
+		Center:       center,
+		RadiusKm:     req.RadiusKm,
+		Bounds:       bounds,
+		Sort:         req.Sort,
+		Cursor:       req.Cursor,
+		Limit:        req.Limit,
+	}
+
+	// The availability window is only applied when both dates are given
+	if req.CheckIn != "" || req.CheckOut != "" {
+		checkIn, err := utils.ParseDate(req.CheckIn)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid check_in date", err.Error())
+		}
+		checkOut, err := utils.ParseDate(req.CheckOut)
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid check_out date", err.Error())
+		}
+		if !checkOut.After(checkIn) {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "check_out must be after check_in", nil)
+		}
+		filter.AvailableFrom = checkIn
+		filter.AvailableTo = checkOut
+	}
+
+	properties, nextCursor, err := pc.db.SearchProperties(filter)
 	if err != nil {
+		if errors.Is(err, database.ErrInvalidCursor) {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", nil)
+		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch properties", err.Error())
 	}
 
@@ -336,9 +450,10 @@ func (pc *PropertiesController) GetAllProperties(c *fiber.Ctx) error {
 		"status": fiber.StatusOK,
 		"data":   properties,
 		"meta": fiber.Map{
-			"total": total,
-			"page":  page,
-			"limit": limit,
+			"limit":       req.Limit,
+			"sort":        req.Sort,
+			"next_cursor": nextCursor,
+			"has_more":    nextCursor != "",
 		},
 	})
 }
@@ -348,6 +463,8 @@ func (pc *PropertiesController) GetAllProperties(c *fiber.Ctx) error {
 // ----------------------------------------------------------------------------------------------------
 
 func (pc *PropertiesController) GetPropertyById(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
 	propertyID, err := c.ParamsInt("id")
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
@@ -358,7 +475,8 @@ func (pc *PropertiesController) GetPropertyById(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
 	}
 
-	if property == nil {
+	// Unpublished properties stay visible to their landlord and to staff only
+	if property == nil || (!property.IsPublished && property.LandlordID != uint(claims.UserID) && !claims.IsStaff) {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
 	}
 
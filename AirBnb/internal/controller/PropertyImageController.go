@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/utils"
+	"fmt"
+	"log"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	propertyImageDir     = "./image"
+	propertyThumbnailDir = "./image/thumbnails"
+	maxPropertyImageSize = 10 * 1024 * 1024 // 10 MB
+	maxImagesPerUpload   = 10
+)
+
+var allowedImageTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+type PropertyImageController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewPropertyImageController(db database.Service) *PropertyImageController {
+	return &PropertyImageController{
+		db:       db,
+		validate: validator.New(),
+	}
+}
+
+// savePropertyImage stores an uploaded image in ./image and writes its thumbnail to ./image/thumbnails.
+func savePropertyImage(c *fiber.Ctx, file *multipart.FileHeader) (database.PropertyImage, error) {
+	var image database.PropertyImage
+
+	if file.Size > maxPropertyImageSize {
+		return image, fmt.Errorf("file %s exceeds the limit of 10 MB", file.Filename)
+	}
+
+	if !allowedImageTypes[file.Header.Get("Content-Type")] {
+		return image, fmt.Errorf("file %s is not a JPEG, PNG or GIF image", file.Filename)
+	}
+
+	if err := os.MkdirAll(propertyThumbnailDir, os.ModePerm); err != nil {
+		return image, err
+	}
+
+	fileName := utils.GenerateUniqueFilename(filepath.Base(file.Filename))
+	image.Path = fmt.Sprintf("%s/%s", propertyImageDir, fileName)
+	if err := c.SaveFile(file, image.Path); err != nil {
+		return image, err
+	}
+
+	image.ThumbnailPath = fmt.Sprintf("%s/%s.jpg", propertyThumbnailDir, strings.TrimSuffix(fileName, filepath.Ext(fileName)))
+	if err := utils.CreateThumbnail(image.Path, image.ThumbnailPath, utils.ThumbnailWidth); err != nil {
+		removeImageFiles(image.Path)
+		return image, err
+	}
+
+	return image, nil
+}
+
+// removeImageFiles deletes image files from disk, logging failures without blocking the request.
+func removeImageFiles(paths ...string) {
+	for _, path := range paths {
+		if path == "" {
+			continue
+		}
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			log.Printf("Failed to delete image: %s, error: %v", path, err)
+		}
+	}
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the upload logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (ic *PropertyImageController) UploadImages(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	files := form.File["images"]
+	if len(files) == 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "At least one image is required", nil)
+	}
+	if len(files) > maxImagesPerUpload {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "You can upload at most 10 images at once", nil)
+	}
+
+	// Save every file first so a single bad file does not leave a half-uploaded gallery
+	images := make([]database.PropertyImage, 0, len(files))
+	for _, file := range files {
+		image, err := savePropertyImage(c, file)
+		if err != nil {
+			for _, saved := range images {
+				removeImageFiles(saved.Path, saved.ThumbnailPath)
+			}
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Failed to upload image", err.Error())
+		}
+		images = append(images, image)
+	}
+
+	gallery, err := ic.db.CreatePropertyImages(uint(propertyID), images)
+	if err != nil {
+		for _, saved := range images {
+			removeImageFiles(saved.Path, saved.ThumbnailPath)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save images", err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Images successfully uploaded",
+		"data":    gallery,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the reorder logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type ReorderImagesRequest struct {
+	ImageIDs []uint `json:"image_ids" validate:"required,min=1"`
+}
+
+func (ic *PropertyImageController) ReorderImages(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	var req ReorderImagesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := ic.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	gallery, err := ic.db.ReorderPropertyImages(uint(propertyID), req.ImageIDs)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, err.Error(), nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Images successfully reordered",
+		"data":    gallery,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the cover logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (ic *PropertyImageController) SetCoverImage(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	imageID, err := c.ParamsInt("imageId")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid image ID", nil)
+	}
+
+	image, err := ic.db.SetPropertyCoverImage(uint(propertyID), uint(imageID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to set cover image", err.Error())
+	}
+
+	if image == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Image not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Cover image successfully updated",
+		"data":    image,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the delete logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (ic *PropertyImageController) DeleteImage(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	imageID, err := c.ParamsInt("imageId")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid image ID", nil)
+	}
+
+	image, err := ic.db.DeletePropertyImage(uint(propertyID), uint(imageID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete image", err.Error())
+	}
+
+	if image == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Image not found", nil)
+	}
+
+	removeImageFiles(image.Path, image.ThumbnailPath)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Image successfully deleted",
+		"data":    image,
+	})
+}
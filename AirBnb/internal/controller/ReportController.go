@@ -0,0 +1,86 @@
+package controllers
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/utils"
+	"html"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReportController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewReportController(db database.Service) *ReportController {
+	return &ReportController{
+		db:       db,
+		validate: validator.New(),
+	}
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the report listing logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type CreateReportRequest struct {
+	Reason  string `json:"reason" validate:"required,oneof=inaccurate scam offensive unsafe other"`
+	Details string `json:"details" validate:"max=2000"`
+}
+
+func (rc *ReportController) CreateReport(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	var req CreateReportRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := rc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	property, err := rc.db.FindPropertyById(propertyID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
+	}
+
+	if property == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	if property.LandlordID == uint(claims.UserID) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "You cannot report your own property", nil)
+	}
+
+	existingReport, err := rc.db.FindOpenPropertyReport(property.ID, uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to check existing report", err.Error())
+	}
+
+	if existingReport != nil {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "You already reported this property", nil)
+	}
+
+	report, err := rc.db.CreatePropertyReport(database.PropertyReport{
+		PropertyID: property.ID,
+		ReporterID: uint(claims.UserID),
+		Reason:     req.Reason,
+		Details:    html.EscapeString(req.Details),
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to report property", err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Property successfully reported",
+		"data":    report,
+	})
+}
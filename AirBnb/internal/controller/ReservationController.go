@@ -1,32 +1,54 @@
 package controllers
 
 import (
+	"AirBnb/internal/booking"
 	"AirBnb/internal/database"
+	"AirBnb/internal/mailer"
+	"AirBnb/internal/models"
+	"AirBnb/internal/payments"
+	"AirBnb/internal/pricing"
 	"AirBnb/internal/utils"
+	"errors"
+	"fmt"
+	"log"
 	"strconv"
 	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
 )
 
 type ReservationController struct {
 	db       database.Service    // The database service to interact with the database.
+	provider payments.Provider   // The payment provider that holds and moves the guests' money.
+	mailer   *mailer.Mailer      // The mailer that notifies guests and hosts of booking changes.
 	validate *validator.Validate // Validator instance for validating user inputs.
 }
 
-func NewReservationController(db database.Service) *ReservationController {
+func NewReservationController(db database.Service, provider payments.Provider, mailer *mailer.Mailer) *ReservationController {
 	return &ReservationController{
 		db:       db,
+		provider: provider,
+		mailer:   mailer,
 		validate: validator.New(),
 	}
 }
 
+// reservationHost returns the landlord of the reserved property. Reservations are loaded
+// without the landlord, who is only needed to send them emails.
+func reservationHost(db database.Service, reservation *models.Reservation) models.User {
+	host, err := db.FindUserById(reservation.Property.LandlordID)
+	if err != nil || host == nil {
+		return models.User{}
+	}
+	return *host
+}
+
 type CreateReservationRequest struct {
-	StartDate      time.Time `json:"start_date" validate:"required,future"`
-	EndDate        time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
-	NumberOfNights int       `json:"number_of_nights" validate:"required,min=1"`
-	Guests         int       `json:"guests" validate:"required,min=1"`
+	StartDate time.Time `json:"start_date" validate:"required"`
+	EndDate   time.Time `json:"end_date" validate:"required,gtfield=StartDate"`
+	Guests    int       `json:"guests" validate:"required,min=1"`
 }
 
 func (rc *ReservationController) CreateReservation(c *fiber.Ctx) error {
@@ -49,37 +71,131 @@ func (rc *ReservationController) CreateReservation(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", utils.FormatValidationErrors(err))
 	}
 
+	// Reservations are booked by night, so only the calendar date matters
+	startDate := utils.TruncateToDay(req.StartDate)
+	endDate := utils.TruncateToDay(req.EndDate)
+	if startDate.Before(utils.TruncateToDay(time.Now())) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Start date cannot be in the past", nil)
+	}
+
+	numberOfNights := utils.NightsBetween(startDate, endDate)
+	if numberOfNights < 1 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "A reservation must be at least one night long", nil)
+	}
+
 	property, err := rc.db.FindPropertyById(int(id))
 	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
+	}
+
+	if property == nil || !property.IsPublished {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
 	}
 
-	totalPrice := float64(property.PricePerNight * req.NumberOfNights)
+	if req.Guests > property.Guests {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Too many guests for this property", nil)
+	}
+
+	// Refuse stays the host does not accept before any money is held, the booking re-checks them
+	var ruleErr *booking.RuleError
+	if err := booking.RulesFromProperty(*property).Check(startDate, endDate); errors.As(err, &ruleErr) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, ruleErr.Message, ruleErr)
+	}
+
+	// Price the stay night by night using the property's pricing rules
+	quote := pricing.Quote(pricing.RulesFromProperty(*property), startDate, endDate, req.Guests)
 
 	createReservationData := database.Reservation{
-		StartDate:      req.StartDate,
-		EndDate:        req.EndDate,
-		NumberOfNights: req.NumberOfNights,
+		StartDate:      startDate,
+		EndDate:        endDate,
+		NumberOfNights: numberOfNights,
 		Guests:         req.Guests,
-		TotalPrice:     totalPrice,
+		NightsSubtotal: quote.NightsSubtotal,
+		ExtraGuestFee:  quote.ExtraGuestFee,
+		CleaningFee:    quote.CleaningFee,
+		Discount:       quote.Discount,
+		TotalPrice:     quote.Total,
 		CreatedByID:    uint(claims.UserID),
 		PropertyID:     uint(id),
 	}
 
+	// Hold the guest's money before the nights are booked
+	amount := payments.ToCents(quote.Total)
+	providerPaymentID, err := rc.provider.Authorize(c.UserContext(), amount, fmt.Sprintf("property-%d-guest-%d", id, claims.UserID))
+	if err != nil {
+		if errors.Is(err, payments.ErrPaymentDeclined) {
+			return utils.SendErrorResponse(c, fiber.StatusPaymentRequired, err.Error(), nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to authorize payment", err.Error())
+	}
+
 	createdReservation, err := rc.db.CreateReservation(createReservationData)
 	if err != nil {
+		rc.provider.Void(c.UserContext(), providerPaymentID)
+		if errors.Is(err, database.ErrReservationOverlap) {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, err.Error(), nil)
+		}
+		var ruleErr *booking.RuleError
+		if errors.As(err, &ruleErr) {
+			status := fiber.StatusBadRequest
+			if errors.Is(err, database.ErrDatesBlocked) {
+				status = fiber.StatusConflict
+			}
+			return utils.SendErrorResponse(c, status, ruleErr.Message, ruleErr)
+		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create reservation", err.Error())
 	}
 
+	_, err = rc.db.CreatePayment(database.Payment{
+		ReservationID:     createdReservation.ID,
+		Provider:          rc.provider.Name(),
+		ProviderPaymentID: providerPaymentID,
+		Amount:            amount,
+	})
+	if err != nil {
+		rc.provider.Void(c.UserContext(), providerPaymentID)
+		cancelUnpaidReservation(rc.db, createdReservation.ID, uint(claims.UserID))
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save payment", err.Error())
+	}
+
+	// Instant bookings are confirmed right away, so their payment is captured right away too
+	if createdReservation.Status == models.ReservationConfirmed {
+		err := capturePayment(c.UserContext(), rc.provider, func(capture func(payment *models.Payment) error) error {
+			return rc.db.CaptureReservationPayment(createdReservation.ID, capture)
+		})
+		if err != nil {
+			releasePayment(c.UserContext(), rc.db, rc.provider, createdReservation, false)
+			cancelUnpaidReservation(rc.db, createdReservation.ID, uint(claims.UserID))
+			return utils.SendErrorResponse(c, fiber.StatusPaymentRequired, "Payment could not be captured", err.Error())
+		}
+	}
+
+	rc.mailer.NotifyBookingRequested(reservationHost(rc.db, createdReservation), *createdReservation)
+
+	message := "Reservation request sent to the landlord"
+	if createdReservation.Status == models.ReservationConfirmed {
+		message = "Reservation successfully created"
+		rc.mailer.NotifyBookingConfirmed(*createdReservation)
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Reservation successfully created",
+		"message": message,
 		"property": fiber.Map{
 			"property": createdReservation,
 		},
+		"price_breakdown": quote,
 	})
 }
 
-func (rc *ReservationController) DeleteReservation(c *fiber.Ctx) error {
+// cancelUnpaidReservation cancels a reservation whose payment could not be completed. The request
+// has already failed at that point, so an error is only logged.
+func cancelUnpaidReservation(db database.Service, reservationID uint, userID uint) {
+	if _, err := db.UpdateReservationStatus(reservationID, models.ReservationCancelled, userID); err != nil {
+		log.Printf("Failed to cancel reservation %d after a payment failure: %v", reservationID, err)
+	}
+}
+
+func (rc *ReservationController) CancelReservation(c *fiber.Ctx) error {
 	claims := c.Locals("user").(*utils.Claims)
 
 	reservationID := c.Params("id")
@@ -91,29 +207,182 @@ func (rc *ReservationController) DeleteReservation(c *fiber.Ctx) error {
 	// Get the reservation first to check dates
 	reservation, err := rc.db.FindReservationById(uint(id))
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Reservation not found", nil)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Reservation not found", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to find reservation", err.Error())
 	}
 
 	// Check if user owns this reservation
 	if reservation.CreatedByID != uint(claims.UserID) {
-		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Not authorized to delete this reservation", nil)
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Not authorized to cancel this reservation", nil)
 	}
 
-	// Check if reservation starts within 24 hours
-	if time.Until(reservation.StartDate) < 24*time.Hour {
+	// Confirmed stays cannot be cancelled within 24 hours of the start date
+	if reservation.Status == models.ReservationConfirmed && time.Until(reservation.StartDate) < 24*time.Hour {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Cannot cancel reservations less than 24 hours before start date", nil)
 	}
 
-	// Proceed with deletion
-	deletedReservation, err := rc.db.DeleteReservation(uint(id))
+	// Record the cancellation instead of deleting the reservation
+	cancelledReservation, err := rc.db.UpdateReservationStatus(uint(id), models.ReservationCancelled, uint(claims.UserID))
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete reservation", err.Error())
+		if errors.Is(err, database.ErrInvalidReservationTransition) {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, "Reservation can no longer be cancelled", nil)
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to cancel reservation", err.Error())
 	}
 
+	// Refund the guest according to the property's cancellation policy
+	if err := releasePayment(c.UserContext(), rc.db, rc.provider, reservation, true); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Reservation cancelled but the refund failed", err.Error())
+	}
+
+	rc.mailer.NotifyBookingCancelled(reservationHost(rc.db, cancelledReservation), *cancelledReservation)
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Reservation successfully deleted",
-		"property": fiber.Map{
-			"property": deletedReservation,
+		"message": "Reservation successfully cancelled",
+		"data":    cancelledReservation,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the trips logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+// GetMyReservations lists the trips the current user booked as a guest.
+func (rc *ReservationController) GetMyReservations(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	period := c.Query("period")
+	if period != "" && period != database.PeriodUpcoming && period != database.PeriodPast {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Period must be upcoming or past", nil)
+	}
+
+	// Get pagination parameters from query
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 20)
+	if page < 1 || limit < 1 || limit > 100 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid pagination parameters", nil)
+	}
+
+	reservations, total, err := rc.db.FindUserReservations(uint(claims.UserID), database.ReservationFilter{
+		Period: period,
+	}, page, limit)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservations", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   reservations,
+		"meta": fiber.Map{
+			"total": total,
+			"page":  page,
+			"limit": limit,
 		},
 	})
 }
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the landlord logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (rc *ReservationController) GetHostReservations(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	status := c.Query("status")
+	switch status {
+	case "", models.ReservationRequested, models.ReservationConfirmed, models.ReservationDeclined,
+		models.ReservationCheckedIn, models.ReservationCompleted, models.ReservationCancelled:
+	default:
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation status", nil)
+	}
+
+	period := c.Query("period")
+	if period != "" && period != database.PeriodUpcoming && period != database.PeriodPast {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Period must be upcoming or past", nil)
+	}
+
+	reservations, err := rc.db.FindLandlordReservations(uint(claims.UserID), database.ReservationFilter{
+		Status: status,
+		Period: period,
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reservations", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   reservations,
+	})
+}
+
+func (rc *ReservationController) AcceptReservation(c *fiber.Ctx) error {
+	return rc.updateReservationStatus(c, models.ReservationConfirmed, "Reservation successfully confirmed")
+}
+
+func (rc *ReservationController) DeclineReservation(c *fiber.Ctx) error {
+	return rc.updateReservationStatus(c, models.ReservationDeclined, "Reservation successfully declined")
+}
+
+func (rc *ReservationController) CheckInReservation(c *fiber.Ctx) error {
+	return rc.updateReservationStatus(c, models.ReservationCheckedIn, "Guest successfully checked in")
+}
+
+func (rc *ReservationController) CompleteReservation(c *fiber.Ctx) error {
+	return rc.updateReservationStatus(c, models.ReservationCompleted, "Reservation successfully completed")
+}
+
+// updateReservationStatus applies a landlord transition to the reservation in the :id parameter.
+func (rc *ReservationController) updateReservationStatus(c *fiber.Ctx, status string, message string) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	id, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation ID", nil)
+	}
+
+	var reservation *models.Reservation
+	if status == models.ReservationConfirmed {
+		// Confirm and capture in one transaction so that a stay is never confirmed without being paid
+		err = capturePayment(c.UserContext(), rc.provider, func(capture func(payment *models.Payment) error) error {
+			reservation, err = rc.db.ConfirmReservation(uint(id), capture)
+			return err
+		})
+	} else {
+		reservation, err = rc.db.UpdateReservationStatus(uint(id), status, uint(claims.UserID))
+	}
+	if err != nil {
+		if errors.Is(err, database.ErrInvalidReservationTransition) {
+			return utils.SendErrorResponse(c, fiber.StatusConflict, err.Error(), nil)
+		}
+		if errors.Is(err, errCaptureFailed) || errors.Is(err, payments.ErrInvalidPaymentState) {
+			return utils.SendErrorResponse(c, fiber.StatusPaymentRequired, "Payment could not be captured", err.Error())
+		}
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update reservation", err.Error())
+	}
+
+	if reservation == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Reservation not found", nil)
+	}
+
+	switch status {
+	case models.ReservationConfirmed:
+		rc.mailer.NotifyBookingConfirmed(*reservation)
+	case models.ReservationDeclined:
+		if err := releasePayment(c.UserContext(), rc.db, rc.provider, reservation, false); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Reservation declined but the payment could not be released", err.Error())
+		}
+		rc.mailer.NotifyBookingCancelled(reservation.CreatedBy, *reservation)
+	case models.ReservationCompleted:
+		if _, err := rc.db.RecordLandlordPayout(reservation.ID); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Reservation completed but the payout failed", err.Error())
+		}
+		rc.mailer.NotifyReviewRequest(*reservation)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": message,
+		"data":    reservation,
+	})
+}
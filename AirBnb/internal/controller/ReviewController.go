@@ -0,0 +1,202 @@
+package controllers
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/models"
+	"AirBnb/internal/utils"
+	"html"
+	"time"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type ReviewController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewReviewController(db database.Service) *ReviewController {
+	return &ReviewController{
+		db:       db,
+		validate: validator.New(),
+	}
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the create review logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type CreateReviewRequest struct {
+	Cleanliness int    `json:"cleanliness" validate:"required,min=1,max=5"`
+	Accuracy    int    `json:"accuracy" validate:"required,min=1,max=5"`
+	Location    int    `json:"location" validate:"required,min=1,max=5"`
+	Value       int    `json:"value" validate:"required,min=1,max=5"`
+	Comment     string `json:"comment" validate:"required,max=2000"`
+}
+
+func (rc *ReviewController) CreateReview(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	reservationID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation ID", nil)
+	}
+
+	var req CreateReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := rc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", utils.FormatValidationErrors(err))
+	}
+
+	reservation, err := rc.db.FindReservationById(uint(reservationID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Reservation not found", nil)
+	}
+
+	// Only the guest who stayed can review, and only once the stay is over
+	if reservation.CreatedByID != uint(claims.UserID) {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Not authorized to review this reservation", nil)
+	}
+
+	switch reservation.Status {
+	case models.ReservationConfirmed, models.ReservationCheckedIn, models.ReservationCompleted:
+	default:
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Only completed stays can be reviewed", nil)
+	}
+
+	if time.Now().Before(reservation.EndDate) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "You can only review a stay after it has ended", nil)
+	}
+
+	existingReview, err := rc.db.FindReviewByReservationId(reservation.ID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to check existing review", err.Error())
+	}
+
+	if existingReview != nil {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "This reservation has already been reviewed", nil)
+	}
+
+	createReviewData := database.Review{
+		ReservationID: reservation.ID,
+		PropertyID:    reservation.PropertyID,
+		GuestID:       uint(claims.UserID),
+		Cleanliness:   req.Cleanliness,
+		Accuracy:      req.Accuracy,
+		Location:      req.Location,
+		Value:         req.Value,
+		Comment:       html.EscapeString(req.Comment),
+	}
+
+	review, err := rc.db.CreateReview(createReviewData)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create review", err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Review successfully created",
+		"data":    review,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the landlord reply logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+type ReplyReviewRequest struct {
+	Reply string `json:"reply" validate:"required,max=2000"`
+}
+
+func (rc *ReviewController) ReplyToReview(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	reviewID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid review ID", nil)
+	}
+
+	var req ReplyReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := rc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", utils.FormatValidationErrors(err))
+	}
+
+	review, err := rc.db.FindReviewById(uint(reviewID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch review", err.Error())
+	}
+
+	if review == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Review not found", nil)
+	}
+
+	// Only the landlord of the reviewed property can reply
+	if review.Property.LandlordID != uint(claims.UserID) {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "You are not the owner of this property", nil)
+	}
+
+	if review.RepliedAt != nil {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "This review already has a reply", nil)
+	}
+
+	updatedReview, err := rc.db.ReplyToReview(review.ID, html.EscapeString(req.Reply))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to reply to review", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Reply successfully added",
+		"data":    updatedReview,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the property reviews logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (rc *ReviewController) GetPropertyReviews(c *fiber.Ctx) error {
+	propertyID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	// Get pagination parameters from query
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 || limit < 1 || limit > 100 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid pagination parameters", nil)
+	}
+
+	property, err := rc.db.FindPropertyById(propertyID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
+	}
+
+	if property == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	reviews, total, err := rc.db.FindPropertyReviews(property.ID, page, limit)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch reviews", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   reviews,
+		"meta": fiber.Map{
+			"total":          total,
+			"page":           page,
+			"limit":          limit,
+			"average_rating": property.AverageRating,
+			"review_count":   property.ReviewCount,
+		},
+	})
+}
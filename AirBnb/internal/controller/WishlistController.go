@@ -0,0 +1,266 @@
+package controllers
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/utils"
+	"html"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+)
+
+type WishlistController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+}
+
+func NewWishlistController(db database.Service) *WishlistController {
+	return &WishlistController{
+		db:       db,
+		validate: validator.New(),
+	}
+}
+
+type WishlistRequest struct {
+	Name string `json:"name" validate:"required,max=255"`
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the list wishlists logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (wc *WishlistController) GetWishlists(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	wishlists, err := wc.db.FindUserWishlists(uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch wishlists", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   wishlists,
+	})
+}
+
+func (wc *WishlistController) GetWishlist(c *fiber.Ctx) error {
+	wishlistID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid wishlist ID", nil)
+	}
+
+	wishlist, err := wc.db.FindWishlistById(uint(wishlistID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch wishlist", err.Error())
+	}
+
+	if wishlist == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Wishlist not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data":   wishlist,
+	})
+}
+
+// GetSharedWishlist is public: anyone with the share link can view the wishlist but not change it.
+func (wc *WishlistController) GetSharedWishlist(c *fiber.Ctx) error {
+	wishlist, err := wc.db.FindWishlistByShareToken(c.Params("token"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch wishlist", err.Error())
+	}
+
+	if wishlist == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Wishlist not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status": fiber.StatusOK,
+		"data": fiber.Map{
+			"name":       wishlist.Name,
+			"properties": wishlist.Properties,
+		},
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the create and rename logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (wc *WishlistController) CreateWishlist(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*utils.Claims)
+
+	var req WishlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := wc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	wishlist, err := wc.db.CreateWishlist(database.Wishlist{
+		Name:    html.EscapeString(req.Name),
+		OwnerID: uint(claims.UserID),
+	})
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create wishlist", err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Wishlist successfully created",
+		"data":    wishlist,
+	})
+}
+
+func (wc *WishlistController) RenameWishlist(c *fiber.Ctx) error {
+	wishlistID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid wishlist ID", nil)
+	}
+
+	var req WishlistRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
+	}
+
+	if err := wc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed, please check input", utils.FormatValidationErrors(err))
+	}
+
+	wishlist, err := wc.db.RenameWishlist(uint(wishlistID), html.EscapeString(req.Name))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to rename wishlist", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Wishlist successfully renamed",
+		"data":    wishlist,
+	})
+}
+
+func (wc *WishlistController) DeleteWishlist(c *fiber.Ctx) error {
+	wishlistID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid wishlist ID", nil)
+	}
+
+	wishlist, err := wc.db.DeleteWishlist(uint(wishlistID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete wishlist", err.Error())
+	}
+
+	if wishlist == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Wishlist not found", nil)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Wishlist successfully deleted",
+		"data":    wishlist,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the wishlist properties logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+func (wc *WishlistController) AddProperty(c *fiber.Ctx) error {
+	wishlistID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid wishlist ID", nil)
+	}
+
+	propertyID, err := c.ParamsInt("propertyId")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	property, err := wc.db.FindPropertyById(propertyID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch property", err.Error())
+	}
+
+	if property == nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Property not found", nil)
+	}
+
+	wishlist, err := wc.db.AddWishlistProperty(uint(wishlistID), property.ID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to add property to wishlist", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Property successfully added to wishlist",
+		"data":    wishlist,
+	})
+}
+
+func (wc *WishlistController) RemoveProperty(c *fiber.Ctx) error {
+	wishlistID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid wishlist ID", nil)
+	}
+
+	propertyID, err := c.ParamsInt("propertyId")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid property ID", nil)
+	}
+
+	wishlist, err := wc.db.RemoveWishlistProperty(uint(wishlistID), uint(propertyID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to remove property from wishlist", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Property successfully removed from wishlist",
+		"data":    wishlist,
+	})
+}
+
+// ----------------------------------------------------------------------------------------------------
+// ------------------------------ these is the start of the share logic -------------------------
+// ----------------------------------------------------------------------------------------------------
+
+// ShareWishlist creates a new share token, which also invalidates any link shared before.
+func (wc *WishlistController) ShareWishlist(c *fiber.Ctx) error {
+	wishlistID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid wishlist ID", nil)
+	}
+
+	token, err := utils.GenerateVerificationToken()
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate share link", err.Error())
+	}
+
+	wishlist, err := wc.db.SetWishlistShareToken(uint(wishlistID), &token)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to share wishlist", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Wishlist successfully shared",
+		"data": fiber.Map{
+			"wishlist":   wishlist,
+			"share_path": "/shared/wishlists/" + token,
+		},
+	})
+}
+
+func (wc *WishlistController) UnshareWishlist(c *fiber.Ctx) error {
+	wishlistID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid wishlist ID", nil)
+	}
+
+	wishlist, err := wc.db.SetWishlistShareToken(uint(wishlistID), nil)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to stop sharing wishlist", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Wishlist is no longer shared",
+		"data":    wishlist,
+	})
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	UserStatusActive    = "active"
+	UserStatusSuspended = "suspended"
+)
+
+// UserFilter narrows down the staff user list. Query matches the name or the email.
+type UserFilter struct {
+	Query  string
+	Status string
+}
+
+type PropertyReport struct {
+	PropertyID uint
+	ReporterID uint
+	Reason     string
+	Details    string
+}
+
+func (s *service) FindUsers(filter UserFilter, page int, limit int) ([]models.User, int64, error) {
+	var users []models.User
+	var total int64
+
+	query := s.db.Model(&models.User{})
+	if filter.Query != "" {
+		like := "%" + filter.Query + "%"
+		query = query.Where("name ILIKE ? OR email ILIKE ?", like, like)
+	}
+	switch filter.Status {
+	case UserStatusActive:
+		query = query.Where("is_active = ?", true)
+	case UserStatusSuspended:
+		query = query.Where("is_active = ?", false)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	result := query.Order("id ASC").Offset(offset).Limit(limit).Find(&users)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return users, total, nil
+}
+
+// SetUserActive suspends or reactivates a user. Suspended users are rejected by AuthRequired.
+func (s *service) SetUserActive(id uint, active bool) (*models.User, error) {
+	result := s.db.Model(&models.User{}).Where("id = ?", id).Update("is_active", active)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return s.FindUserById(id)
+}
+
+func (s *service) SetPropertyPublished(id uint, published bool) (*models.Property, error) {
+	result := s.db.Model(&models.Property{}).Where("id = ?", id).Update("is_published", published)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return s.FindPropertyById(int(id))
+}
+
+func (s *service) FindPropertyReportById(id uint) (*models.PropertyReport, error) {
+	var report models.PropertyReport
+	result := s.db.Preload("Property").Preload("Reporter").Preload("ReviewedBy").Where("id = ?", id).First(&report)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &report, nil
+}
+
+// FindOpenPropertyReport returns the open report a user already made about a property, if any.
+func (s *service) FindOpenPropertyReport(propertyID uint, reporterID uint) (*models.PropertyReport, error) {
+	var report models.PropertyReport
+	result := s.db.Where("property_id = ? AND reporter_id = ? AND status = ?", propertyID, reporterID, models.ReportOpen).First(&report)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &report, nil
+}
+
+// FindPropertyReports returns a page of reports with the given status, oldest first so that the
+// moderation queue is worked through in order.
+func (s *service) FindPropertyReports(status string, page int, limit int) ([]models.PropertyReport, int64, error) {
+	var reports []models.PropertyReport
+	var total int64
+
+	query := s.db.Model(&models.PropertyReport{})
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	result := query.Preload("Property").
+		Preload("Reporter").
+		Preload("ReviewedBy").
+		Order("created_at ASC").
+		Offset(offset).Limit(limit).
+		Find(&reports)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return reports, total, nil
+}
+
+func (s *service) CreatePropertyReport(report PropertyReport) (*models.PropertyReport, error) {
+	newReport := &models.PropertyReport{
+		PropertyID: report.PropertyID,
+		ReporterID: report.ReporterID,
+		Reason:     report.Reason,
+		Details:    report.Details,
+		Status:     models.ReportOpen,
+	}
+
+	result := s.db.Create(newReport)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return s.FindPropertyReportById(newReport.ID)
+}
+
+// ClosePropertyReport resolves or dismisses an open report on behalf of a staff member.
+func (s *service) ClosePropertyReport(id uint, status string, staffID uint, note string) (*models.PropertyReport, error) {
+	result := s.db.Model(&models.PropertyReport{}).
+		Where("id = ? AND status = ?", id, models.ReportOpen).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"staff_note":     note,
+			"reviewed_by_id": staffID,
+			"reviewed_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return s.FindPropertyReportById(id)
+}
@@ -0,0 +1,87 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type StayRules struct {
+	MinNights   int
+	MaxNights   int
+	CheckInDays string
+}
+
+func (s *service) UpdatePropertyStayRules(id uint, rules StayRules) (*models.Property, error) {
+	result := s.db.Model(&models.Property{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"min_nights":    rules.MinNights,
+		"max_nights":    rules.MaxNights,
+		"check_in_days": rules.CheckInDays,
+	})
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return s.FindPropertyById(int(id))
+}
+
+// CreateBlockedDate blocks nights of a property by hand. Nights that are already booked cannot be
+// blocked, the reservation has to be cancelled first.
+func (s *service) CreateBlockedDate(propertyID uint, blockedDate BlockedDate) (*models.BlockedDate, error) {
+	newBlockedDate := &models.BlockedDate{
+		PropertyID: propertyID,
+		StartDate:  blockedDate.StartDate,
+		EndDate:    blockedDate.EndDate,
+		Summary:    blockedDate.Summary,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the property like CreateReservation does, so a booking cannot slip in meanwhile
+		var property models.Property
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&property, propertyID).Error; err != nil {
+			return err
+		}
+
+		var overlapping int64
+		if err := tx.Model(&models.Reservation{}).
+			Where("property_id = ? AND start_date < ? AND end_date > ?", propertyID, blockedDate.EndDate, blockedDate.StartDate).
+			Where(activeReservationCondition).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrReservationOverlap
+		}
+
+		return tx.Create(newBlockedDate).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newBlockedDate, nil
+}
+
+// DeleteBlockedDate removes a block the host created. Imported blocks are managed by their calendar
+// and are not found here.
+func (s *service) DeleteBlockedDate(propertyID uint, id uint) (*models.BlockedDate, error) {
+	var blockedDate models.BlockedDate
+	result := s.db.Where("id = ? AND property_id = ? AND external_calendar_id IS NULL", id, propertyID).First(&blockedDate)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	if err := s.db.Delete(&blockedDate).Error; err != nil {
+		return nil, err
+	}
+
+	return &blockedDate, nil
+}
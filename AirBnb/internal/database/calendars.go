@@ -0,0 +1,142 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type ExternalCalendar struct {
+	PropertyID uint
+	Name       string
+	URL        string
+}
+
+type BlockedDate struct {
+	StartDate time.Time
+	EndDate   time.Time
+	Summary   string
+	UID       string
+}
+
+func (s *service) FindPropertyExternalCalendars(propertyID uint) ([]models.ExternalCalendar, error) {
+	var calendars []models.ExternalCalendar
+	result := s.db.Where("property_id = ?", propertyID).Order("id ASC").Find(&calendars)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return calendars, nil
+}
+
+func (s *service) FindExternalCalendarById(propertyID uint, id uint) (*models.ExternalCalendar, error) {
+	var calendar models.ExternalCalendar
+	result := s.db.Where("id = ? AND property_id = ?", id, propertyID).First(&calendar)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &calendar, nil
+}
+
+// FindExternalCalendars returns the calendars of every property, in the order the sync job visits them.
+func (s *service) FindExternalCalendars() ([]models.ExternalCalendar, error) {
+	var calendars []models.ExternalCalendar
+	result := s.db.Order("id ASC").Find(&calendars)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return calendars, nil
+}
+
+// FindPropertyBlockedDates returns the blocks of a property that overlap the nights in [from, to).
+func (s *service) FindPropertyBlockedDates(propertyID uint, from time.Time, to time.Time) ([]models.BlockedDate, error) {
+	var blockedDates []models.BlockedDate
+	result := s.db.Where("property_id = ? AND start_date < ? AND end_date > ?", propertyID, to, from).
+		Order("start_date ASC").
+		Find(&blockedDates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return blockedDates, nil
+}
+
+func (s *service) CreateExternalCalendar(calendar ExternalCalendar) (*models.ExternalCalendar, error) {
+	newCalendar := &models.ExternalCalendar{
+		PropertyID: calendar.PropertyID,
+		Name:       calendar.Name,
+		URL:        calendar.URL,
+	}
+
+	if err := s.db.Create(newCalendar).Error; err != nil {
+		return nil, err
+	}
+
+	return newCalendar, nil
+}
+
+// DeleteExternalCalendar removes a calendar together with the dates it blocked.
+func (s *service) DeleteExternalCalendar(propertyID uint, id uint) (*models.ExternalCalendar, error) {
+	calendar, err := s.FindExternalCalendarById(propertyID, id)
+	if err != nil || calendar == nil {
+		return nil, err
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("external_calendar_id = ?", calendar.ID).Delete(&models.BlockedDate{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(calendar).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return calendar, nil
+}
+
+// ReplaceExternalCalendarBlocks swaps the dates blocked by a calendar for the events of its latest
+// import and marks the calendar as synced.
+func (s *service) ReplaceExternalCalendarBlocks(calendarID uint, blocks []BlockedDate, syncedAt time.Time) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the calendar so overlapping syncs of the same feed apply one after the other
+		var calendar models.ExternalCalendar
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&calendar, calendarID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("external_calendar_id = ?", calendar.ID).Delete(&models.BlockedDate{}).Error; err != nil {
+			return err
+		}
+
+		if len(blocks) > 0 {
+			newBlocks := make([]models.BlockedDate, 0, len(blocks))
+			for _, block := range blocks {
+				newBlocks = append(newBlocks, models.BlockedDate{
+					PropertyID:         calendar.PropertyID,
+					ExternalCalendarID: &calendar.ID,
+					StartDate:          block.StartDate,
+					EndDate:            block.EndDate,
+					Summary:            block.Summary,
+					UID:                block.UID,
+				})
+			}
+			if err := tx.Create(&newBlocks).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&calendar).Updates(map[string]interface{}{
+			"last_synced_at":  syncedAt,
+			"last_sync_error": "",
+		}).Error
+	})
+}
+
+// RecordExternalCalendarError keeps the blocks of the last successful sync and stores why the latest one failed.
+func (s *service) RecordExternalCalendarError(calendarID uint, syncErr string) error {
+	return s.db.Model(&models.ExternalCalendar{}).Where("id = ?", calendarID).Update("last_sync_error", syncErr).Error
+}
@@ -0,0 +1,142 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"time"
+)
+
+// Participant is the public profile of a conversation member.
+type Participant struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar"`
+}
+
+// ConversationSummary is one entry of a user's inbox.
+type ConversationSummary struct {
+	ID           uint                        `json:"id"`
+	ModifiedAt   time.Time                   `json:"modified_at"`
+	Participants []Participant               `json:"participants"`
+	LastMessage  *models.ConversationMessage `json:"last_message"`
+	UnreadCount  int64                       `json:"unread_count"`
+}
+
+// FindUserConversations lists the conversations of a user, most recently active first,
+// with the last message of each conversation and how many messages the user has not read.
+func (s *service) FindUserConversations(userID uint) ([]ConversationSummary, error) {
+	var conversations []models.Conversation
+
+	// Step 1: Load the conversations the user takes part in
+	result := s.db.Joins("JOIN conversation_users ON conversation_users.conversation_id = conversations.id").
+		Where("conversation_users.user_id = ?", userID).
+		Preload("Users", publicUserColumns).
+		Order("conversations.modified_at DESC").
+		Find(&conversations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if len(conversations) == 0 {
+		return []ConversationSummary{}, nil
+	}
+
+	ids := make([]uint, 0, len(conversations))
+	for _, conversation := range conversations {
+		ids = append(ids, conversation.ID)
+	}
+
+	// Step 2: Load the last message of every conversation in a single query
+	var lastMessages []models.ConversationMessage
+	result = s.db.Raw(`SELECT DISTINCT ON (conversation_id) * FROM conversation_messages
+		WHERE conversation_id IN (?) ORDER BY conversation_id, id DESC`, ids).
+		Scan(&lastMessages)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	lastByConversation := make(map[uint]models.ConversationMessage, len(lastMessages))
+	for _, message := range lastMessages {
+		lastByConversation[message.ConversationID] = message
+	}
+
+	// Step 3: Count the unread messages addressed to the user
+	var unread []struct {
+		ConversationID uint
+		Count          int64
+	}
+	result = s.db.Model(&models.ConversationMessage{}).
+		Select("conversation_id, COUNT(*) AS count").
+		Where("conversation_id IN (?) AND sent_to_id = ? AND read_at IS NULL", ids, userID).
+		Group("conversation_id").
+		Scan(&unread)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	unreadByConversation := make(map[uint]int64, len(unread))
+	for _, row := range unread {
+		unreadByConversation[row.ConversationID] = row.Count
+	}
+
+	summaries := make([]ConversationSummary, 0, len(conversations))
+	for _, conversation := range conversations {
+		participants := make([]Participant, len(conversation.Users))
+		for i, user := range conversation.Users {
+			participants[i] = Participant{ID: user.ID, Name: user.Name, Avatar: user.Avatar}
+		}
+
+		summary := ConversationSummary{
+			ID:           conversation.ID,
+			ModifiedAt:   conversation.ModifiedAt,
+			Participants: participants,
+			UnreadCount:  unreadByConversation[conversation.ID],
+		}
+		if message, ok := lastByConversation[conversation.ID]; ok {
+			summary.LastMessage = &message
+		}
+		summaries = append(summaries, summary)
+	}
+
+	return summaries, nil
+}
+
+// FindConversationMessages returns up to limit messages of a conversation, newest first.
+// When beforeID is set only messages older than that message are returned.
+func (s *service) FindConversationMessages(conversationID uint, beforeID uint, limit int) ([]models.ConversationMessage, error) {
+	var messages []models.ConversationMessage
+
+	query := s.db.Where("conversation_id = ?", conversationID)
+	if beforeID > 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	result := query.Order("id DESC").Limit(limit).Find(&messages)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return messages, nil
+}
+
+func (s *service) IsConversationParticipant(conversationID uint, userID uint) (bool, error) {
+	var count int64
+	result := s.db.Table("conversation_users").
+		Where("conversation_id = ? AND user_id = ?", conversationID, userID).
+		Count(&count)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return count > 0, nil
+}
+
+// MarkConversationRead marks every unread message sent to the user in the conversation as read
+// and returns how many messages were updated.
+func (s *service) MarkConversationRead(conversationID uint, userID uint) (int64, error) {
+	result := s.db.Model(&models.ConversationMessage{}).
+		Where("conversation_id = ? AND sent_to_id = ? AND read_at IS NULL", conversationID, userID).
+		Update("read_at", time.Now())
+	if result.Error != nil {
+		return 0, result.Error
+	}
+	return result.RowsAffected, nil
+}
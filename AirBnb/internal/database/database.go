@@ -1,8 +1,10 @@
 package database
 
 import (
+	"AirBnb/internal/booking"
 	"AirBnb/internal/models"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -12,9 +14,31 @@ import (
 	_ "github.com/joho/godotenv/autoload"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
+// ErrReservationOverlap is returned when a reservation overlaps an existing booking of the same property.
+var ErrReservationOverlap = errors.New("property is already booked for the selected dates")
+
+// ErrDatesBlocked is wrapped by the booking.RuleError returned when a reservation includes nights the host has blocked.
+var ErrDatesBlocked = errors.New("property is not available for the selected dates")
+
+// ErrInvalidReservationTransition is returned when a reservation cannot move to the requested status.
+var ErrInvalidReservationTransition = errors.New("reservation cannot move to the requested status")
+
+// ErrPropertyHasPayments is returned when deleting a property whose reservations have payments,
+// which are kept together with their ledger for accounting.
+var ErrPropertyHasPayments = errors.New("property has reservations with payments")
+
+// activeReservationCondition matches reservations that hold the property's nights.
+const activeReservationCondition = "status NOT IN ('declined', 'cancelled')"
+
+// publicUserColumns limits a user preload to the columns other users may see.
+func publicUserColumns(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name", "avatar")
+}
+
 type User struct {
 	ID         uint
 	Email      string
@@ -39,8 +63,13 @@ type Property struct {
 	Guests        int
 	Country       string
 	CountryCode   string
+	Address       string
+	City          string
+	Latitude      *float64
+	Longitude     *float64
 	Category      string
 	Image         string
+	InstantBook   bool
 	Landlord      User
 	LandlordID    uint
 	FavoritedBy   []User
@@ -53,7 +82,12 @@ type Reservation struct {
 	EndDate        time.Time
 	NumberOfNights int
 	Guests         int
+	NightsSubtotal float64
+	ExtraGuestFee  float64
+	CleaningFee    float64
+	Discount       float64
 	TotalPrice     float64
+	Status         string
 	CreatedAt      time.Time
 	CreatedByID    uint
 	PropertyID     uint
@@ -61,14 +95,26 @@ type Reservation struct {
 	Property       Property
 }
 
+type Review struct {
+	ID            uint
+	ReservationID uint
+	PropertyID    uint
+	GuestID       uint
+	Cleanliness   int
+	Accuracy      int
+	Location      int
+	Value         int
+	Comment       string
+}
+
 type ConversationMessage struct {
 	ID             uint
 	Body           string
 	ConversationID uint
 	CreatedByID    uint
-	SenderToID     uint
+	SentToID       uint
 	Conversation   models.Conversation
-	CreatedID      User
+	CreatedBy      User
 	SentTo         User
 }
 
@@ -84,24 +130,86 @@ type Service interface {
 	FindUserByEmail(email string) (*models.User, error)
 	FindUserByToken(token string) (*models.User, error)
 	FindPropertyById(Id int) (*models.Property, error)
-	FindAllProperties(page int, limit int) ([]models.Property, int64, error)
+	SearchProperties(filter PropertyFilter) ([]models.Property, string, error)
 	FindReservationById(id uint) (*models.Reservation, error)
+	FindPropertyReservations(propertyID uint, from time.Time, to time.Time) ([]models.Reservation, error)
+	FindLandlordReservations(landlordID uint, filter ReservationFilter) ([]models.Reservation, error)
+	FindLandlordProperties(landlordID uint) ([]models.Property, error)
+	FindLandlordEarnings(landlordID uint, from time.Time, to time.Time) ([]PropertyEarnings, error)
+	FindUserById(id uint) (*models.User, error)
 	FindOrCreateConversation(senderID, receiverID uint) (*models.Conversation, error)
+	FindUserConversations(userID uint) ([]ConversationSummary, error)
+	FindConversationMessages(conversationID uint, beforeID uint, limit int) ([]models.ConversationMessage, error)
+	IsConversationParticipant(conversationID uint, userID uint) (bool, error)
+	FindReviewById(id uint) (*models.Review, error)
+	FindReviewByReservationId(reservationID uint) (*models.Review, error)
+	FindPropertyReviews(propertyID uint, page int, limit int) ([]models.Review, int64, error)
+	FindUserFavorites(userID uint, page int, limit int) ([]models.Property, int64, error)
+	FindUserReservations(userID uint, filter ReservationFilter, page int, limit int) ([]models.Reservation, int64, error)
+	FindUserWishlists(userID uint) ([]models.Wishlist, error)
+	FindWishlistById(id uint) (*models.Wishlist, error)
+	FindWishlistByShareToken(token string) (*models.Wishlist, error)
+	FindUsers(filter UserFilter, page int, limit int) ([]models.User, int64, error)
+	FindPropertyReportById(id uint) (*models.PropertyReport, error)
+	FindOpenPropertyReport(propertyID uint, reporterID uint) (*models.PropertyReport, error)
+	FindPropertyReports(status string, page int, limit int) ([]models.PropertyReport, int64, error)
+	FindPaymentByReservationId(reservationID uint) (*models.Payment, error)
+	FindReservationLedger(reservationID uint) ([]models.LedgerTransaction, error)
+	FindReservationPayouts(reservationIDs []uint) (map[uint]int64, error)
+	FindPropertyExternalCalendars(propertyID uint) ([]models.ExternalCalendar, error)
+	FindExternalCalendarById(propertyID uint, id uint) (*models.ExternalCalendar, error)
+	FindExternalCalendars() ([]models.ExternalCalendar, error)
+	FindPropertyBlockedDates(propertyID uint, from time.Time, to time.Time) ([]models.BlockedDate, error)
+	FindPendingCheckInReminders(day time.Time) ([]models.Reservation, error)
 	//-----------------------Create ------------------------
 	CreateUser(user User) (*models.User, error)
 	CreateProperty(property Property) (*models.Property, error)
 	CreateReservation(reservation Reservation) (*models.Reservation, error)
 	AddFavoriteProperty(propertyId uint, userId uint) (*models.Property, error)
 	CreateConversationMessage(message ConversationMessage) (*models.ConversationMessage, error)
+	CreateReview(review Review) (*models.Review, error)
+	CreateSeasonalPrice(seasonalPrice SeasonalPrice) (*models.SeasonalPrice, error)
+	CreatePropertyImages(propertyID uint, images []PropertyImage) ([]models.PropertyImage, error)
+	CreateWishlist(wishlist Wishlist) (*models.Wishlist, error)
+	AddWishlistProperty(wishlistID uint, propertyID uint) (*models.Wishlist, error)
+	CreatePropertyReport(report PropertyReport) (*models.PropertyReport, error)
+	CreatePayment(payment Payment) (*models.Payment, error)
+	RecordLandlordPayout(reservationID uint) (*models.LedgerTransaction, error)
+	CreateExternalCalendar(calendar ExternalCalendar) (*models.ExternalCalendar, error)
+	CreateBlockedDate(propertyID uint, blockedDate BlockedDate) (*models.BlockedDate, error)
 	// --------------------Verify --------------------------
 	VerifyUserAndUpdate(token string) (*models.User, error)
+	MarkConversationRead(conversationID uint, userID uint) (int64, error)
 	// --------------------Delete---------------------------
 	DeleteUser(id string) (*models.User, error)
 	DeleteProperty(Id uint) (*models.Property, error)
-	DeleteReservation(id uint) (*models.Reservation, error)
 	DeleteFavoriteProperty(propertyId uint, userId uint) (*models.Property, error)
+	DeleteSeasonalPrice(propertyID uint, id uint) (*models.SeasonalPrice, error)
+	DeletePropertyImage(propertyID uint, imageID uint) (*models.PropertyImage, error)
+	RemoveWishlistProperty(wishlistID uint, propertyID uint) (*models.Wishlist, error)
+	DeleteWishlist(id uint) (*models.Wishlist, error)
+	DeleteExternalCalendar(propertyID uint, id uint) (*models.ExternalCalendar, error)
+	DeleteBlockedDate(propertyID uint, id uint) (*models.BlockedDate, error)
 	// ---------------------Update--------------------------
 	UpdateProperty(Id uint, property Property) (*models.Property, error)
+	ReplyToReview(id uint, reply string) (*models.Review, error)
+	UpdateReservationStatus(id uint, status string, actorID uint) (*models.Reservation, error)
+	UpdatePropertyPricing(id uint, pricing PropertyPricing) (*models.Property, error)
+	UpdatePropertyStayRules(id uint, rules StayRules) (*models.Property, error)
+	ReorderPropertyImages(propertyID uint, imageIDs []uint) ([]models.PropertyImage, error)
+	SetPropertyCoverImage(propertyID uint, imageID uint) (*models.PropertyImage, error)
+	RenameWishlist(id uint, name string) (*models.Wishlist, error)
+	SetWishlistShareToken(id uint, token *string) (*models.Wishlist, error)
+	SetUserActive(id uint, active bool) (*models.User, error)
+	SetPropertyPublished(id uint, published bool) (*models.Property, error)
+	ClosePropertyReport(id uint, status string, staffID uint, note string) (*models.PropertyReport, error)
+	ConfirmReservation(id uint, capture func(payment *models.Payment) error) (*models.Reservation, error)
+	CaptureReservationPayment(reservationID uint, capture func(payment *models.Payment) error) error
+	RecordPaymentRefund(paymentID uint, amount int64) (*models.Payment, error)
+	RecordPaymentVoid(paymentID uint) (*models.Payment, error)
+	ReplaceExternalCalendarBlocks(calendarID uint, blocks []BlockedDate, syncedAt time.Time) error
+	RecordExternalCalendarError(calendarID uint, syncErr string) error
+	MarkCheckInReminderSent(reservationID uint, sentAt time.Time) error
 
 	Close() error
 	GetDB() *gorm.DB // Add this method
@@ -128,6 +236,18 @@ func (s *service) FindUserByToken(token string) (*models.User, error) {
 	return &user, nil
 }
 
+func (s *service) FindUserById(id uint) (*models.User, error) {
+	var user models.User
+	result := s.db.Where("id = ?", id).First(&user)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &user, nil
+}
+
 func (s *service) FindReservationById(id uint) (*models.Reservation, error) {
 	var reservation models.Reservation
 	result := s.db.Preload("CreatedBy").
@@ -139,12 +259,34 @@ func (s *service) FindReservationById(id uint) (*models.Reservation, error) {
 	return &reservation, nil
 }
 
+// FindPropertyReservations returns the active reservations of a property that overlap the [from, to) range.
+func (s *service) FindPropertyReservations(propertyID uint, from time.Time, to time.Time) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+
+	result := s.db.Where("property_id = ? AND start_date < ? AND end_date > ?", propertyID, to, from).
+		Where(activeReservationCondition).
+		Order("start_date ASC").
+		Find(&reservations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return reservations, nil
+}
+
 func (s *service) FindPropertyById(Id int) (*models.Property, error) {
 	var property models.Property
 
-	result := s.db.Preload("Landlord").
+	result := s.db.Select("properties.*, "+propertyRatingColumns).
+		Preload("Landlord").
 		Preload("FavoritedBy").
 		Preload("Reservations").
+		Preload("SeasonalPrices", func(db *gorm.DB) *gorm.DB {
+			return db.Order("start_date ASC")
+		}).
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
 		Where("id = ?", Id).First(&property)
 
 	if result.Error != nil {
@@ -157,45 +299,30 @@ func (s *service) FindPropertyById(Id int) (*models.Property, error) {
 	return &property, nil
 }
 
-func (s *service) FindAllProperties(page int, limit int) ([]models.Property, int64, error) {
-	var properties []models.Property
-	var total int64
-
-	query := s.db.Model(&models.Property{})
-	query.Count(&total)
-
-	if limit > 0 {
-		offset := (page - 1) * limit
-		query = query.Offset(offset).Limit(limit)
-	}
-
-	result := query.Preload("Landlord").
-		Preload("FavoritedBy").
-		Preload("Reservations").
-		Find(&properties)
-
-	if result.Error != nil {
-		return nil, 0, result.Error
-	}
-
-	return properties, total, nil
-}
-
 // ---------------------------------------
 // ----------------- Create ---------------
 // ----------------------------------------
 
-func (s *service) CreateConversationMessage(message database.ConversationMessage) (*models.ConversationMessage, error) {
+func (s *service) CreateConversationMessage(message ConversationMessage) (*models.ConversationMessage, error) {
 	NewMessage := &models.ConversationMessage{
 		Body:           message.Body,
 		ConversationID: message.ConversationID,
 		CreatedByID:    message.CreatedByID,
-		SentToID:       message.SentToID, // Ensure this is set
+		SentToID:       message.SentToID,
 	}
 
-	result := s.db.Create(NewMessage)
-	if result.Error != nil {
-		return nil, result.Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(NewMessage).Error; err != nil {
+			return err
+		}
+
+		// Bump the conversation so it moves to the top of both inboxes
+		return tx.Model(&models.Conversation{}).
+			Where("id = ?", message.ConversationID).
+			Update("modified_at", NewMessage.CreatedAt).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 	return NewMessage, nil
 }
@@ -226,8 +353,13 @@ func (s *service) CreateProperty(property Property) (*models.Property, error) {
 		Guests:        property.Guests,
 		Country:       property.Country,
 		CountryCode:   property.CountryCode,
+		Address:       property.Address,
+		City:          property.City,
+		Latitude:      property.Latitude,
+		Longitude:     property.Longitude,
 		Category:      property.Category,
 		Image:         property.Image,
+		InstantBook:   property.InstantBook,
 		LandlordID:    property.LandlordID,
 	}
 
@@ -277,20 +409,74 @@ func (s *service) AddFavoriteProperty(propertyId uint, userId uint) (*models.Pro
 	return &property, nil
 }
 
+// CreateReservation books a property for the given dates. The property row is locked for the
+// duration of the transaction so that two concurrent bookings cannot both pass the overlap check.
 func (s *service) CreateReservation(reservation Reservation) (*models.Reservation, error) {
 	newReservation := &models.Reservation{
 		StartDate:      reservation.StartDate,
 		EndDate:        reservation.EndDate,
 		NumberOfNights: reservation.NumberOfNights,
 		Guests:         reservation.Guests,
+		NightsSubtotal: reservation.NightsSubtotal,
+		ExtraGuestFee:  reservation.ExtraGuestFee,
+		CleaningFee:    reservation.CleaningFee,
+		Discount:       reservation.Discount,
 		TotalPrice:     reservation.TotalPrice,
+		Status:         models.ReservationRequested,
 		CreatedByID:    reservation.CreatedByID,
 		PropertyID:     reservation.PropertyID,
 	}
 
-	result := s.db.Create(newReservation)
-	if result.Error != nil {
-		return nil, result.Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Step 1: Lock the property so bookings for it are serialized
+		var property models.Property
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&property, reservation.PropertyID).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return fmt.Errorf("property with ID %d not found", reservation.PropertyID)
+			}
+			return err
+		}
+
+		// Step 2: Reject the booking if any existing reservation overlaps the requested nights
+		var overlapping int64
+		if err := tx.Model(&models.Reservation{}).
+			Where("property_id = ? AND start_date < ? AND end_date > ?", reservation.PropertyID, reservation.EndDate, reservation.StartDate).
+			Where(activeReservationCondition).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return ErrReservationOverlap
+		}
+
+		// Step 3: Reject the booking if the host blocked any of the nights, by hand or through an imported calendar
+		var blocked int64
+		if err := tx.Model(&models.BlockedDate{}).
+			Where("property_id = ? AND start_date < ? AND end_date > ?", reservation.PropertyID, reservation.EndDate, reservation.StartDate).
+			Count(&blocked).Error; err != nil {
+			return err
+		}
+		if blocked > 0 {
+			return &booking.RuleError{
+				Rule:    booking.RuleBlockedDates,
+				Message: "The host has blocked some of the selected nights",
+				Err:     ErrDatesBlocked,
+			}
+		}
+
+		// Step 4: Check the stay against the rules of the property as they are when it is booked
+		if err := booking.RulesFromProperty(property).Check(reservation.StartDate, reservation.EndDate); err != nil {
+			return err
+		}
+
+		// Step 5: Create the reservation, skipping landlord approval for instant-book properties
+		if property.InstantBook {
+			newReservation.Status = models.ReservationConfirmed
+		}
+		return tx.Create(newReservation).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	// Fetch the complete reservation with associations
@@ -302,31 +488,47 @@ func (s *service) CreateReservation(reservation Reservation) (*models.Reservatio
 	return &createdReservation, nil
 }
 
+// FindOrCreateConversation returns the conversation whose participants are exactly the
+// sender and the receiver, creating it when the two users have never talked before.
 func (s *service) FindOrCreateConversation(senderID, receiverID uint) (*models.Conversation, error) {
 	var conversation models.Conversation
 
-	// Check if a conversation already exists between the two users
-	err := s.db.Joins("JOIN conversation_users ON conversation_users.conversation_id = conversations.id").
-		Where("conversation_users.user_id IN (?, ?)", senderID, receiverID).
-		Group("conversations.id").
-		Having("COUNT(DISTINCT conversation_users.user_id) = 2").
-		First(&conversation).Error
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Serialize conversation creation for this pair of users so concurrent first
+		// messages cannot create two conversations
+		low, high := senderID, receiverID
+		if low > high {
+			low, high = high, low
+		}
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(?, ?)", int32(low), int32(high)).Error; err != nil {
+			return err
+		}
 
-	if err != nil && err != gorm.ErrRecordNotFound {
-		return nil, err
-	}
+		// Check if a conversation already exists with exactly these two participants
+		err := tx.Where("id IN (?)", tx.Table("conversation_users").
+			Select("conversation_id").
+			Group("conversation_id").
+			Having("COUNT(DISTINCT user_id) = 2 AND COUNT(*) FILTER (WHERE user_id NOT IN (?, ?)) = 0", senderID, receiverID),
+		).First(&conversation).Error
+
+		if err != gorm.ErrRecordNotFound {
+			return err
+		}
 
-	// If no conversation exists, create a new one
-	if err == gorm.ErrRecordNotFound {
+		// If no conversation exists, create a new one without touching the user rows
+		now := time.Now()
 		conversation = models.Conversation{
+			CreatedAt:  now,
+			ModifiedAt: now,
 			Users: []models.User{
 				{ID: senderID},
 				{ID: receiverID},
 			},
 		}
-		if err := s.db.Create(&conversation).Error; err != nil {
-			return nil, err
-		}
+		return tx.Omit("Users.*").Create(&conversation).Error
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	return &conversation, nil
@@ -384,25 +586,6 @@ func (s *service) DeleteUser(id string) (*models.User, error) {
 	return &user, nil
 }
 
-func (s *service) DeleteReservation(id uint) (*models.Reservation, error) {
-	var reservation models.Reservation
-
-	result := s.db.Where("id = ?", id).First(&reservation)
-
-	if result.Error != nil {
-		if result.Error == gorm.ErrRecordNotFound {
-			return nil, nil
-		}
-		return nil, result.Error
-	}
-
-	if err := s.db.Delete(reservation).Error; err != nil {
-		return nil, err
-	}
-
-	return &reservation, nil
-}
-
 func (s *service) DeleteProperty(Id uint) (*models.Property, error) {
 	var property models.Property
 
@@ -417,7 +600,33 @@ func (s *service) DeleteProperty(Id uint) (*models.Property, error) {
 	}
 
 	// Delete the user
-	if err := s.db.Select("FavoritedBy", "Reservations").Delete(&property).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var paid int64
+		if err := tx.Model(&models.Payment{}).
+			Joins("JOIN reservations ON reservations.id = payments.reservation_id").
+			Where("reservations.property_id = ?", property.ID).
+			Count(&paid).Error; err != nil {
+			return err
+		}
+		if paid > 0 {
+			return ErrPropertyHasPayments
+		}
+
+		// Reviews reference both the property and its reservations, so they go first
+		if err := tx.Where("property_id = ?", property.ID).Delete(&models.Review{}).Error; err != nil {
+			return err
+		}
+		// Imported blocked dates reference their external calendar, so blocked dates go before
+		// calendars and both before the property
+		if err := tx.Where("property_id = ?", property.ID).Delete(&models.BlockedDate{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("property_id = ?", property.ID).Delete(&models.ExternalCalendar{}).Error; err != nil {
+			return err
+		}
+		return tx.Select("FavoritedBy", "Reservations", "Images", "SeasonalPrices", "Wishlists", "Reports").Delete(&property).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 
@@ -488,11 +697,20 @@ func (s *service) UpdateProperty(Id uint, property Property) (*models.Property,
 		"guests":          property.Guests,
 		"country":         property.Country,
 		"country_code":    property.CountryCode,
+		"address":         property.Address,
+		"city":            property.City,
+		"latitude":        property.Latitude,
+		"longitude":       property.Longitude,
 		"category":        property.Category,
-		"image":           property.Image,
+		"instant_book":    property.InstantBook,
 		"landlord_id":     property.LandlordID,
 	}
 
+	// Keep the current image when no new one was uploaded
+	if property.Image != "" {
+		updates["image"] = property.Image
+	}
+
 	// Update the property in the database
 	if err := s.db.Model(&FindProperty).Updates(updates).Error; err != nil {
 		return nil, err // Return error if update fails
@@ -502,6 +720,41 @@ func (s *service) UpdateProperty(Id uint, property Property) (*models.Property,
 	return &FindProperty, nil
 }
 
+// UpdateReservationStatus moves a reservation to a new status. The reservation row is locked so
+// that concurrent transitions are applied one after the other. Cancellations record who
+// cancelled and when.
+func (s *service) UpdateReservationStatus(id uint, status string, actorID uint) (*models.Reservation, error) {
+	var reservation models.Reservation
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id).Error; err != nil {
+			return err
+		}
+
+		if !models.CanTransitionReservation(reservation.Status, status) {
+			return ErrInvalidReservationTransition
+		}
+
+		updates := map[string]interface{}{
+			"status": status,
+		}
+		if status == models.ReservationCancelled {
+			updates["cancelled_at"] = time.Now()
+			updates["cancelled_by_id"] = actorID
+		}
+
+		return tx.Model(&reservation).Updates(updates).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return s.FindReservationById(reservation.ID)
+}
+
 type service struct {
 	db *gorm.DB
 }
@@ -546,6 +799,16 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.Property{},
 		&models.Reservation{},
 		&models.ConversationMessage{},
+		&models.Review{},
+		&models.SeasonalPrice{},
+		&models.PropertyImage{},
+		&models.Wishlist{},
+		&models.PropertyReport{},
+		&models.Payment{},
+		&models.LedgerTransaction{},
+		&models.LedgerEntry{},
+		&models.ExternalCalendar{},
+		&models.BlockedDate{},
 	)
 }
 
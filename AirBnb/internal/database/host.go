@@ -0,0 +1,120 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"AirBnb/internal/payments"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	PeriodUpcoming = "upcoming"
+	PeriodPast     = "past"
+)
+
+// earningReservationCondition matches reservations that were accepted and therefore earn money.
+const earningReservationCondition = "reservations.status IN ('confirmed', 'checked_in', 'completed')"
+
+// ReservationFilter narrows down a list of reservations. Zero values mean the filter is not applied.
+// Upcoming reservations have not ended yet, past reservations have. From and To select the
+// reservations that overlap the [From, To) range.
+type ReservationFilter struct {
+	Status string
+	Period string
+	From   time.Time
+	To     time.Time
+}
+
+// PropertyEarnings holds GrossEarnings, the total price guests were quoted, and NetEarnings, the
+// landlord's share of the captured payments after the platform fee and refunds.
+type PropertyEarnings struct {
+	PropertyID    uint    `json:"property_id"`
+	Title         string  `json:"title"`
+	Reservations  int64   `json:"reservations"`
+	Nights        int64   `json:"nights"`
+	GrossEarnings float64 `json:"gross_earnings"`
+	NetEarnings   float64 `json:"net_earnings"`
+}
+
+// FindLandlordReservations returns the reservations made on the landlord's properties.
+func (s *service) FindLandlordReservations(landlordID uint, filter ReservationFilter) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+
+	query := s.db.Joins("JOIN properties ON properties.id = reservations.property_id").
+		Where("properties.landlord_id = ?", landlordID)
+	if filter.Status != "" {
+		query = query.Where("reservations.status = ?", filter.Status)
+	}
+	switch filter.Period {
+	case PeriodUpcoming:
+		query = query.Where("reservations.end_date >= ?", time.Now())
+	case PeriodPast:
+		query = query.Where("reservations.end_date < ?", time.Now())
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("reservations.end_date > ?", filter.From)
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("reservations.start_date < ?", filter.To)
+	}
+
+	result := query.Preload("CreatedBy", guestContactColumns).
+		Preload("Property").
+		Order("reservations.start_date ASC").
+		Find(&reservations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return reservations, nil
+}
+
+// guestContactColumns limits a guest preload to what the landlord needs to host the stay,
+// their public profile and the email address shown in the bookings export.
+func guestContactColumns(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name", "avatar", "email")
+}
+
+func (s *service) FindLandlordProperties(landlordID uint) ([]models.Property, error) {
+	var properties []models.Property
+
+	result := s.db.Where("landlord_id = ?", landlordID).Order("id ASC").Find(&properties)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return properties, nil
+}
+
+// FindLandlordEarnings sums the accepted reservations of each of the landlord's properties that
+// start in the [from, to) range. Properties without reservations are listed with zero earnings.
+// Net earnings come from the ledger, so they also include what the landlord kept of cancelled
+// reservations that were partially refunded.
+func (s *service) FindLandlordEarnings(landlordID uint, from time.Time, to time.Time) ([]PropertyEarnings, error) {
+	var earnings []PropertyEarnings
+
+	result := s.db.Table("properties").
+		Select("properties.id AS property_id, properties.title, "+
+			"COUNT(reservations.id) AS reservations, "+
+			"COALESCE(SUM(reservations.number_of_nights), 0) AS nights, "+
+			"COALESCE(SUM(reservations.total_price), 0) AS gross_earnings, "+
+			"COALESCE((SELECT SUM(ledger_entries.credit - ledger_entries.debit) "+
+			"FROM ledger_entries "+
+			"JOIN ledger_transactions ON ledger_transactions.id = ledger_entries.transaction_id "+
+			"JOIN reservations paid ON paid.id = ledger_transactions.reservation_id "+
+			"WHERE paid.property_id = properties.id AND paid.start_date >= ? AND paid.start_date < ? "+
+			"AND ledger_entries.account = ? AND ledger_transactions.kind IN ?), 0) / 100.0 AS net_earnings",
+			from, to, payments.AccountLandlordPayable, []string{payments.KindCapture, payments.KindRefund}).
+		Joins("LEFT JOIN reservations ON reservations.property_id = properties.id AND "+earningReservationCondition+
+			" AND reservations.start_date >= ? AND reservations.start_date < ?", from, to).
+		Where("properties.landlord_id = ?", landlordID).
+		Group("properties.id, properties.title").
+		Order("properties.id ASC").
+		Scan(&earnings)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return earnings, nil
+}
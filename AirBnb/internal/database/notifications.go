@@ -0,0 +1,25 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"time"
+)
+
+// FindPendingCheckInReminders returns the confirmed reservations starting on day whose guest
+// has not been reminded yet.
+func (s *service) FindPendingCheckInReminders(day time.Time) ([]models.Reservation, error) {
+	var reservations []models.Reservation
+	result := s.db.Preload("CreatedBy").Preload("Property").
+		Where("status = ? AND start_date >= ? AND start_date < ?", models.ReservationConfirmed, day, day.AddDate(0, 0, 1)).
+		Where("check_in_reminder_sent_at IS NULL").
+		Order("id ASC").
+		Find(&reservations)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return reservations, nil
+}
+
+func (s *service) MarkCheckInReminderSent(reservationID uint, sentAt time.Time) error {
+	return s.db.Model(&models.Reservation{}).Where("id = ?", reservationID).Update("check_in_reminder_sent_at", sentAt).Error
+}
@@ -0,0 +1,294 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"AirBnb/internal/payments"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// ErrUnbalancedLedger is returned when the debits of a ledger transaction do not equal its credits.
+var ErrUnbalancedLedger = errors.New("ledger transaction is not balanced")
+
+type Payment struct {
+	ReservationID     uint
+	Provider          string
+	ProviderPaymentID string
+	Amount            int64
+}
+
+func (s *service) FindPaymentByReservationId(reservationID uint) (*models.Payment, error) {
+	var payment models.Payment
+	result := s.db.Where("reservation_id = ?", reservationID).First(&payment)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &payment, nil
+}
+
+// FindReservationLedger returns the ledger transactions of a reservation in the order they happened.
+func (s *service) FindReservationLedger(reservationID uint) ([]models.LedgerTransaction, error) {
+	var transactions []models.LedgerTransaction
+
+	result := s.db.Preload("Entries", func(db *gorm.DB) *gorm.DB {
+		return db.Order("id ASC")
+	}).
+		Where("reservation_id = ?", reservationID).
+		Order("id ASC").
+		Find(&transactions)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return transactions, nil
+}
+
+// FindReservationPayouts returns the landlord's net share in cents of each of the reservations,
+// that is the captured amount minus the platform fee and any refunds. Reservations without a
+// captured payment are left out of the map.
+func (s *service) FindReservationPayouts(reservationIDs []uint) (map[uint]int64, error) {
+	payouts := make(map[uint]int64, len(reservationIDs))
+	if len(reservationIDs) == 0 {
+		return payouts, nil
+	}
+
+	var rows []struct {
+		ReservationID uint
+		Amount        int64
+	}
+	result := s.db.Table("ledger_entries").
+		Select("ledger_transactions.reservation_id, SUM(ledger_entries.credit - ledger_entries.debit) AS amount").
+		Joins("JOIN ledger_transactions ON ledger_transactions.id = ledger_entries.transaction_id").
+		Where("ledger_transactions.reservation_id IN ?", reservationIDs).
+		Where("ledger_entries.account = ? AND ledger_transactions.kind IN ?",
+			payments.AccountLandlordPayable, []string{payments.KindCapture, payments.KindRefund}).
+		Group("ledger_transactions.reservation_id").
+		Scan(&rows)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	for _, row := range rows {
+		payouts[row.ReservationID] = row.Amount
+	}
+	return payouts, nil
+}
+
+// CreatePayment stores a payment that was authorized at the provider.
+func (s *service) CreatePayment(payment Payment) (*models.Payment, error) {
+	newPayment := &models.Payment{
+		ReservationID:     payment.ReservationID,
+		Provider:          payment.Provider,
+		ProviderPaymentID: payment.ProviderPaymentID,
+		Status:            models.PaymentAuthorized,
+		Amount:            payment.Amount,
+	}
+
+	result := s.db.Create(newPayment)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return newPayment, nil
+}
+
+// ConfirmReservation moves a requested reservation to confirmed and captures its payment in the
+// same transaction. The reservation and payment rows stay locked while capture charges the guest
+// at the provider, so a concurrent cancellation waits for the outcome and refunds the captured
+// payment instead of voiding it. When capture fails, the reservation stays requested.
+func (s *service) ConfirmReservation(id uint, capture func(payment *models.Payment) error) (*models.Reservation, error) {
+	var reservation models.Reservation
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, id).Error; err != nil {
+			return err
+		}
+		if !models.CanTransitionReservation(reservation.Status, models.ReservationConfirmed) {
+			return ErrInvalidReservationTransition
+		}
+
+		if err := tx.Model(&reservation).Update("status", models.ReservationConfirmed).Error; err != nil {
+			return err
+		}
+
+		return captureReservationPayment(tx, reservation.ID, capture)
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return s.FindReservationById(reservation.ID)
+}
+
+// CaptureReservationPayment captures the payment of a reservation that was confirmed when it was
+// booked. Like ConfirmReservation it keeps the reservation locked while capture runs, and fails
+// with ErrInvalidReservationTransition when the reservation was cancelled in the meantime.
+func (s *service) CaptureReservationPayment(reservationID uint, capture func(payment *models.Payment) error) error {
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var reservation models.Reservation
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&reservation, reservationID).Error; err != nil {
+			return err
+		}
+		if reservation.Status != models.ReservationConfirmed {
+			return ErrInvalidReservationTransition
+		}
+
+		return captureReservationPayment(tx, reservation.ID, capture)
+	})
+}
+
+// RecordPaymentRefund adds a refund to a captured payment and books it.
+func (s *service) RecordPaymentRefund(paymentID uint, amount int64) (*models.Payment, error) {
+	var payment models.Payment
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPayment(tx, paymentID, &payment); err != nil {
+			return err
+		}
+		if payment.Status != models.PaymentCaptured && payment.Status != models.PaymentPartiallyRefunded {
+			return payments.ErrInvalidPaymentState
+		}
+		if payment.RefundedAmount+amount > payment.CapturedAmount {
+			return payments.ErrInvalidPaymentState
+		}
+
+		payment.RefundedAmount += amount
+		payment.Status = models.PaymentPartiallyRefunded
+		if payment.RefundedAmount == payment.CapturedAmount {
+			payment.Status = models.PaymentRefunded
+		}
+		if err := tx.Save(&payment).Error; err != nil {
+			return err
+		}
+
+		_, err := createLedgerTransaction(tx, payment.ReservationID, payments.RefundTransaction(amount, payment.CapturedAmount))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// RecordPaymentVoid marks an authorized payment as voided. No money moved, so nothing is booked.
+func (s *service) RecordPaymentVoid(paymentID uint) (*models.Payment, error) {
+	var payment models.Payment
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockPayment(tx, paymentID, &payment); err != nil {
+			return err
+		}
+		if payment.Status != models.PaymentAuthorized {
+			return payments.ErrInvalidPaymentState
+		}
+
+		payment.Status = models.PaymentVoided
+		return tx.Save(&payment).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &payment, nil
+}
+
+// RecordLandlordPayout pays out what the landlord is still owed for a reservation. It returns nil
+// when nothing is owed.
+func (s *service) RecordLandlordPayout(reservationID uint) (*models.LedgerTransaction, error) {
+	var payout *models.LedgerTransaction
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the payment so that two payouts of the same reservation cannot both see a balance
+		var payment models.Payment
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("reservation_id = ?", reservationID).First(&payment).Error; err != nil {
+			if err == gorm.ErrRecordNotFound {
+				return nil
+			}
+			return err
+		}
+
+		var owed int64
+		err := tx.Table("ledger_entries").
+			Select("COALESCE(SUM(ledger_entries.credit - ledger_entries.debit), 0)").
+			Joins("JOIN ledger_transactions ON ledger_transactions.id = ledger_entries.transaction_id").
+			Where("ledger_transactions.reservation_id = ? AND ledger_entries.account = ?", reservationID, payments.AccountLandlordPayable).
+			Scan(&owed).Error
+		if err != nil || owed <= 0 {
+			return err
+		}
+
+		payout, err = createLedgerTransaction(tx, reservationID, payments.PayoutTransaction(owed))
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return payout, nil
+}
+
+func lockPayment(tx *gorm.DB, paymentID uint, payment *models.Payment) error {
+	return tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(payment, paymentID).Error
+}
+
+// captureReservationPayment calls capture for the authorized payment of a reservation and books
+// the guest charge. Reservations made before payments existed have no payment and are skipped.
+func captureReservationPayment(tx *gorm.DB, reservationID uint, capture func(payment *models.Payment) error) error {
+	var payment models.Payment
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("reservation_id = ?", reservationID).First(&payment).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil
+		}
+		return err
+	}
+	if payment.Status != models.PaymentAuthorized {
+		return payments.ErrInvalidPaymentState
+	}
+
+	if err := capture(&payment); err != nil {
+		return err
+	}
+
+	payment.Status = models.PaymentCaptured
+	payment.CapturedAmount = payment.Amount
+	if err := tx.Save(&payment).Error; err != nil {
+		return err
+	}
+
+	_, err := createLedgerTransaction(tx, reservationID, payments.CaptureTransaction(payment.Amount))
+	return err
+}
+
+func createLedgerTransaction(tx *gorm.DB, reservationID uint, transaction payments.Transaction) (*models.LedgerTransaction, error) {
+	if !transaction.Balanced() {
+		return nil, ErrUnbalancedLedger
+	}
+
+	ledgerTransaction := models.LedgerTransaction{
+		ReservationID: reservationID,
+		Kind:          transaction.Kind,
+		Description:   transaction.Description,
+	}
+	for _, entry := range transaction.Entries {
+		ledgerTransaction.Entries = append(ledgerTransaction.Entries, models.LedgerEntry{
+			Account: entry.Account,
+			Debit:   entry.Debit,
+			Credit:  entry.Credit,
+		})
+	}
+
+	if err := tx.Create(&ledgerTransaction).Error; err != nil {
+		return nil, err
+	}
+
+	return &ledgerTransaction, nil
+}
@@ -0,0 +1,100 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"fmt"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type PropertyPricing struct {
+	WeekendPrice    int
+	CleaningFee     int
+	BaseGuests      int
+	ExtraGuestFee   int
+	WeeklyDiscount  int
+	MonthlyDiscount int
+	// CancellationPolicy is left unchanged when empty
+	CancellationPolicy string
+}
+
+type SeasonalPrice struct {
+	ID            uint
+	PropertyID    uint
+	StartDate     time.Time
+	EndDate       time.Time
+	PricePerNight int
+}
+
+func (s *service) UpdatePropertyPricing(id uint, pricing PropertyPricing) (*models.Property, error) {
+	updates := map[string]interface{}{
+		"weekend_price":    pricing.WeekendPrice,
+		"cleaning_fee":     pricing.CleaningFee,
+		"base_guests":      pricing.BaseGuests,
+		"extra_guest_fee":  pricing.ExtraGuestFee,
+		"weekly_discount":  pricing.WeeklyDiscount,
+		"monthly_discount": pricing.MonthlyDiscount,
+	}
+	if pricing.CancellationPolicy != "" {
+		updates["cancellation_policy"] = pricing.CancellationPolicy
+	}
+
+	result := s.db.Model(&models.Property{}).Where("id = ?", id).Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+
+	return s.FindPropertyById(int(id))
+}
+
+// CreateSeasonalPrice adds a seasonal price to a property. Seasons of the same property may not overlap.
+func (s *service) CreateSeasonalPrice(seasonalPrice SeasonalPrice) (*models.SeasonalPrice, error) {
+	newSeasonalPrice := &models.SeasonalPrice{
+		PropertyID:    seasonalPrice.PropertyID,
+		StartDate:     seasonalPrice.StartDate,
+		EndDate:       seasonalPrice.EndDate,
+		PricePerNight: seasonalPrice.PricePerNight,
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var overlapping int64
+		if err := tx.Model(&models.SeasonalPrice{}).
+			Where("property_id = ? AND start_date < ? AND end_date > ?", seasonalPrice.PropertyID, seasonalPrice.EndDate, seasonalPrice.StartDate).
+			Count(&overlapping).Error; err != nil {
+			return err
+		}
+		if overlapping > 0 {
+			return fmt.Errorf("seasonal price overlaps an existing season")
+		}
+
+		return tx.Create(newSeasonalPrice).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newSeasonalPrice, nil
+}
+
+func (s *service) DeleteSeasonalPrice(propertyID uint, id uint) (*models.SeasonalPrice, error) {
+	var seasonalPrice models.SeasonalPrice
+
+	result := s.db.Where("id = ? AND property_id = ?", id, propertyID).First(&seasonalPrice)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	if err := s.db.Delete(&seasonalPrice).Error; err != nil {
+		return nil, err
+	}
+
+	return &seasonalPrice, nil
+}
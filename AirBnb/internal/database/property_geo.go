@@ -0,0 +1,68 @@
+package database
+
+import (
+	"AirBnb/internal/utils"
+
+	"gorm.io/gorm"
+)
+
+// GeoPoint is a location in decimal degrees.
+type GeoPoint struct {
+	Latitude  float64
+	Longitude float64
+}
+
+// BoundingBox is the area of a map view in decimal degrees. A box that crosses the
+// antimeridian has a MinLongitude greater than its MaxLongitude.
+type BoundingBox struct {
+	MinLatitude  float64
+	MinLongitude float64
+	MaxLatitude  float64
+	MaxLongitude float64
+}
+
+// distanceExpression is the haversine distance in kilometres between a property and a point.
+// Its placeholders are filled by distanceArgs.
+const distanceExpression = "(? * 2 * ASIN(SQRT(LEAST(1, " +
+	"POWER(SIN(RADIANS(properties.latitude - ?) / 2), 2) + " +
+	"COS(RADIANS(?)) * COS(RADIANS(properties.latitude)) * POWER(SIN(RADIANS(properties.longitude - ?) / 2), 2)))))"
+
+func distanceArgs(point GeoPoint) []interface{} {
+	return []interface{}{utils.EarthRadiusKm, point.Latitude, point.Latitude, point.Longitude}
+}
+
+func applyBoundingBox(query *gorm.DB, box BoundingBox) *gorm.DB {
+	query = query.Where("properties.latitude BETWEEN ? AND ?", box.MinLatitude, box.MaxLatitude)
+	if box.MinLongitude <= box.MaxLongitude {
+		return query.Where("properties.longitude BETWEEN ? AND ?", box.MinLongitude, box.MaxLongitude)
+	}
+	return query.Where("(properties.longitude >= ? OR properties.longitude <= ?)", box.MinLongitude, box.MaxLongitude)
+}
+
+// applyLocationFilter keeps the properties inside the filter's bounding box and radius.
+// Properties without coordinates never match a location search.
+func applyLocationFilter(query *gorm.DB, filter PropertyFilter) *gorm.DB {
+	if filter.Center == nil && filter.Bounds == nil {
+		return query
+	}
+
+	query = query.Where("properties.latitude IS NOT NULL AND properties.longitude IS NOT NULL")
+	if filter.Bounds != nil {
+		query = applyBoundingBox(query, *filter.Bounds)
+	}
+
+	if filter.Center != nil && filter.RadiusKm > 0 {
+		// The box around the circle lets the location index skip far away rows before
+		// the exact distance is computed
+		minLat, minLng, maxLat, maxLng := utils.RadiusBounds(filter.Center.Latitude, filter.Center.Longitude, filter.RadiusKm)
+		query = applyBoundingBox(query, BoundingBox{
+			MinLatitude:  minLat,
+			MinLongitude: minLng,
+			MaxLatitude:  maxLat,
+			MaxLongitude: maxLng,
+		})
+		query = query.Where(distanceExpression+" <= ?", append(distanceArgs(*filter.Center), filter.RadiusKm)...)
+	}
+
+	return query
+}
@@ -0,0 +1,178 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"fmt"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type PropertyImage struct {
+	Path          string
+	ThumbnailPath string
+}
+
+func (s *service) findPropertyImages(tx *gorm.DB, propertyID uint) ([]models.PropertyImage, error) {
+	var images []models.PropertyImage
+	result := tx.Where("property_id = ?", propertyID).Order("position ASC").Find(&images)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	return images, nil
+}
+
+// CreatePropertyImages appends images to the end of a property's gallery. When the
+// property has no cover yet, the first new image becomes the cover.
+func (s *service) CreatePropertyImages(propertyID uint, images []PropertyImage) ([]models.PropertyImage, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock the property so concurrent uploads get distinct positions
+		var property models.Property
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&property, propertyID).Error; err != nil {
+			return err
+		}
+
+		existing, err := s.findPropertyImages(tx, propertyID)
+		if err != nil {
+			return err
+		}
+
+		hasCover := false
+		for _, image := range existing {
+			hasCover = hasCover || image.IsCover
+		}
+
+		newImages := make([]models.PropertyImage, 0, len(images))
+		for i, image := range images {
+			newImages = append(newImages, models.PropertyImage{
+				PropertyID:    propertyID,
+				Path:          image.Path,
+				ThumbnailPath: image.ThumbnailPath,
+				Position:      len(existing) + i,
+				IsCover:       !hasCover && i == 0,
+			})
+		}
+
+		if err := tx.Create(&newImages).Error; err != nil {
+			return err
+		}
+
+		if !hasCover {
+			return tx.Model(&property).Update("image", newImages[0].Path).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.findPropertyImages(s.db, propertyID)
+}
+
+// ReorderPropertyImages sets the gallery order. imageIDs must list every image of the property exactly once.
+func (s *service) ReorderPropertyImages(propertyID uint, imageIDs []uint) ([]models.PropertyImage, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		existing, err := s.findPropertyImages(tx, propertyID)
+		if err != nil {
+			return err
+		}
+
+		if len(existing) != len(imageIDs) {
+			return fmt.Errorf("the new order must list all %d images of the property", len(existing))
+		}
+
+		known := make(map[uint]bool, len(existing))
+		for _, image := range existing {
+			known[image.ID] = true
+		}
+
+		for position, id := range imageIDs {
+			if !known[id] {
+				return fmt.Errorf("image with ID %d does not belong to the property or is listed twice", id)
+			}
+			delete(known, id)
+
+			if err := tx.Model(&models.PropertyImage{}).Where("id = ?", id).Update("position", position).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.findPropertyImages(s.db, propertyID)
+}
+
+// SetPropertyCoverImage makes an image the cover of its property and mirrors it in Property.Image.
+func (s *service) SetPropertyCoverImage(propertyID uint, imageID uint) (*models.PropertyImage, error) {
+	var image models.PropertyImage
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND property_id = ?", imageID, propertyID).First(&image).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.PropertyImage{}).Where("property_id = ?", propertyID).Update("is_cover", false).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&image).Update("is_cover", true).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Property{}).Where("id = ?", propertyID).Update("image", image.Path).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &image, nil
+}
+
+// DeletePropertyImage removes an image from the gallery. When the cover is deleted the next
+// image in the gallery becomes the cover. Removing the files is left to the caller.
+func (s *service) DeletePropertyImage(propertyID uint, imageID uint) (*models.PropertyImage, error) {
+	var image models.PropertyImage
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("id = ? AND property_id = ?", imageID, propertyID).First(&image).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&image).Error; err != nil {
+			return err
+		}
+
+		if !image.IsCover {
+			return nil
+		}
+
+		remaining, err := s.findPropertyImages(tx, propertyID)
+		if err != nil {
+			return err
+		}
+
+		coverPath := ""
+		if len(remaining) > 0 {
+			coverPath = remaining[0].Path
+			if err := tx.Model(&remaining[0]).Update("is_cover", true).Error; err != nil {
+				return err
+			}
+		}
+
+		return tx.Model(&models.Property{}).Where("id = ?", propertyID).Update("image", coverPath).Error
+	})
+	if err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &image, nil
+}
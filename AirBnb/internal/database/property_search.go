@@ -0,0 +1,229 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"AirBnb/internal/utils"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	SortNewest        = "newest"
+	SortPriceAsc      = "price_asc"
+	SortPriceDesc     = "price_desc"
+	SortMostFavorited = "most_favorited"
+	SortDistance      = "distance"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded.
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// favoritesCountQuery counts how many users saved a property to their favorites.
+const favoritesCountQuery = "(SELECT COUNT(*) FROM user_favorites WHERE user_favorites.property_id = properties.id)"
+
+// PropertyFilter holds the search criteria accepted by SearchProperties.
+// Zero values mean the filter is not applied.
+type PropertyFilter struct {
+	Country       string
+	CountryCode   string
+	Category      string
+	MinPrice      int
+	MaxPrice      int
+	MinBedrooms   int
+	MinBathrooms  int
+	MinGuests     int
+	AvailableFrom time.Time
+	AvailableTo   time.Time
+	// Center is the point distances are measured from, RadiusKm limits the results to a
+	// circle around it and Bounds limits them to a map view.
+	Center   *GeoPoint
+	RadiusKm float64
+	Bounds   *BoundingBox
+	Sort     string
+	Cursor   string
+	Limit    int
+}
+
+// propertyCursor marks the last property of a page. Value holds the sort key of that
+// property and ID breaks ties so that pages never overlap or skip listings.
+type propertyCursor struct {
+	Value string `json:"v"`
+	ID    uint   `json:"id"`
+}
+
+func encodeCursor(cursor propertyCursor) string {
+	raw, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func decodeCursor(value string) (*propertyCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var cursor propertyCursor
+	if err := json.Unmarshal(raw, &cursor); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &cursor, nil
+}
+
+// SearchProperties returns one page of properties matching the filter together with the
+// cursor of the next page. The next cursor is empty when there are no more results.
+func (s *service) SearchProperties(filter PropertyFilter) ([]models.Property, string, error) {
+	columns := "properties.*, " + favoritesCountQuery + " AS favorites_count, " + propertyRatingColumns
+	query := s.db.Model(&models.Property{})
+	if filter.Center != nil {
+		query = query.Select(columns+", "+distanceExpression+" AS distance_km", distanceArgs(*filter.Center)...)
+	} else {
+		query = query.Select(columns)
+	}
+
+	// Step 1: Apply the filters, properties unpublished by staff are never listed
+	query = query.Where("properties.is_published = ?", true)
+	if filter.Country != "" {
+		query = query.Where("properties.country ILIKE ?", filter.Country)
+	}
+	if filter.CountryCode != "" {
+		query = query.Where("properties.country_code ILIKE ?", filter.CountryCode)
+	}
+	if filter.Category != "" {
+		query = query.Where("properties.category ILIKE ?", filter.Category)
+	}
+	if filter.MinPrice > 0 {
+		query = query.Where("properties.price_per_night >= ?", filter.MinPrice)
+	}
+	if filter.MaxPrice > 0 {
+		query = query.Where("properties.price_per_night <= ?", filter.MaxPrice)
+	}
+	if filter.MinBedrooms > 0 {
+		query = query.Where("properties.bedrooms >= ?", filter.MinBedrooms)
+	}
+	if filter.MinBathrooms > 0 {
+		query = query.Where("properties.bathrooms >= ?", filter.MinBathrooms)
+	}
+	if filter.MinGuests > 0 {
+		query = query.Where("properties.guests >= ?", filter.MinGuests)
+	}
+	if !filter.AvailableFrom.IsZero() && !filter.AvailableTo.IsZero() {
+		query = query.Where(
+			"NOT EXISTS (SELECT 1 FROM reservations WHERE reservations.property_id = properties.id AND reservations.start_date < ? AND reservations.end_date > ? AND reservations."+activeReservationCondition+")",
+			filter.AvailableTo, filter.AvailableFrom,
+		).Where(
+			"NOT EXISTS (SELECT 1 FROM blocked_dates WHERE blocked_dates.property_id = properties.id AND blocked_dates.start_date < ? AND blocked_dates.end_date > ?)",
+			filter.AvailableTo, filter.AvailableFrom,
+		)
+		// Leave out properties whose stay rules refuse the requested dates
+		nights := utils.NightsBetween(filter.AvailableFrom, filter.AvailableTo)
+		checkIn := strings.ToLower(filter.AvailableFrom.Weekday().String())
+		query = query.Where("properties.min_nights <= ? AND (properties.max_nights = 0 OR properties.max_nights >= ?)", nights, nights).
+			Where("(properties.check_in_days = '' OR properties.check_in_days IS NULL OR properties.check_in_days LIKE ?)", "%"+checkIn+"%")
+	}
+	query = applyLocationFilter(query, filter)
+
+	// Step 2: Resume after the cursor and order by the sort key
+	var cursor *propertyCursor
+	if filter.Cursor != "" {
+		var err error
+		if cursor, err = decodeCursor(filter.Cursor); err != nil {
+			return nil, "", err
+		}
+	}
+
+	query, err := applyPropertySort(query, filter.Sort, filter.Center, cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Step 3: Fetch one extra row to know whether there is a next page
+	var properties []models.Property
+	result := query.Limit(filter.Limit+1).
+		Preload("Landlord", publicUserColumns).
+		Find(&properties)
+	if result.Error != nil {
+		return nil, "", result.Error
+	}
+
+	nextCursor := ""
+	if len(properties) > filter.Limit {
+		properties = properties[:filter.Limit]
+		nextCursor = encodeCursor(cursorFor(properties[len(properties)-1], filter.Sort))
+	}
+
+	return properties, nextCursor, nil
+}
+
+func applyPropertySort(query *gorm.DB, sort string, center *GeoPoint, cursor *propertyCursor) (*gorm.DB, error) {
+	switch sort {
+	case SortDistance:
+		if center == nil {
+			return nil, fmt.Errorf("sorting by distance requires a location")
+		}
+		if cursor != nil {
+			distance, err := strconv.ParseFloat(cursor.Value, 64)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			query = query.Where("("+distanceExpression+", properties.id) > (?, ?)", append(distanceArgs(*center), distance, cursor.ID)...)
+		}
+		return query.Order("distance_km ASC").Order("properties.id ASC"), nil
+
+	case SortPriceAsc, SortPriceDesc:
+		direction, comparison := "ASC", ">"
+		if sort == SortPriceDesc {
+			direction, comparison = "DESC", "<"
+		}
+		if cursor != nil {
+			price, err := strconv.Atoi(cursor.Value)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			query = query.Where("(properties.price_per_night, properties.id) "+comparison+" (?, ?)", price, cursor.ID)
+		}
+		return query.Order("properties.price_per_night " + direction).Order("properties.id " + direction), nil
+
+	case SortMostFavorited:
+		if cursor != nil {
+			count, err := strconv.ParseInt(cursor.Value, 10, 64)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			query = query.Where("("+favoritesCountQuery+", properties.id) < (?, ?)", count, cursor.ID)
+		}
+		return query.Order("favorites_count DESC").Order("properties.id DESC"), nil
+
+	case SortNewest, "":
+		if cursor != nil {
+			createdAt, err := time.Parse(time.RFC3339Nano, cursor.Value)
+			if err != nil {
+				return nil, ErrInvalidCursor
+			}
+			query = query.Where("(properties.created_at, properties.id) < (?, ?)", createdAt, cursor.ID)
+		}
+		return query.Order("properties.created_at DESC").Order("properties.id DESC"), nil
+
+	default:
+		return nil, fmt.Errorf("unknown sort %q", sort)
+	}
+}
+
+func cursorFor(property models.Property, sort string) propertyCursor {
+	switch sort {
+	case SortDistance:
+		return propertyCursor{Value: strconv.FormatFloat(*property.DistanceKm, 'g', -1, 64), ID: property.ID}
+	case SortPriceAsc, SortPriceDesc:
+		return propertyCursor{Value: strconv.Itoa(property.PricePerNight), ID: property.ID}
+	case SortMostFavorited:
+		return propertyCursor{Value: strconv.FormatInt(property.FavoritesCount, 10), ID: property.ID}
+	default:
+		return propertyCursor{Value: property.CreatedAt.Format(time.RFC3339Nano), ID: property.ID}
+	}
+}
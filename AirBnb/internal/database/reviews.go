@@ -0,0 +1,103 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// propertyRatingColumns selects the average rating and the review count of a property
+// so that they can be scanned into models.Property.
+const propertyRatingColumns = "COALESCE((SELECT ROUND(AVG(reviews.rating)::numeric, 2) FROM reviews WHERE reviews.property_id = properties.id), 0) AS average_rating, " +
+	"(SELECT COUNT(*) FROM reviews WHERE reviews.property_id = properties.id) AS review_count"
+
+func (s *service) FindReviewById(id uint) (*models.Review, error) {
+	var review models.Review
+	result := s.db.Preload("Guest", publicUserColumns).Preload("Property").Where("id = ?", id).First(&review)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &review, nil
+}
+
+func (s *service) FindReviewByReservationId(reservationID uint) (*models.Review, error) {
+	var review models.Review
+	result := s.db.Where("reservation_id = ?", reservationID).First(&review)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &review, nil
+}
+
+func (s *service) FindPropertyReviews(propertyID uint, page int, limit int) ([]models.Review, int64, error) {
+	var reviews []models.Review
+	var total int64
+
+	query := s.db.Model(&models.Review{}).Where("property_id = ?", propertyID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	result := query.Preload("Guest", publicUserColumns).
+		Order("created_at DESC").
+		Offset(offset).Limit(limit).
+		Find(&reviews)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return reviews, total, nil
+}
+
+// CreateReview stores a review. The overall rating is the average of the category ratings.
+func (s *service) CreateReview(review Review) (*models.Review, error) {
+	newReview := &models.Review{
+		ReservationID: review.ReservationID,
+		PropertyID:    review.PropertyID,
+		GuestID:       review.GuestID,
+		Cleanliness:   review.Cleanliness,
+		Accuracy:      review.Accuracy,
+		Location:      review.Location,
+		Value:         review.Value,
+		Rating:        float64(review.Cleanliness+review.Accuracy+review.Location+review.Value) / 4,
+		Comment:       review.Comment,
+	}
+
+	result := s.db.Create(newReview)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return s.FindReviewById(newReview.ID)
+}
+
+func (s *service) ReplyToReview(id uint, reply string) (*models.Review, error) {
+	var review models.Review
+
+	result := s.db.Where("id = ?", id).First(&review)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+
+	updates := map[string]interface{}{
+		"landlord_reply": reply,
+		"replied_at":     time.Now(),
+	}
+
+	if err := s.db.Model(&review).Updates(updates).Error; err != nil {
+		return nil, err
+	}
+
+	return s.FindReviewById(review.ID)
+}
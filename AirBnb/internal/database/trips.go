@@ -0,0 +1,40 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+	"time"
+)
+
+// FindUserReservations returns a page of the reservations the user made as a guest. Upcoming trips
+// are ordered soonest first and past trips most recent first.
+func (s *service) FindUserReservations(userID uint, filter ReservationFilter, page int, limit int) ([]models.Reservation, int64, error) {
+	var reservations []models.Reservation
+	var total int64
+
+	order := "start_date DESC"
+	query := s.db.Model(&models.Reservation{}).Where("created_by_id = ?", userID)
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+	switch filter.Period {
+	case PeriodUpcoming:
+		query = query.Where("end_date >= ?", time.Now())
+		order = "start_date ASC"
+	case PeriodPast:
+		query = query.Where("end_date < ?", time.Now())
+	}
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	result := query.Preload("Property").
+		Order(order).
+		Offset(offset).Limit(limit).
+		Find(&reservations)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return reservations, total, nil
+}
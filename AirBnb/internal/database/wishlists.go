@@ -0,0 +1,162 @@
+package database
+
+import (
+	"AirBnb/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type Wishlist struct {
+	ID      uint
+	Name    string
+	OwnerID uint
+}
+
+// FindUserFavorites returns a page of the properties the user saved to their favorites.
+func (s *service) FindUserFavorites(userID uint, page int, limit int) ([]models.Property, int64, error) {
+	var properties []models.Property
+	var total int64
+
+	query := s.db.Model(&models.Property{}).
+		Joins("JOIN user_favorites ON user_favorites.property_id = properties.id").
+		Where("user_favorites.user_id = ?", userID)
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	offset := (page - 1) * limit
+	result := query.Select("properties.*, "+favoritesCountQuery+" AS favorites_count, "+propertyRatingColumns).
+		Preload("Images", func(db *gorm.DB) *gorm.DB {
+			return db.Order("position ASC")
+		}).
+		Order("properties.id DESC").
+		Offset(offset).Limit(limit).
+		Find(&properties)
+	if result.Error != nil {
+		return nil, 0, result.Error
+	}
+
+	return properties, total, nil
+}
+
+func (s *service) FindUserWishlists(userID uint) ([]models.Wishlist, error) {
+	var wishlists []models.Wishlist
+
+	result := s.db.Preload("Properties").
+		Where("owner_id = ?", userID).
+		Order("created_at DESC").
+		Find(&wishlists)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return wishlists, nil
+}
+
+func (s *service) FindWishlistById(id uint) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	result := s.db.Preload("Properties").Where("id = ?", id).First(&wishlist)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &wishlist, nil
+}
+
+func (s *service) FindWishlistByShareToken(token string) (*models.Wishlist, error) {
+	var wishlist models.Wishlist
+	result := s.db.Preload("Properties").Where("share_token = ?", token).First(&wishlist)
+	if result.Error != nil {
+		if result.Error == gorm.ErrRecordNotFound {
+			return nil, nil
+		}
+		return nil, result.Error
+	}
+	return &wishlist, nil
+}
+
+func (s *service) CreateWishlist(wishlist Wishlist) (*models.Wishlist, error) {
+	newWishlist := &models.Wishlist{
+		Name:    wishlist.Name,
+		OwnerID: wishlist.OwnerID,
+	}
+
+	result := s.db.Create(newWishlist)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return s.FindWishlistById(newWishlist.ID)
+}
+
+func (s *service) RenameWishlist(id uint, name string) (*models.Wishlist, error) {
+	result := s.db.Model(&models.Wishlist{}).Where("id = ?", id).Update("name", name)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return s.FindWishlistById(id)
+}
+
+// SetWishlistShareToken turns sharing on with the given token, or off when the token is nil.
+func (s *service) SetWishlistShareToken(id uint, token *string) (*models.Wishlist, error) {
+	result := s.db.Model(&models.Wishlist{}).Where("id = ?", id).Update("share_token", token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+
+	return s.FindWishlistById(id)
+}
+
+// AddWishlistProperty adds a property to a wishlist and to the favorites of the wishlist's owner,
+// so that every property of a wishlist is also one of the owner's favorites.
+func (s *service) AddWishlistProperty(wishlistID uint, propertyID uint) (*models.Wishlist, error) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var wishlist models.Wishlist
+		if err := tx.First(&wishlist, wishlistID).Error; err != nil {
+			return err
+		}
+
+		var property models.Property
+		if err := tx.First(&property, propertyID).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&wishlist).Association("Properties").Append(&property); err != nil {
+			return err
+		}
+
+		owner := models.User{ID: wishlist.OwnerID}
+		return tx.Model(&owner).Association("Favorites").Append(&property)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.FindWishlistById(wishlistID)
+}
+
+func (s *service) RemoveWishlistProperty(wishlistID uint, propertyID uint) (*models.Wishlist, error) {
+	wishlist := models.Wishlist{ID: wishlistID}
+	property := models.Property{ID: propertyID}
+	if err := s.db.Model(&wishlist).Association("Properties").Delete(&property); err != nil {
+		return nil, err
+	}
+
+	return s.FindWishlistById(wishlistID)
+}
+
+func (s *service) DeleteWishlist(id uint) (*models.Wishlist, error) {
+	wishlist, err := s.FindWishlistById(id)
+	if err != nil || wishlist == nil {
+		return nil, err
+	}
+
+	if err := s.db.Select("Properties").Delete(&models.Wishlist{ID: id}).Error; err != nil {
+		return nil, err
+	}
+
+	return wishlist, nil
+}
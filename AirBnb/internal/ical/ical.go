@@ -0,0 +1,199 @@
+// Package ical reads and writes the subset of iCalendar (RFC 5545) used to share the busy
+// nights of a property between booking platforms.
+package ical
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+const (
+	dateLayout     = "20060102"
+	dateTimeLayout = "20060102T150405"
+	maxLineLength  = 75
+)
+
+// Event is a busy period. Start and End are calendar dates; End is exclusive, like a check-out day.
+type Event struct {
+	UID     string
+	Summary string
+	Start   time.Time
+	End     time.Time
+}
+
+// Write writes a calendar with one all-day event per busy period. stamp is used as the
+// DTSTAMP of every event.
+func Write(w io.Writer, name string, events []Event, stamp time.Time) error {
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//AirBnb//Property Calendar//EN",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"X-WR-CALNAME:" + escapeText(name),
+	}
+	for _, event := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeText(event.UID),
+			"DTSTAMP:"+stamp.UTC().Format(dateTimeLayout)+"Z",
+			"DTSTART;VALUE=DATE:"+event.Start.Format(dateLayout),
+			"DTEND;VALUE=DATE:"+event.End.Format(dateLayout),
+			"SUMMARY:"+escapeText(event.Summary),
+			"END:VEVENT",
+		)
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	writer := bufio.NewWriter(w)
+	for _, line := range lines {
+		if _, err := writer.WriteString(foldLine(line) + "\r\n"); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// Parse reads the events of a calendar. Times are reduced to calendar dates: an event blocks every
+// night from its start date up to, but not including, its end date. Cancelled events are skipped.
+func Parse(r io.Reader) ([]Event, error) {
+	lines, err := unfoldLines(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var events []Event
+	var current map[string]string
+	for number, line := range lines {
+		name, value, ok := splitProperty(line)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			current = make(map[string]string)
+		case name == "END" && value == "VEVENT":
+			if current == nil {
+				return nil, fmt.Errorf("line %d: END:VEVENT without BEGIN:VEVENT", number+1)
+			}
+			if strings.EqualFold(current["STATUS"], "CANCELLED") {
+				current = nil
+				continue
+			}
+			event, err := eventFromProperties(current)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", number+1, err)
+			}
+			events = append(events, event)
+			current = nil
+		case current != nil:
+			// Keep the first occurrence of a property, parameters are dropped
+			if _, exists := current[name]; !exists {
+				current[name] = value
+			}
+		}
+	}
+
+	return events, nil
+}
+
+func eventFromProperties(properties map[string]string) (Event, error) {
+	start, err := parseDate(properties["DTSTART"])
+	if err != nil {
+		return Event{}, fmt.Errorf("invalid DTSTART: %w", err)
+	}
+
+	end := start.AddDate(0, 0, 1)
+	if value, ok := properties["DTEND"]; ok {
+		if end, err = parseDate(value); err != nil {
+			return Event{}, fmt.Errorf("invalid DTEND: %w", err)
+		}
+	}
+	// An event that starts and ends on the same day still blocks that night
+	if !end.After(start) {
+		end = start.AddDate(0, 0, 1)
+	}
+
+	return Event{
+		UID:     unescapeText(properties["UID"]),
+		Summary: unescapeText(properties["SUMMARY"]),
+		Start:   start,
+		End:     end,
+	}, nil
+}
+
+// parseDate accepts DATE and DATE-TIME values and keeps only the date. Time zones are ignored
+// because booking calendars describe whole nights.
+func parseDate(value string) (time.Time, error) {
+	if len(value) < len(dateLayout) {
+		return time.Time{}, fmt.Errorf("%q is not a date", value)
+	}
+	return time.Parse(dateLayout, value[:len(dateLayout)])
+}
+
+// splitProperty splits a content line into its upper-cased name and its value.
+func splitProperty(line string) (string, string, bool) {
+	colon := strings.Index(line, ":")
+	if colon < 0 {
+		return "", "", false
+	}
+	name := line[:colon]
+	if semicolon := strings.Index(name, ";"); semicolon >= 0 {
+		name = name[:semicolon]
+	}
+	return strings.ToUpper(strings.TrimSpace(name)), strings.TrimSpace(line[colon+1:]), true
+}
+
+// unfoldLines joins continuation lines, which start with a space or a tab, to the line before.
+func unfoldLines(r io.Reader) ([]string, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines, scanner.Err()
+}
+
+// foldLine splits lines longer than 75 octets, without breaking UTF-8 characters.
+func foldLine(line string) string {
+	if len(line) <= maxLineLength {
+		return line
+	}
+
+	var folded strings.Builder
+	length := 0
+	for _, r := range line {
+		size := len(string(r))
+		if length+size > maxLineLength {
+			folded.WriteString("\r\n ")
+			length = 1
+		}
+		folded.WriteRune(r)
+		length += size
+	}
+	return folded.String()
+}
+
+var textEscaper = strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+
+var textUnescaper = strings.NewReplacer(`\\`, `\`, `\;`, ";", `\,`, ",", `\n`, "\n", `\N`, "\n")
+
+func escapeText(value string) string {
+	return textEscaper.Replace(value)
+}
+
+func unescapeText(value string) string {
+	return textUnescaper.Replace(value)
+}
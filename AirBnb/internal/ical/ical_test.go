@@ -0,0 +1,105 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestWriteAndParseRoundTrip(t *testing.T) {
+	events := []Event{
+		{UID: "reservation-1@airbnb", Summary: "Reserved", Start: date(2025, 3, 10), End: date(2025, 3, 13)},
+		{UID: "reservation-2@airbnb", Summary: "Reserved; late check-in", Start: date(2025, 3, 20), End: date(2025, 3, 21)},
+	}
+
+	var buffer bytes.Buffer
+	if err := Write(&buffer, "Beach house", events, date(2025, 1, 1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(buffer.String(), "DTSTART;VALUE=DATE:20250310\r\n") {
+		t.Fatalf("expected an all-day start date, got:\n%s", buffer.String())
+	}
+
+	parsed, err := Parse(&buffer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(parsed) != len(events) {
+		t.Fatalf("expected %d events, got %d", len(events), len(parsed))
+	}
+	for i, event := range parsed {
+		if event != events[i] {
+			t.Errorf("event %d: expected %+v, got %+v", i, events[i], event)
+		}
+	}
+}
+
+func TestParseExternalCalendar(t *testing.T) {
+	calendar := "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:abc\r\n" +
+		"DTSTART;TZID=Europe/Paris:20250401T150000\r\n" +
+		"DTEND;TZID=Europe/Paris:20250404T110000\r\n" +
+		"SUMMARY:Booked on another\r\n" +
+		"  platform\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:single-day\r\n" +
+		"DTSTART;VALUE=DATE:20250410\r\n" +
+		"END:VEVENT\r\n" +
+		"BEGIN:VEVENT\r\n" +
+		"UID:cancelled\r\n" +
+		"STATUS:CANCELLED\r\n" +
+		"DTSTART;VALUE=DATE:20250420\r\n" +
+		"END:VEVENT\r\n" +
+		"END:VCALENDAR\r\n"
+
+	events, err := Parse(strings.NewReader(calendar))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+
+	if !events[0].Start.Equal(date(2025, 4, 1)) || !events[0].End.Equal(date(2025, 4, 4)) {
+		t.Errorf("expected 1 to 4 April, got %v to %v", events[0].Start, events[0].End)
+	}
+	if events[0].Summary != "Booked on another platform" {
+		t.Errorf("expected the folded summary to be joined, got %q", events[0].Summary)
+	}
+	if !events[1].End.Equal(date(2025, 4, 11)) {
+		t.Errorf("expected an event without DTEND to block one night, got %v", events[1].End)
+	}
+
+	if _, err := Parse(strings.NewReader("BEGIN:VEVENT\r\nDTSTART:soon\r\nEND:VEVENT\r\n")); err == nil {
+		t.Fatal("expected an error for an invalid date")
+	}
+}
+
+func TestFoldLongLines(t *testing.T) {
+	var buffer bytes.Buffer
+	events := []Event{{UID: "1", Summary: strings.Repeat("é", 60), Start: date(2025, 1, 1), End: date(2025, 1, 2)}}
+	if err := Write(&buffer, "Calendar", events, date(2025, 1, 1)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, line := range strings.Split(buffer.String(), "\r\n") {
+		if len(line) > maxLineLength {
+			t.Fatalf("line is longer than %d octets: %q", maxLineLength, line)
+		}
+	}
+
+	parsed, err := Parse(&buffer)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if parsed[0].Summary != events[0].Summary {
+		t.Fatalf("expected the summary to survive folding, got %q", parsed[0].Summary)
+	}
+}
@@ -0,0 +1,58 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+)
+
+// LogBackend writes the recipient and subject of every message to the log. It is used in
+// development when no SMTP server is configured.
+type LogBackend struct{}
+
+func (LogBackend) Send(ctx context.Context, message Message) error {
+	log.Printf("mailer: %q to %s", message.Subject, message.To)
+	return nil
+}
+
+// CaptureBackend keeps sent messages in memory so tests can assert on them. Set Failures to
+// make the next sends fail.
+type CaptureBackend struct {
+	mu       sync.Mutex
+	Failures int
+	attempts int
+	messages []Message
+}
+
+func NewCaptureBackend() *CaptureBackend {
+	return &CaptureBackend{}
+}
+
+func (b *CaptureBackend) Send(ctx context.Context, message Message) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.attempts++
+	if b.Failures > 0 {
+		b.Failures--
+		return errors.New("capture backend: simulated failure")
+	}
+
+	b.messages = append(b.messages, message)
+	return nil
+}
+
+// Messages returns a copy of the messages sent so far.
+func (b *CaptureBackend) Messages() []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return append([]Message(nil), b.messages...)
+}
+
+// Attempts counts every send, including the failed ones.
+func (b *CaptureBackend) Attempts() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.attempts
+}
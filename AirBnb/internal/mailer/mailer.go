@@ -0,0 +1,161 @@
+// Package mailer sends the transactional emails of the platform. Messages are queued and
+// delivered by background workers that retry failed sends.
+package mailer
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrQueueFull is returned when a message is enqueued faster than the workers deliver.
+	ErrQueueFull = errors.New("mail queue is full")
+	// ErrMailerClosed is returned when a message is enqueued after Close.
+	ErrMailerClosed = errors.New("mailer is closed")
+)
+
+// Message is a rendered email. Template names the notification it was rendered from.
+type Message struct {
+	To       string
+	Subject  string
+	HTML     string
+	Template string
+}
+
+// Backend delivers a single message.
+type Backend interface {
+	Send(ctx context.Context, message Message) error
+}
+
+type Config struct {
+	QueueSize   int
+	Workers     int
+	MaxAttempts int
+	// RetryDelay is the wait before the second attempt, it doubles with every further attempt
+	RetryDelay  time.Duration
+	SendTimeout time.Duration
+	// BaseURL is prefixed to the links in emails
+	BaseURL string
+}
+
+// ConfigFromEnv reads APP_URL, the base of the links in emails, and MAIL_MAX_ATTEMPTS. Other settings use defaults.
+func ConfigFromEnv() Config {
+	config := Config{BaseURL: os.Getenv("APP_URL")}
+	if attempts, err := strconv.Atoi(os.Getenv("MAIL_MAX_ATTEMPTS")); err == nil {
+		config.MaxAttempts = attempts
+	}
+	return config
+}
+
+func (c Config) withDefaults() Config {
+	if c.QueueSize <= 0 {
+		c.QueueSize = 1000
+	}
+	if c.Workers <= 0 {
+		c.Workers = 2
+	}
+	if c.MaxAttempts <= 0 {
+		c.MaxAttempts = 5
+	}
+	if c.RetryDelay <= 0 {
+		c.RetryDelay = 2 * time.Second
+	}
+	if c.SendTimeout <= 0 {
+		c.SendTimeout = 30 * time.Second
+	}
+	if c.BaseURL == "" {
+		c.BaseURL = "http://localhost:8090"
+	}
+	return c
+}
+
+type Mailer struct {
+	backend Backend
+	config  Config
+	queue   chan Message
+
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// New starts the delivery workers of a mailer. Call Close to deliver the queued messages and stop them.
+func New(backend Backend, config Config) *Mailer {
+	config = config.withDefaults()
+	m := &Mailer{
+		backend: backend,
+		config:  config,
+		queue:   make(chan Message, config.QueueSize),
+	}
+
+	m.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go m.work()
+	}
+
+	return m
+}
+
+// Enqueue queues a message for delivery without waiting for it to be sent.
+func (m *Mailer) Enqueue(message Message) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.closed {
+		return ErrMailerClosed
+	}
+
+	select {
+	case m.queue <- message:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting messages and waits until the queued ones are delivered or given up on.
+func (m *Mailer) Close() {
+	m.mu.Lock()
+	if !m.closed {
+		m.closed = true
+		close(m.queue)
+	}
+	m.mu.Unlock()
+
+	m.wg.Wait()
+}
+
+func (m *Mailer) work() {
+	defer m.wg.Done()
+	for message := range m.queue {
+		if err := m.deliver(message); err != nil {
+			log.Printf("mailer: giving up on %q to %s: %v", message.Subject, message.To, err)
+		}
+	}
+}
+
+// deliver sends a message, retrying with an exponential backoff.
+func (m *Mailer) deliver(message Message) error {
+	delay := m.config.RetryDelay
+
+	var err error
+	for attempt := 1; attempt <= m.config.MaxAttempts; attempt++ {
+		ctx, cancel := context.WithTimeout(context.Background(), m.config.SendTimeout)
+		err = m.backend.Send(ctx, message)
+		cancel()
+		if err == nil {
+			return nil
+		}
+
+		if attempt < m.config.MaxAttempts {
+			time.Sleep(delay)
+			delay *= 2
+		}
+	}
+	return err
+}
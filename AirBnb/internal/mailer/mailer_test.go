@@ -0,0 +1,165 @@
+package mailer
+
+import (
+	"AirBnb/internal/models"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestMailer(backend Backend) *Mailer {
+	return New(backend, Config{Workers: 1, MaxAttempts: 3, RetryDelay: time.Millisecond, BaseURL: "https://example.com"})
+}
+
+func testReservation() models.Reservation {
+	return models.Reservation{
+		ID:        7,
+		StartDate: time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 6, 14, 0, 0, 0, 0, time.UTC),
+		Guests:    2,
+		Status:    models.ReservationRequested,
+		CreatedBy: models.User{ID: 1, Name: "Guest", Email: "guest@example.com"},
+		Property:  models.Property{Title: "Beach & Sun house"},
+	}
+}
+
+func TestMailerRetriesFailedSends(t *testing.T) {
+	backend := NewCaptureBackend()
+	backend.Failures = 2
+
+	mailer := newTestMailer(backend)
+	if err := mailer.Enqueue(Message{To: "guest@example.com", Subject: "Hello"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mailer.Close()
+
+	if len(backend.Messages()) != 1 {
+		t.Fatalf("expected the message to be sent on the third attempt, got %d messages", len(backend.Messages()))
+	}
+	if backend.Attempts() != 3 {
+		t.Errorf("expected 3 attempts, got %d", backend.Attempts())
+	}
+}
+
+func TestMailerGivesUpAfterMaxAttempts(t *testing.T) {
+	backend := NewCaptureBackend()
+	backend.Failures = 10
+
+	mailer := newTestMailer(backend)
+	mailer.Enqueue(Message{To: "guest@example.com", Subject: "Hello"})
+	mailer.Close()
+
+	if len(backend.Messages()) != 0 || backend.Attempts() != 3 {
+		t.Fatalf("expected 3 failed attempts, got %d attempts and %d messages", backend.Attempts(), len(backend.Messages()))
+	}
+
+	if err := mailer.Enqueue(Message{}); !errors.Is(err, ErrMailerClosed) {
+		t.Fatalf("expected ErrMailerClosed after Close, got %v", err)
+	}
+}
+
+func TestBookingNotifications(t *testing.T) {
+	backend := NewCaptureBackend()
+	mailer := newTestMailer(backend)
+
+	reservation := testReservation()
+	host := models.User{ID: 2, Name: "Host", Email: "host@example.com"}
+	mailer.NotifyBookingRequested(host, reservation)
+	mailer.NotifyReviewRequest(reservation)
+	mailer.NotifyNewMessage(host, reservation.CreatedBy, 3, "<b>Is parking included?</b>")
+	// Users without an email address are skipped
+	mailer.NotifyBookingConfirmed(models.Reservation{})
+	mailer.Close()
+
+	messages := backend.Messages()
+	if len(messages) != 3 {
+		t.Fatalf("expected 3 messages, got %d", len(messages))
+	}
+
+	requested := messages[0]
+	if requested.To != "host@example.com" || requested.Template != TemplateBookingRequested {
+		t.Errorf("expected the booking request to go to the host, got %+v", requested)
+	}
+	if requested.Subject != "New booking for Beach & Sun house" {
+		t.Errorf("expected the subject not to be HTML escaped, got %q", requested.Subject)
+	}
+	if !strings.Contains(requested.HTML, "Beach &amp; Sun house") || !strings.Contains(requested.HTML, "Tue, Jun 10 2025") {
+		t.Errorf("expected the body to describe the stay, got:\n%s", requested.HTML)
+	}
+
+	review := messages[1]
+	if review.To != "guest@example.com" || !strings.Contains(review.HTML, "https://example.com/api/reservations/7/review") {
+		t.Errorf("expected the review request to link to the reservation, got %+v", review)
+	}
+
+	message := messages[2]
+	if strings.Contains(message.HTML, "<b>") || !strings.Contains(message.HTML, "&lt;b&gt;Is parking included?") {
+		t.Errorf("expected the message body to be escaped, got:\n%s", message.HTML)
+	}
+}
+
+type fakeReminderStore struct {
+	reservations []models.Reservation
+	day          time.Time
+	sent         []uint
+}
+
+func (s *fakeReminderStore) FindPendingCheckInReminders(day time.Time) ([]models.Reservation, error) {
+	s.day = day
+	return s.reservations, nil
+}
+
+func (s *fakeReminderStore) MarkCheckInReminderSent(reservationID uint, sentAt time.Time) error {
+	s.sent = append(s.sent, reservationID)
+	return nil
+}
+
+func TestSendCheckInReminders(t *testing.T) {
+	backend := NewCaptureBackend()
+	mailer := newTestMailer(backend)
+	store := &fakeReminderStore{reservations: []models.Reservation{testReservation()}}
+
+	if err := mailer.SendCheckInReminders(store, time.Date(2025, 6, 9, 18, 30, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	mailer.Close()
+
+	if !store.day.Equal(time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected reminders for stays starting on 10 June, got %v", store.day)
+	}
+	if len(store.sent) != 1 || store.sent[0] != 7 {
+		t.Errorf("expected reservation 7 to be marked as reminded, got %v", store.sent)
+	}
+
+	messages := backend.Messages()
+	if len(messages) != 1 || messages[0].Template != TemplateCheckInReminder || messages[0].To != "guest@example.com" {
+		t.Fatalf("expected one check-in reminder to the guest, got %+v", messages)
+	}
+}
+
+func TestSendCheckInRemindersKeepsUnqueuedReminders(t *testing.T) {
+	mailer := newTestMailer(NewCaptureBackend())
+	mailer.Close()
+	store := &fakeReminderStore{reservations: []models.Reservation{testReservation()}}
+
+	err := mailer.SendCheckInReminders(store, time.Date(2025, 6, 9, 18, 30, 0, 0, time.UTC))
+	if !errors.Is(err, ErrMailerClosed) {
+		t.Fatalf("expected ErrMailerClosed, got %v", err)
+	}
+	if len(store.sent) != 0 {
+		t.Errorf("expected no reservation to be marked as reminded, got %v", store.sent)
+	}
+}
+
+func TestBuildMIMEStripsHeaderInjection(t *testing.T) {
+	raw := string(buildMIME("from@example.com", Message{
+		To:      "guest@example.com\r\nBcc: attacker@example.com",
+		Subject: "Hello",
+		HTML:    "<p>Hi</p>",
+	}, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)))
+
+	if strings.Contains(raw, "\r\nBcc:") {
+		t.Fatalf("expected line breaks to be stripped from headers, got:\n%s", raw)
+	}
+}
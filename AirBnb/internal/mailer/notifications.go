@@ -0,0 +1,162 @@
+package mailer
+
+import (
+	"AirBnb/internal/models"
+	"bytes"
+	"embed"
+	"fmt"
+	"html/template"
+	"log"
+	"strings"
+	texttemplate "text/template"
+)
+
+// Notification templates, each one is templates/<name>.html rendered inside templates/layout.html.
+const (
+	TemplateVerifyEmail      = "verify_email"
+	TemplatePasswordReset    = "password_reset"
+	TemplateBookingRequested = "booking_requested"
+	TemplateBookingConfirmed = "booking_confirmed"
+	TemplateBookingCancelled = "booking_cancelled"
+	TemplateCheckInReminder  = "check_in_reminder"
+	TemplateNewMessage       = "new_message"
+	TemplateReviewRequest    = "review_request"
+)
+
+// subjects are text templates, the email bodies are HTML templates.
+var subjects = map[string]string{
+	TemplateVerifyEmail:      "Verify your email address",
+	TemplatePasswordReset:    "Reset your password",
+	TemplateBookingRequested: "New booking for {{.Reservation.Property.Title}}",
+	TemplateBookingConfirmed: "Your stay at {{.Reservation.Property.Title}} is confirmed",
+	TemplateBookingCancelled: "Your booking at {{.Reservation.Property.Title}} was {{.Reservation.Status}}",
+	TemplateCheckInReminder:  "Your stay at {{.Reservation.Property.Title}} starts tomorrow",
+	TemplateNewMessage:       "New message from {{.Sender.Name}}",
+	TemplateReviewRequest:    "How was your stay at {{.Reservation.Property.Title}}?",
+}
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+type emailTemplate struct {
+	subject *texttemplate.Template
+	body    *template.Template
+}
+
+var templates = parseTemplates()
+
+func parseTemplates() map[string]emailTemplate {
+	parsed := make(map[string]emailTemplate, len(subjects))
+	for name, subject := range subjects {
+		parsed[name] = emailTemplate{
+			subject: texttemplate.Must(texttemplate.New(name).Parse(subject)),
+			body:    template.Must(template.ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html")),
+		}
+	}
+	return parsed
+}
+
+// templateData is what every template renders. Only the fields a notification needs are set.
+type templateData struct {
+	Recipient   models.User
+	Reservation models.Reservation
+	Sender      models.User
+	Message     string
+	Link        string
+}
+
+// Render renders a notification for its recipient.
+func Render(name string, data templateData) (Message, error) {
+	tmpl, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject strings.Builder
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return Message{}, err
+	}
+
+	var body bytes.Buffer
+	if err := tmpl.body.ExecuteTemplate(&body, "layout", data); err != nil {
+		return Message{}, err
+	}
+
+	return Message{
+		To:       data.Recipient.Email,
+		Subject:  subject.String(),
+		HTML:     body.String(),
+		Template: name,
+	}, nil
+}
+
+// notify renders and queues a notification. Emails are best effort, so failures are logged and
+// most callers ignore the returned error instead of failing the request that caused them.
+func (m *Mailer) notify(name string, data templateData) error {
+	if data.Recipient.Email == "" {
+		return nil
+	}
+
+	message, err := Render(name, data)
+	if err != nil {
+		log.Printf("mailer: failed to render %s: %v", name, err)
+		return err
+	}
+
+	if err := m.Enqueue(message); err != nil {
+		log.Printf("mailer: failed to queue %s to %s: %v", name, message.To, err)
+		return err
+	}
+	return nil
+}
+
+func (m *Mailer) link(format string, args ...interface{}) string {
+	return strings.TrimRight(m.config.BaseURL, "/") + fmt.Sprintf(format, args...)
+}
+
+func (m *Mailer) SendVerificationEmail(user models.User) {
+	m.notify(TemplateVerifyEmail, templateData{Recipient: user, Link: m.link("/auth/verify/%s", user.Token)})
+}
+
+func (m *Mailer) SendPasswordReset(user models.User) {
+	m.notify(TemplatePasswordReset, templateData{Recipient: user, Link: m.link("/auth/reset-password/%s", user.Token)})
+}
+
+// NotifyBookingRequested tells the host about a new reservation, asking them to accept or decline it
+// unless it was booked instantly.
+func (m *Mailer) NotifyBookingRequested(host models.User, reservation models.Reservation) {
+	m.notify(TemplateBookingRequested, templateData{Recipient: host, Reservation: reservation, Link: m.link("/api/host/reservations")})
+}
+
+func (m *Mailer) NotifyBookingConfirmed(reservation models.Reservation) {
+	m.notify(TemplateBookingConfirmed, templateData{Recipient: reservation.CreatedBy, Reservation: reservation, Link: m.link("/api/me/reservations")})
+}
+
+// NotifyBookingCancelled tells the other party that a reservation was cancelled or declined.
+func (m *Mailer) NotifyBookingCancelled(recipient models.User, reservation models.Reservation) {
+	m.notify(TemplateBookingCancelled, templateData{Recipient: recipient, Reservation: reservation})
+}
+
+// NotifyCheckInReminder returns the error of queueing the reminder, so a reservation is only
+// marked as reminded once its reminder is queued.
+func (m *Mailer) NotifyCheckInReminder(reservation models.Reservation) error {
+	return m.notify(TemplateCheckInReminder, templateData{Recipient: reservation.CreatedBy, Reservation: reservation})
+}
+
+func (m *Mailer) NotifyNewMessage(recipient models.User, sender models.User, conversationID uint, body string) {
+	m.notify(TemplateNewMessage, templateData{
+		Recipient: recipient,
+		Sender:    sender,
+		Message:   body,
+		Link:      m.link("/api/conversations/%d/messages", conversationID),
+	})
+}
+
+// NotifyReviewRequest invites the guest to review a stay that just ended.
+func (m *Mailer) NotifyReviewRequest(reservation models.Reservation) {
+	m.notify(TemplateReviewRequest, templateData{
+		Recipient:   reservation.CreatedBy,
+		Reservation: reservation,
+		Link:        m.link("/api/reservations/%d/review", reservation.ID),
+	})
+}
@@ -0,0 +1,59 @@
+package mailer
+
+import (
+	"AirBnb/internal/models"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ReminderStore is the part of database.Service the check-in reminders need.
+type ReminderStore interface {
+	FindPendingCheckInReminders(day time.Time) ([]models.Reservation, error)
+	MarkCheckInReminderSent(reservationID uint, sentAt time.Time) error
+}
+
+// SendCheckInReminders queues a reminder for every confirmed stay that starts the day after now.
+// A reservation is marked once its reminder is queued, so it is reminded at most once. Reminders
+// that cannot be queued are left unmarked for the next run, the returned error joins the failures.
+func (m *Mailer) SendCheckInReminders(store ReminderStore, now time.Time) error {
+	year, month, day := now.UTC().AddDate(0, 0, 1).Date()
+	tomorrow := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+
+	reservations, err := store.FindPendingCheckInReminders(tomorrow)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, reservation := range reservations {
+		if err := m.NotifyCheckInReminder(reservation); err != nil {
+			errs = append(errs, fmt.Errorf("reservation %d: %w", reservation.ID, err))
+			continue
+		}
+		if err := store.MarkCheckInReminderSent(reservation.ID, now); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// RunCheckInReminders sends the check-in reminders once per interval until ctx is cancelled.
+func (m *Mailer) RunCheckInReminders(ctx context.Context, store ReminderStore, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.SendCheckInReminders(store, time.Now()); err != nil {
+			log.Printf("mailer: check-in reminders: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
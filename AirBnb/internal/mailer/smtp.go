@@ -0,0 +1,80 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"mime"
+	"net/smtp"
+	"os"
+	"strings"
+	"time"
+)
+
+// SMTPBackend sends messages through an SMTP server. Username may be empty for servers
+// that do not require authentication.
+type SMTPBackend struct {
+	Host     string
+	Port     string
+	Username string
+	Password string
+	From     string
+}
+
+// BackendFromEnv returns an SMTP backend configured by SMTP_HOST, SMTP_PORT, SMTP_USERNAME,
+// SMTP_PASSWORD and MAIL_FROM. Without SMTP_HOST, messages are only written to the log.
+func BackendFromEnv() Backend {
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Println("mailer: SMTP_HOST is not set, emails are written to the log")
+		return LogBackend{}
+	}
+
+	backend := &SMTPBackend{
+		Host:     host,
+		Port:     os.Getenv("SMTP_PORT"),
+		Username: os.Getenv("SMTP_USERNAME"),
+		Password: os.Getenv("SMTP_PASSWORD"),
+		From:     os.Getenv("MAIL_FROM"),
+	}
+	if backend.Port == "" {
+		backend.Port = "587"
+	}
+	if backend.From == "" {
+		backend.From = "no-reply@localhost"
+	}
+	return backend
+}
+
+// Send delivers the message. net/smtp does not take a context, so ctx is only checked before sending.
+func (b *SMTPBackend) Send(ctx context.Context, message Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	var auth smtp.Auth
+	if b.Username != "" {
+		auth = smtp.PlainAuth("", b.Username, b.Password, b.Host)
+	}
+
+	return smtp.SendMail(b.Host+":"+b.Port, auth, b.From, []string{message.To}, buildMIME(b.From, message, time.Now()))
+}
+
+// buildMIME formats an HTML message. Header values are stripped of line breaks so they
+// cannot inject other headers.
+func buildMIME(from string, message Message, date time.Time) []byte {
+	var builder strings.Builder
+	fmt.Fprintf(&builder, "From: %s\r\n", headerValue(from))
+	fmt.Fprintf(&builder, "To: %s\r\n", headerValue(message.To))
+	fmt.Fprintf(&builder, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", headerValue(message.Subject)))
+	fmt.Fprintf(&builder, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/html; charset=UTF-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(message.HTML)
+	return []byte(builder.String())
+}
+
+func headerValue(value string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(value)
+}
@@ -0,0 +1,23 @@
+package middleware
+
+import (
+	"AirBnb/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// StaffRequired only lets staff members through. It must run after AuthRequired, which
+// refreshes the staff flag of the claims from the database.
+func StaffRequired() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Extract the user claims from the context
+		claims := c.Locals("user").(*utils.Claims)
+
+		if !claims.IsStaff {
+			return utils.SendErrorResponse(c, fiber.StatusForbidden, "Staff access required", nil)
+		}
+
+		// If the user is a staff member, proceed to the next handler
+		return c.Next()
+	}
+}
@@ -1,20 +1,19 @@
 package middleware
 
 import (
+	"AirBnb/internal/database"
 	"AirBnb/internal/utils"
-	"fmt"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func AuthRequired() fiber.Handler {
+// AuthRequired validates the bearer token and checks the account against the database, so that
+// suspending a user or changing their staff flag takes effect without waiting for the token to expire.
+func AuthRequired(s database.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 
-		// Add debug logging
-		fmt.Printf("Auth Header: %s\n", authHeader)
-
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
 				"error":  "Missing Authorization header",
@@ -38,6 +37,30 @@ func AuthRequired() fiber.Handler {
 			})
 		}
 
+		user, dbErr := s.FindUserById(uint(claims.UserID))
+		if dbErr != nil {
+			return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+				"error":  "Failed to fetch user",
+				"status": fiber.StatusInternalServerError,
+			})
+		}
+
+		if user == nil {
+			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
+				"error":  "Invalid token",
+				"status": fiber.StatusUnauthorized,
+			})
+		}
+
+		if !user.IsActive {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error":  "This account has been deactivated",
+				"status": fiber.StatusForbidden,
+			})
+		}
+
+		claims.IsActive = user.IsActive
+		claims.IsStaff = user.IsStaff
 		c.Locals("user", claims)
 		return c.Next()
 	}
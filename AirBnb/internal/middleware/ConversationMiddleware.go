@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ConversationParticipant(s database.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Extract the conversation ID from the request
+		conversationID, err := c.ParamsInt("id")
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid conversation ID", nil)
+		}
+
+		// Extract the user ID from the JWT claims
+		claims := c.Locals("user").(*utils.Claims)
+		userID := claims.UserID
+
+		// Verify that the user takes part in the conversation
+		isParticipant, err := s.IsConversationParticipant(uint(conversationID), uint(userID))
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch conversation", err.Error())
+		}
+
+		if !isParticipant {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Conversation not found", nil)
+		}
+
+		// If the user is a participant, proceed to the next handler
+		return c.Next()
+	}
+}
@@ -39,3 +39,31 @@ func ReservationOwner(s database.Service) fiber.Handler {
 		return c.Next()
 	}
 }
+
+func ReservationLandlord(s database.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Extract the reservation ID from the request
+		reservationID, err := c.ParamsInt("id")
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid reservation ID", nil)
+		}
+
+		// Fetch the reservation together with its property
+		reservation, err := s.FindReservationById(uint(reservationID))
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Reservation not found", nil)
+		}
+
+		// Extract the user ID from the JWT claims
+		claims := c.Locals("user").(*utils.Claims)
+		userID := claims.UserID
+
+		// Verify that the user owns the reserved property
+		if reservation.Property.LandlordID != uint(userID) {
+			return utils.SendErrorResponse(c, fiber.StatusForbidden, "You are not the owner of this property", nil)
+		}
+
+		// If the user is the landlord, proceed to the next handler
+		return c.Next()
+	}
+}
@@ -0,0 +1,36 @@
+package middleware
+
+import (
+	"AirBnb/internal/database"
+	"AirBnb/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func WishlistOwner(s database.Service) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		// Extract the wishlist ID from the request
+		wishlistID, err := c.ParamsInt("id")
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid wishlist ID", nil)
+		}
+
+		// Fetch the wishlist from the database
+		wishlist, err := s.FindWishlistById(uint(wishlistID))
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to fetch wishlist", err.Error())
+		}
+
+		// Extract the user ID from the JWT claims
+		claims := c.Locals("user").(*utils.Claims)
+		userID := claims.UserID
+
+		// Other users' wishlists are only reachable through their share link
+		if wishlist == nil || wishlist.OwnerID != uint(userID) {
+			return utils.SendErrorResponse(c, fiber.StatusNotFound, "Wishlist not found", nil)
+		}
+
+		// If the user is the owner, proceed to the next handler
+		return c.Next()
+	}
+}
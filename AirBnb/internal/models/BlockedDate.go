@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// BlockedDate makes the nights in [StartDate, EndDate) of a property unavailable for booking.
+// Blocks imported from an external calendar reference it and are replaced on every sync.
+type BlockedDate struct {
+	ID                 uint      `gorm:"primaryKey;autoIncrement"`
+	PropertyID         uint      `gorm:"index;not null"`
+	ExternalCalendarID *uint     `gorm:"index"`
+	StartDate          time.Time `gorm:"not null"`
+	EndDate            time.Time `gorm:"not null"`
+	Summary            string
+	// UID is the identifier of the imported event
+	UID       string
+	CreatedAt time.Time
+}
@@ -6,6 +6,7 @@ type ConversationMessage struct {
 	ID             uint   `gorm:"primaryKey;autoIncrement"`
 	Body           string `gorm:"type:text"`
 	CreatedAt      time.Time
+	ReadAt         *time.Time
 	ConversationID uint
 	CreatedByID    uint
 	SentToID       uint
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// ExternalCalendar is an iCal feed of another booking platform registered by a host. Its events
+// are imported as blocked dates of the property by the calendar sync job.
+type ExternalCalendar struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	PropertyID   uint   `gorm:"index;not null"`
+	Name         string `gorm:"not null"`
+	URL          string `gorm:"not null"`
+	LastSyncedAt *time.Time
+	// LastSyncError is the error of the last failed sync, cleared by the next successful one
+	LastSyncError string `gorm:"type:text"`
+	CreatedAt     time.Time
+	BlockedDates  []BlockedDate `gorm:"foreignKey:ExternalCalendarID" json:"-"`
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+const (
+	PaymentAuthorized        = "authorized"
+	PaymentCaptured          = "captured"
+	PaymentVoided            = "voided"
+	PaymentPartiallyRefunded = "partially_refunded"
+	PaymentRefunded          = "refunded"
+)
+
+// Payment is the guest's payment of a reservation at the payment provider. Amounts are in cents.
+type Payment struct {
+	ID                uint   `gorm:"primaryKey;autoIncrement"`
+	ReservationID     uint   `gorm:"uniqueIndex;not null"`
+	Provider          string `gorm:"not null;size:50"`
+	ProviderPaymentID string `gorm:"not null;size:255"`
+	Status            string `gorm:"not null;size:30"`
+	Amount            int64  `gorm:"not null"`
+	CapturedAmount    int64
+	RefundedAmount    int64
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// LedgerTransaction groups balanced ledger entries of one money movement of a reservation.
+type LedgerTransaction struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	ReservationID uint   `gorm:"index;not null"`
+	Kind          string `gorm:"not null;size:30"`
+	Description   string
+	CreatedAt     time.Time
+	Entries       []LedgerEntry `gorm:"foreignKey:TransactionID"`
+}
+
+// LedgerEntry is one side of a ledger transaction. Amounts are in cents.
+type LedgerEntry struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	TransactionID uint   `gorm:"index;not null"`
+	Account       string `gorm:"index;not null;size:50"`
+	Debit         int64  `gorm:"not null;default:0"`
+	Credit        int64  `gorm:"not null;default:0"`
+}
@@ -12,11 +12,45 @@ type Property struct {
 	Guests        int
 	Country       string
 	CountryCode   string
+	Address       string
+	City          string   `gorm:"index"`
+	Latitude      *float64 `gorm:"index:idx_properties_location"`
+	Longitude     *float64 `gorm:"index:idx_properties_location"`
 	Category      string
 	Image         string
-	CreatedAt     time.Time
-	LandlordID    uint
-	Landlord      User          `gorm:"foreignKey:LandlordID"`
-	FavoritedBy   []User        `gorm:"many2many:user_favorites;"`
-	Reservations  []Reservation `gorm:"foreignKey:PropertyID"`
+	InstantBook   bool `gorm:"default:false"`
+	// Unpublished properties are hidden from guests by staff moderation
+	IsPublished bool `gorm:"default:true"`
+	// Pricing rules, amounts are whole currency units and discounts are percentages
+	WeekendPrice    int
+	CleaningFee     int
+	BaseGuests      int
+	ExtraGuestFee   int
+	WeeklyDiscount  int
+	MonthlyDiscount int
+	// Stay rules, see booking.Rules. A MaxNights of 0 means no limit and CheckInDays holds
+	// comma separated weekday names, empty when guests may check in on any day.
+	MinNights   int `gorm:"not null;default:1"`
+	MaxNights   int
+	CheckInDays string
+	// CancellationPolicy decides the refund of guest cancellations, see payments.RefundAmount
+	CancellationPolicy string `gorm:"not null;default:flexible"`
+	CreatedAt          time.Time
+	LandlordID         uint
+	// FavoritesCount, AverageRating, ReviewCount and DistanceKm are computed by queries and
+	// are not stored in the properties table. DistanceKm is only set by location searches.
+	FavoritesCount    int64              `gorm:"->;-:migration" json:"favorites_count"`
+	AverageRating     float64            `gorm:"->;-:migration" json:"average_rating"`
+	ReviewCount       int64              `gorm:"->;-:migration" json:"review_count"`
+	DistanceKm        *float64           `gorm:"->;-:migration" json:"distance_km,omitempty"`
+	Landlord          User               `gorm:"foreignKey:LandlordID"`
+	FavoritedBy       []User             `gorm:"many2many:user_favorites;"`
+	Reservations      []Reservation      `gorm:"foreignKey:PropertyID"`
+	Reviews           []Review           `gorm:"foreignKey:PropertyID" json:",omitempty"`
+	SeasonalPrices    []SeasonalPrice    `gorm:"foreignKey:PropertyID"`
+	Images            []PropertyImage    `gorm:"foreignKey:PropertyID"`
+	Wishlists         []Wishlist         `gorm:"many2many:wishlist_properties;" json:"-"`
+	Reports           []PropertyReport   `gorm:"foreignKey:PropertyID" json:"-"`
+	ExternalCalendars []ExternalCalendar `gorm:"foreignKey:PropertyID" json:"-"`
+	BlockedDates      []BlockedDate      `gorm:"foreignKey:PropertyID" json:"-"`
 }
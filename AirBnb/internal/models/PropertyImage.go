@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+type PropertyImage struct {
+	ID            uint   `gorm:"primaryKey;autoIncrement"`
+	PropertyID    uint   `gorm:"index;not null"`
+	Path          string `gorm:"not null;size:255"`
+	ThumbnailPath string `gorm:"size:255"`
+	Position      int    `gorm:"not null;default:0"`
+	IsCover       bool   `gorm:"default:false"`
+	CreatedAt     time.Time
+}
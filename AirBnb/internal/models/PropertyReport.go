@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+const (
+	ReportOpen      = "open"
+	ReportResolved  = "resolved"
+	ReportDismissed = "dismissed"
+)
+
+// PropertyReport is a listing reported by a user. Open reports form the staff moderation queue.
+type PropertyReport struct {
+	ID           uint   `gorm:"primaryKey;autoIncrement"`
+	PropertyID   uint   `gorm:"index;not null"`
+	ReporterID   uint   `gorm:"index;not null"`
+	Reason       string `gorm:"not null;size:50"`
+	Details      string `gorm:"type:text"`
+	Status       string `gorm:"index;not null;default:open"`
+	StaffNote    string `gorm:"type:text"`
+	ReviewedByID *uint
+	ReviewedAt   *time.Time
+	CreatedAt    time.Time
+	Property     Property `gorm:"foreignKey:PropertyID"`
+	Reporter     User     `gorm:"foreignKey:ReporterID"`
+	ReviewedBy   *User    `gorm:"foreignKey:ReviewedByID" json:",omitempty"`
+}
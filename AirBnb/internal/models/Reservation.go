@@ -2,16 +2,53 @@ package models
 
 import "time"
 
+const (
+	ReservationRequested = "requested"
+	ReservationConfirmed = "confirmed"
+	ReservationDeclined  = "declined"
+	ReservationCheckedIn = "checked_in"
+	ReservationCompleted = "completed"
+	ReservationCancelled = "cancelled"
+)
+
+// reservationTransitions lists the statuses a reservation may move to from each status.
+var reservationTransitions = map[string][]string{
+	ReservationRequested: {ReservationConfirmed, ReservationDeclined, ReservationCancelled},
+	ReservationConfirmed: {ReservationCheckedIn, ReservationCancelled},
+	ReservationCheckedIn: {ReservationCompleted},
+}
+
+// CanTransitionReservation reports whether a reservation in status from may move to status to.
+func CanTransitionReservation(from, to string) bool {
+	for _, status := range reservationTransitions[from] {
+		if status == to {
+			return true
+		}
+	}
+	return false
+}
+
 type Reservation struct {
 	ID             uint `gorm:"primaryKey;autoIncrement"`
 	StartDate      time.Time
 	EndDate        time.Time
 	NumberOfNights int
 	Guests         int
+	NightsSubtotal float64
+	ExtraGuestFee  float64
+	CleaningFee    float64
+	Discount       float64
 	TotalPrice     float64
-	CreatedAt      time.Time
-	CreatedByID    uint
-	PropertyID     uint
-	CreatedBy      User     `gorm:"foreignKey:CreatedByID"`
-	Property       Property `gorm:"foreignKey:PropertyID"`
+	Status         string `gorm:"size:20;not null;default:requested;index"`
+	CancelledAt    *time.Time
+	CancelledByID  *uint
+	// CheckInReminderSentAt is set once the guest was reminded of the stay
+	CheckInReminderSentAt *time.Time `json:"-"`
+	CreatedAt             time.Time
+	UpdatedAt             time.Time
+	CreatedByID           uint
+	PropertyID            uint
+	CreatedBy             User     `gorm:"foreignKey:CreatedByID"`
+	Property              Property `gorm:"foreignKey:PropertyID"`
+	CancelledBy           *User    `gorm:"foreignKey:CancelledByID"`
 }
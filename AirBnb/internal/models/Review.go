@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+type Review struct {
+	ID            uint `gorm:"primaryKey;autoIncrement"`
+	ReservationID uint `gorm:"uniqueIndex;not null"`
+	PropertyID    uint `gorm:"index;not null"`
+	GuestID       uint `gorm:"not null"`
+	Cleanliness   int  `gorm:"not null"`
+	Accuracy      int  `gorm:"not null"`
+	Location      int  `gorm:"not null"`
+	Value         int  `gorm:"not null"`
+	Rating        float64
+	Comment       string `gorm:"type:text"`
+	LandlordReply string `gorm:"type:text"`
+	RepliedAt     *time.Time
+	CreatedAt     time.Time
+	UpdatedAt     time.Time
+	Reservation   Reservation `gorm:"foreignKey:ReservationID" json:"-"`
+	Property      Property    `gorm:"foreignKey:PropertyID" json:"-"`
+	Guest         User        `gorm:"foreignKey:GuestID"`
+}
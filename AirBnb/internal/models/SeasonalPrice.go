@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// SeasonalPrice overrides a property's nightly price for the nights in [StartDate, EndDate).
+type SeasonalPrice struct {
+	ID            uint      `gorm:"primaryKey;autoIncrement"`
+	PropertyID    uint      `gorm:"index;not null"`
+	StartDate     time.Time `gorm:"not null"`
+	EndDate       time.Time `gorm:"not null"`
+	PricePerNight int       `gorm:"not null"`
+	CreatedAt     time.Time
+}
@@ -10,7 +10,7 @@ type User struct {
 	Password   string `gorm:"not null" json:"-"`
 	Name       string `gorm:"not null;size:255"`
 	Avatar     string `gorm:"not null;size:255"`
-	Token      string `gorm:"not null;size:255"`
+	Token      string `gorm:"not null;size:255" json:"-"` // email verification / password reset token
 	IsVerified bool   `gorm:"default:false"`
 	IsActive   bool   `gorm:"default:true"`
 	IsStaff    bool   `gorm:"default:false"`
@@ -20,4 +20,5 @@ type User struct {
 	Properties   []Property    `gorm:"foreignKey:LandlordID"`
 	Reservations []Reservation `gorm:"foreignKey:CreatedByID"`
 	Favorites    []Property    `gorm:"many2many:user_favorites;"`
+	Wishlists    []Wishlist    `gorm:"foreignKey:OwnerID"`
 }
@@ -0,0 +1,17 @@
+package models
+
+import "time"
+
+// Wishlist is a named collection of a user's favorite properties. A wishlist can be viewed
+// without logging in by anyone who knows its ShareToken; the token is empty when sharing is off.
+type Wishlist struct {
+	ID         uint    `gorm:"primaryKey;autoIncrement"`
+	Name       string  `gorm:"not null;size:255"`
+	ShareToken *string `gorm:"uniqueIndex;size:64" json:",omitempty"`
+	OwnerID    uint    `gorm:"index;not null"`
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+
+	Owner      User       `gorm:"foreignKey:OwnerID" json:"-"`
+	Properties []Property `gorm:"many2many:wishlist_properties;"`
+}
@@ -0,0 +1,93 @@
+package payments
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+type fakePayment struct {
+	authorized int64
+	captured   int64
+	refunded   int64
+	voided     bool
+}
+
+// FakeProvider is an in-process Provider that keeps payments in memory. It is used in
+// development and tests; set Decline to refuse every new authorization.
+type FakeProvider struct {
+	Decline bool
+
+	mu       sync.Mutex
+	nextID   int
+	payments map[string]*fakePayment
+}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{payments: make(map[string]*fakePayment)}
+}
+
+func (p *FakeProvider) Name() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Authorize(ctx context.Context, amount int64, reference string) (string, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.Decline || amount <= 0 {
+		return "", ErrPaymentDeclined
+	}
+
+	p.nextID++
+	id := fmt.Sprintf("fake_%d_%s", p.nextID, reference)
+	p.payments[id] = &fakePayment{authorized: amount}
+	return id, nil
+}
+
+func (p *FakeProvider) Capture(ctx context.Context, paymentID string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[paymentID]
+	if !ok || payment.voided || payment.captured > 0 || amount > payment.authorized {
+		return ErrInvalidPaymentState
+	}
+	payment.captured = amount
+	return nil
+}
+
+func (p *FakeProvider) Void(ctx context.Context, paymentID string) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[paymentID]
+	if !ok || payment.captured > 0 {
+		return ErrInvalidPaymentState
+	}
+	payment.voided = true
+	return nil
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, paymentID string, amount int64) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	payment, ok := p.payments[paymentID]
+	if !ok || amount <= 0 || payment.refunded+amount > payment.captured {
+		return ErrInvalidPaymentState
+	}
+	payment.refunded += amount
+	return nil
+}
+
+// Balance returns the captured and refunded amounts of a payment, for assertions in tests.
+func (p *FakeProvider) Balance(paymentID string) (captured int64, refunded int64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if payment, ok := p.payments[paymentID]; ok {
+		return payment.captured, payment.refunded
+	}
+	return 0, 0
+}
@@ -0,0 +1,92 @@
+package payments
+
+const (
+	// AccountGuestCharges holds the money collected from guests.
+	AccountGuestCharges = "guest_charges"
+	// AccountLandlordPayable is what is owed to landlords until it is paid out.
+	AccountLandlordPayable = "landlord_payable"
+	// AccountPlatformFees is the platform's revenue.
+	AccountPlatformFees = "platform_fees"
+	// AccountLandlordPayouts holds the money paid out to landlords.
+	AccountLandlordPayouts = "landlord_payouts"
+
+	KindCapture = "capture"
+	KindRefund  = "refund"
+	KindPayout  = "payout"
+
+	// PlatformFeePercent is the share of every captured payment kept by the platform.
+	PlatformFeePercent = 3
+)
+
+// Entry is one side of a ledger transaction. Exactly one of Debit and Credit is set.
+type Entry struct {
+	Account string
+	Debit   int64
+	Credit  int64
+}
+
+// Transaction is a set of entries whose debits and credits are equal.
+type Transaction struct {
+	Kind        string
+	Description string
+	Entries     []Entry
+}
+
+// Balanced reports whether the debits of the transaction equal its credits.
+func (t Transaction) Balanced() bool {
+	var debits, credits int64
+	for _, entry := range t.Entries {
+		debits += entry.Debit
+		credits += entry.Credit
+	}
+	return debits == credits
+}
+
+// PlatformFee returns the platform's share of a captured amount.
+func PlatformFee(captured int64) int64 {
+	return percentOf(captured, PlatformFeePercent)
+}
+
+// CaptureTransaction records a guest charge split between the landlord and the platform.
+func CaptureTransaction(amount int64) Transaction {
+	fee := PlatformFee(amount)
+	return Transaction{
+		Kind:        KindCapture,
+		Description: "Guest charge captured",
+		Entries: []Entry{
+			{Account: AccountGuestCharges, Debit: amount},
+			{Account: AccountLandlordPayable, Credit: amount - fee},
+			{Account: AccountPlatformFees, Credit: fee},
+		},
+	}
+}
+
+// RefundTransaction records a refund of a captured amount. The landlord and the platform give
+// back their shares in proportion to the refund.
+func RefundTransaction(refund int64, captured int64) Transaction {
+	feeShare := int64(0)
+	if captured > 0 {
+		feeShare = PlatformFee(captured) * refund / captured
+	}
+	return Transaction{
+		Kind:        KindRefund,
+		Description: "Guest refund",
+		Entries: []Entry{
+			{Account: AccountLandlordPayable, Debit: refund - feeShare},
+			{Account: AccountPlatformFees, Debit: feeShare},
+			{Account: AccountGuestCharges, Credit: refund},
+		},
+	}
+}
+
+// PayoutTransaction records paying out what the landlord is owed for a reservation.
+func PayoutTransaction(amount int64) Transaction {
+	return Transaction{
+		Kind:        KindPayout,
+		Description: "Landlord payout",
+		Entries: []Entry{
+			{Account: AccountLandlordPayable, Debit: amount},
+			{Account: AccountLandlordPayouts, Credit: amount},
+		},
+	}
+}
@@ -0,0 +1,96 @@
+package payments
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestFakeProviderLifecycle(t *testing.T) {
+	ctx := context.Background()
+	provider := NewFakeProvider()
+
+	id, err := provider.Authorize(ctx, 10000, "1")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := provider.Capture(ctx, id, 10000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := provider.Void(ctx, id); !errors.Is(err, ErrInvalidPaymentState) {
+		t.Fatalf("expected a captured payment not to be voidable, got %v", err)
+	}
+	if err := provider.Refund(ctx, id, 4000); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := provider.Refund(ctx, id, 7000); !errors.Is(err, ErrInvalidPaymentState) {
+		t.Fatalf("expected refunds above the captured amount to fail, got %v", err)
+	}
+
+	if captured, refunded := provider.Balance(id); captured != 10000 || refunded != 4000 {
+		t.Fatalf("expected 10000 captured and 4000 refunded, got %d and %d", captured, refunded)
+	}
+
+	provider.Decline = true
+	if _, err := provider.Authorize(ctx, 10000, "2"); !errors.Is(err, ErrPaymentDeclined) {
+		t.Fatalf("expected the authorization to be declined, got %v", err)
+	}
+}
+
+func TestRefundAmount(t *testing.T) {
+	checkIn := time.Date(2025, 6, 10, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		policy   string
+		daysLeft int
+		expected int64
+	}{
+		{PolicyFlexible, 2, 10000},
+		{PolicyFlexible, 0, 0},
+		{PolicyModerate, 5, 10000},
+		{PolicyModerate, 3, 5000},
+		{PolicyStrict, 7, 5000},
+		{PolicyStrict, 6, 0},
+	}
+
+	for _, test := range tests {
+		cancelledAt := checkIn.AddDate(0, 0, -test.daysLeft)
+		if refund := RefundAmount(test.policy, 10000, checkIn, cancelledAt); refund != test.expected {
+			t.Errorf("%s policy with %d days left: expected %d, got %d", test.policy, test.daysLeft, test.expected, refund)
+		}
+	}
+}
+
+func TestLedgerTransactionsBalance(t *testing.T) {
+	capture := CaptureTransaction(12345)
+	refund := RefundTransaction(6172, 12345)
+	payout := PayoutTransaction(5000)
+
+	for _, transaction := range []Transaction{capture, refund, payout} {
+		if !transaction.Balanced() {
+			t.Errorf("%s transaction is not balanced: %+v", transaction.Kind, transaction.Entries)
+		}
+	}
+
+	// 3% of 123.45 is 3.70, the landlord is owed the rest
+	if capture.Entries[1].Credit != 12345-370 || capture.Entries[2].Credit != 370 {
+		t.Fatalf("unexpected capture split: %+v", capture.Entries)
+	}
+}
+
+func TestProviderFromEnv(t *testing.T) {
+	t.Setenv("PAYMENT_PROVIDER", "")
+	if provider, err := ProviderFromEnv(); err != nil || provider != nil {
+		t.Errorf("expected bookings to be disabled without a provider, got %v, %v", provider, err)
+	}
+
+	t.Setenv("PAYMENT_PROVIDER", "fake")
+	if provider, err := ProviderFromEnv(); err != nil || provider == nil {
+		t.Errorf("expected the fake provider, got %v, %v", provider, err)
+	}
+
+	t.Setenv("PAYMENT_PROVIDER", "stripe")
+	if _, err := ProviderFromEnv(); err == nil {
+		t.Error("expected an error for an unsupported provider")
+	}
+}
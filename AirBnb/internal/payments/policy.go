@@ -0,0 +1,39 @@
+package payments
+
+import "time"
+
+const (
+	PolicyFlexible = "flexible"
+	PolicyModerate = "moderate"
+	PolicyStrict   = "strict"
+)
+
+// RefundAmount returns how much of a captured payment is refunded when the guest cancels at
+// cancelledAt a stay starting at checkIn:
+//
+//   - flexible: full refund up to 24 hours before check-in, nothing afterwards
+//   - moderate: full refund up to 5 days before check-in, half afterwards
+//   - strict: half refund up to 7 days before check-in, nothing afterwards
+//
+// Cancellations by the landlord or by staff are always refunded in full and do not use the policy.
+func RefundAmount(policy string, captured int64, checkIn time.Time, cancelledAt time.Time) int64 {
+	notice := checkIn.Sub(cancelledAt)
+
+	switch policy {
+	case PolicyStrict:
+		if notice >= 7*24*time.Hour {
+			return percentOf(captured, 50)
+		}
+		return 0
+	case PolicyModerate:
+		if notice >= 5*24*time.Hour {
+			return captured
+		}
+		return percentOf(captured, 50)
+	default:
+		if notice >= 24*time.Hour {
+			return captured
+		}
+		return 0
+	}
+}
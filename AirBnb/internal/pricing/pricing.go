@@ -0,0 +1,126 @@
+// Package pricing computes itemized price quotes for a stay from a property's pricing rules.
+package pricing
+
+import (
+	"AirBnb/internal/models"
+	"AirBnb/internal/utils"
+	"math"
+	"time"
+)
+
+const (
+	RateBase     = "base"
+	RateWeekend  = "weekend"
+	RateSeasonal = "seasonal"
+
+	weeklyStayNights  = 7
+	monthlyStayNights = 28
+)
+
+// Rules are the pricing settings of a property. Amounts are whole currency units and
+// discounts are percentages. A zero WeekendPrice means weekends use PricePerNight.
+type Rules struct {
+	PricePerNight   int
+	WeekendPrice    int
+	CleaningFee     int
+	BaseGuests      int
+	ExtraGuestFee   int
+	WeeklyDiscount  int
+	MonthlyDiscount int
+	Seasons         []models.SeasonalPrice
+}
+
+type Night struct {
+	Date  string  `json:"date"`
+	Price float64 `json:"price"`
+	Rate  string  `json:"rate"`
+}
+
+type Breakdown struct {
+	Nights          []Night `json:"nights"`
+	NumberOfNights  int     `json:"number_of_nights"`
+	Guests          int     `json:"guests"`
+	NightsSubtotal  float64 `json:"nights_subtotal"`
+	ExtraGuestFee   float64 `json:"extra_guest_fee"`
+	CleaningFee     float64 `json:"cleaning_fee"`
+	DiscountPercent int     `json:"discount_percent"`
+	Discount        float64 `json:"discount"`
+	Total           float64 `json:"total"`
+}
+
+// RulesFromProperty builds the pricing rules of a property, including its seasonal prices.
+func RulesFromProperty(property models.Property) Rules {
+	return Rules{
+		PricePerNight:   property.PricePerNight,
+		WeekendPrice:    property.WeekendPrice,
+		CleaningFee:     property.CleaningFee,
+		BaseGuests:      property.BaseGuests,
+		ExtraGuestFee:   property.ExtraGuestFee,
+		WeeklyDiscount:  property.WeeklyDiscount,
+		MonthlyDiscount: property.MonthlyDiscount,
+		Seasons:         property.SeasonalPrices,
+	}
+}
+
+// Quote prices every night between start and end and adds fees and length-of-stay discounts.
+// Seasonal prices take precedence over weekend prices, which take precedence over the base price.
+// Friday and Saturday nights count as the weekend.
+func Quote(rules Rules, start, end time.Time, guests int) Breakdown {
+	start = utils.TruncateToDay(start)
+	end = utils.TruncateToDay(end)
+
+	breakdown := Breakdown{
+		Nights: []Night{},
+		Guests: guests,
+	}
+
+	for night := start; night.Before(end); night = night.AddDate(0, 0, 1) {
+		price, rate := rules.nightlyRate(night)
+		breakdown.Nights = append(breakdown.Nights, Night{
+			Date:  night.Format(utils.DateLayout),
+			Price: float64(price),
+			Rate:  rate,
+		})
+		breakdown.NightsSubtotal += float64(price)
+	}
+	breakdown.NumberOfNights = len(breakdown.Nights)
+
+	// Guests above the base occupancy pay a fee for every night
+	if rules.BaseGuests > 0 && guests > rules.BaseGuests {
+		breakdown.ExtraGuestFee = float64((guests - rules.BaseGuests) * rules.ExtraGuestFee * breakdown.NumberOfNights)
+	}
+
+	// Only the best length-of-stay discount applies
+	switch {
+	case breakdown.NumberOfNights >= monthlyStayNights && rules.MonthlyDiscount > 0:
+		breakdown.DiscountPercent = rules.MonthlyDiscount
+	case breakdown.NumberOfNights >= weeklyStayNights && rules.WeeklyDiscount > 0:
+		breakdown.DiscountPercent = rules.WeeklyDiscount
+	}
+	breakdown.Discount = roundCents(breakdown.NightsSubtotal * float64(breakdown.DiscountPercent) / 100)
+
+	if breakdown.NumberOfNights > 0 {
+		breakdown.CleaningFee = float64(rules.CleaningFee)
+	}
+
+	breakdown.Total = roundCents(breakdown.NightsSubtotal + breakdown.ExtraGuestFee + breakdown.CleaningFee - breakdown.Discount)
+	return breakdown
+}
+
+func (r Rules) nightlyRate(night time.Time) (int, string) {
+	for _, season := range r.Seasons {
+		if !night.Before(utils.TruncateToDay(season.StartDate)) && night.Before(utils.TruncateToDay(season.EndDate)) {
+			return season.PricePerNight, RateSeasonal
+		}
+	}
+
+	if r.WeekendPrice > 0 && (night.Weekday() == time.Friday || night.Weekday() == time.Saturday) {
+		return r.WeekendPrice, RateWeekend
+	}
+
+	return r.PricePerNight, RateBase
+}
+
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
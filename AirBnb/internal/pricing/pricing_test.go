@@ -0,0 +1,69 @@
+package pricing
+
+import (
+	"AirBnb/internal/models"
+	"testing"
+	"time"
+)
+
+func date(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+func TestQuoteWeekendAndSeasonalRates(t *testing.T) {
+	rules := Rules{
+		PricePerNight: 100,
+		WeekendPrice:  150,
+		Seasons: []models.SeasonalPrice{
+			{StartDate: date(2025, 3, 12), EndDate: date(2025, 3, 13), PricePerNight: 300},
+		},
+	}
+
+	// Monday 10 March to Sunday 16 March 2025: 6 nights, Friday and Saturday are weekend nights
+	quote := Quote(rules, date(2025, 3, 10), date(2025, 3, 16), 2)
+
+	if quote.NumberOfNights != 6 {
+		t.Fatalf("expected 6 nights, got %d", quote.NumberOfNights)
+	}
+
+	expectedRates := []string{RateBase, RateBase, RateSeasonal, RateBase, RateWeekend, RateWeekend}
+	for i, night := range quote.Nights {
+		if night.Rate != expectedRates[i] {
+			t.Errorf("night %s: expected rate %s, got %s", night.Date, expectedRates[i], night.Rate)
+		}
+	}
+
+	if quote.NightsSubtotal != 900 {
+		t.Fatalf("expected subtotal 900, got %v", quote.NightsSubtotal)
+	}
+}
+
+func TestQuoteFeesAndDiscounts(t *testing.T) {
+	rules := Rules{
+		PricePerNight:   100,
+		CleaningFee:     50,
+		BaseGuests:      2,
+		ExtraGuestFee:   10,
+		WeeklyDiscount:  10,
+		MonthlyDiscount: 25,
+	}
+
+	// 7 nights for 3 guests: one extra guest every night and the weekly discount
+	quote := Quote(rules, date(2025, 3, 10), date(2025, 3, 17), 3)
+
+	if quote.ExtraGuestFee != 70 {
+		t.Errorf("expected extra guest fee 70, got %v", quote.ExtraGuestFee)
+	}
+	if quote.DiscountPercent != 10 || quote.Discount != 70 {
+		t.Errorf("expected a 10%% discount of 70, got %d%% of %v", quote.DiscountPercent, quote.Discount)
+	}
+	if quote.Total != 750 {
+		t.Errorf("expected total 750, got %v", quote.Total)
+	}
+
+	// 28 nights switch to the monthly discount
+	quote = Quote(rules, date(2025, 3, 1), date(2025, 3, 29), 2)
+	if quote.DiscountPercent != 25 {
+		t.Errorf("expected the monthly discount, got %d%%", quote.DiscountPercent)
+	}
+}
@@ -18,10 +18,21 @@ func (s *FiberServer) RegisterFiberRoutes() {
 	}))
 
 	// Initialize controllers
-	authController := controllers.NewAuthController(s.db)
+	authController := controllers.NewAuthController(s.db, s.mailer)
 	propertiesController := controllers.NewPropertiesController(s.db)
 	favoritesController := controllers.NewFavoritesController(s.db)
-	reservationController := controllers.NewReservationController(s.db)
+	reservationController := controllers.NewReservationController(s.db, s.payments, s.mailer)
+	availabilityController := controllers.NewAvailabilityController(s.db)
+	conversationController := controllers.NewConversationController(s.db, s.mailer)
+	reviewController := controllers.NewReviewController(s.db)
+	pricingController := controllers.NewPricingController(s.db)
+	propertyImageController := controllers.NewPropertyImageController(s.db)
+	hostController := controllers.NewHostController(s.db)
+	wishlistController := controllers.NewWishlistController(s.db)
+	reportController := controllers.NewReportController(s.db)
+	adminController := controllers.NewAdminController(s.db, s.payments, s.mailer)
+	paymentController := controllers.NewPaymentController(s.db)
+	calendarController := controllers.NewCalendarController(s.db)
 
 	// Auth routes (public)
 	auth := s.App.Group("/auth")
@@ -32,34 +43,138 @@ func (s *FiberServer) RegisterFiberRoutes() {
 	auth.Get("/reset-password/:Token", authController.RestPassword)
 
 	// Protected API routes
-	api := s.App.Group("/api", middleware.AuthRequired())
+	api := s.App.Group("/api", middleware.AuthRequired(s.db))
 
 	// User management
 	api.Delete("/auth/delete/:ID", authController.DeleteUser)
 
+	// Current user routes
+	me := api.Group("/me")
+	me.Get("/reservations", reservationController.GetMyReservations)
+
 	// Property routes
 	properties := api.Group("/properties")
 	properties.Post("/register", propertiesController.RegisterProperty)
 	properties.Get("/", propertiesController.GetAllProperties)
 	properties.Get("/:id", propertiesController.GetPropertyById)
+	properties.Get("/:id/availability", availabilityController.GetPropertyAvailability)
+	properties.Get("/:id/reviews", reviewController.GetPropertyReviews)
+	properties.Get("/:id/quote", pricingController.GetQuote)
+	properties.Post("/:id/report", reportController.CreateReport)
 
 	// Property routes with owner verification
 	propertyProtected := properties.Group("/:id", middleware.PropertyOwner(s.db))
 	propertyProtected.Delete("/", propertiesController.DeleteProperty)
 	propertyProtected.Put("/", propertiesController.UpdateProperty)
+	propertyProtected.Put("/pricing", pricingController.UpdatePricing)
+	propertyProtected.Post("/seasonal-prices", pricingController.CreateSeasonalPrice)
+	propertyProtected.Delete("/seasonal-prices/:seasonId", pricingController.DeleteSeasonalPrice)
+	propertyProtected.Post("/images", propertyImageController.UploadImages)
+	propertyProtected.Put("/images/order", propertyImageController.ReorderImages)
+	propertyProtected.Put("/images/:imageId/cover", propertyImageController.SetCoverImage)
+	propertyProtected.Delete("/images/:imageId", propertyImageController.DeleteImage)
+	propertyProtected.Put("/stay-rules", availabilityController.UpdateStayRules)
+	propertyProtected.Get("/blocked-dates", availabilityController.GetBlockedDates)
+	propertyProtected.Post("/blocked-dates", availabilityController.CreateBlockedDate)
+	propertyProtected.Delete("/blocked-dates/:blockId", availabilityController.DeleteBlockedDate)
+	propertyProtected.Get("/calendars", calendarController.GetExternalCalendars)
+	propertyProtected.Post("/calendars", calendarController.CreateExternalCalendar)
+	propertyProtected.Post("/calendars/:calendarId/sync", calendarController.SyncExternalCalendar)
+	propertyProtected.Delete("/calendars/:calendarId", calendarController.DeleteExternalCalendar)
 
 	// Favorites routes
 	favorites := api.Group("/favorites")
+	favorites.Get("/", favoritesController.GetFavorites)
 	favorites.Post("/:id", favoritesController.AddToFavorites)
 	favorites.Delete("/:id", favoritesController.DeleteFromFavorites)
 
+	// Wishlist routes
+	wishlists := api.Group("/wishlists")
+	wishlists.Get("/", wishlistController.GetWishlists)
+	wishlists.Post("/", wishlistController.CreateWishlist)
+
+	// Wishlist routes with owner verification
+	wishlistProtected := wishlists.Group("/:id", middleware.WishlistOwner(s.db))
+	wishlistProtected.Get("/", wishlistController.GetWishlist)
+	wishlistProtected.Put("/", wishlistController.RenameWishlist)
+	wishlistProtected.Delete("/", wishlistController.DeleteWishlist)
+	wishlistProtected.Post("/properties/:propertyId", wishlistController.AddProperty)
+	wishlistProtected.Delete("/properties/:propertyId", wishlistController.RemoveProperty)
+	wishlistProtected.Put("/share", wishlistController.ShareWishlist)
+	wishlistProtected.Delete("/share", wishlistController.UnshareWishlist)
+
 	// Reservation routes
+	// Routes that move the guests' money are only registered with a payment provider
 	reservations := api.Group("/reservations")
-	reservations.Post("/:id", reservationController.CreateReservation)
+	if s.payments != nil {
+		reservations.Post("/:id", reservationController.CreateReservation)
+	}
 
 	// Reservation routes with owner verification
 	reservationProtected := reservations.Group("/:id", middleware.ReservationOwner(s.db))
-	reservationProtected.Delete("/", reservationController.DeleteReservation)
+	if s.payments != nil {
+		reservationProtected.Put("/cancel", reservationController.CancelReservation)
+	}
+	reservationProtected.Post("/review", reviewController.CreateReview)
+	reservationProtected.Get("/payment", paymentController.GetReservationPayment)
+
+	// Host routes
+	host := api.Group("/host")
+	host.Get("/reservations", reservationController.GetHostReservations)
+	host.Get("/occupancy", hostController.GetOccupancy)
+	host.Get("/earnings", hostController.GetEarnings)
+	host.Get("/bookings.csv", hostController.ExportBookings)
+
+	// Host reservation routes with landlord verification
+	hostReservation := host.Group("/reservations/:id", middleware.ReservationLandlord(s.db))
+	if s.payments != nil {
+		hostReservation.Put("/accept", reservationController.AcceptReservation)
+		hostReservation.Put("/decline", reservationController.DeclineReservation)
+	}
+	hostReservation.Put("/check-in", reservationController.CheckInReservation)
+	hostReservation.Put("/complete", reservationController.CompleteReservation)
+	hostReservation.Get("/ledger", paymentController.GetReservationLedger)
+
+	// Review routes
+	reviews := api.Group("/reviews")
+	reviews.Post("/:id/reply", reviewController.ReplyToReview)
+
+	// Messaging routes
+	messages := api.Group("/messages")
+	messages.Post("/:id", conversationController.CreateConversationMessage)
+
+	conversations := api.Group("/conversations")
+	conversations.Get("/", conversationController.GetConversations)
+
+	// Conversation routes with participant verification
+	conversationProtected := conversations.Group("/:id", middleware.ConversationParticipant(s.db))
+	conversationProtected.Get("/messages", conversationController.GetConversationMessages)
+	conversationProtected.Put("/read", conversationController.MarkConversationRead)
+
+	// Staff routes
+	admin := api.Group("/admin", middleware.StaffRequired())
+	admin.Get("/users", adminController.GetUsers)
+	admin.Put("/users/:id/suspend", adminController.SuspendUser)
+	admin.Put("/users/:id/reactivate", adminController.ReactivateUser)
+	admin.Put("/properties/:id/unpublish", adminController.UnpublishProperty)
+	admin.Put("/properties/:id/publish", adminController.PublishProperty)
+	admin.Delete("/properties/:id", propertiesController.DeleteProperty)
+	if s.payments != nil {
+		admin.Put("/reservations/:id/cancel", adminController.CancelReservation)
+	}
+	admin.Get("/reservations/:id/ledger", paymentController.GetReservationLedger)
+	admin.Get("/reports", adminController.GetReports)
+	admin.Put("/reports/:id/resolve", adminController.ResolveReport)
+	admin.Put("/reports/:id/dismiss", adminController.DismissReport)
+
+	// Shared wishlists are read-only and public
+	s.App.Get("/shared/wishlists/:token", wishlistController.GetSharedWishlist)
+
+	// Property calendars are public so other booking platforms can subscribe to them
+	s.App.Get("/properties/:id/calendar.ics", calendarController.ExportCalendar)
+
+	// Uploaded property images and thumbnails
+	s.App.Static("/image", "./image")
 
 	// Health check and root routes
 	s.App.Get("/", s.HelloWorldHandler)
@@ -1,18 +1,29 @@
 package server
 
 import (
+	"log"
+
 	"github.com/gofiber/fiber/v2"
 
 	"AirBnb/internal/database"
+	"AirBnb/internal/mailer"
+	"AirBnb/internal/payments"
 )
 
 type FiberServer struct {
 	*fiber.App
 
-	db database.Service
+	db       database.Service
+	payments payments.Provider
+	mailer   *mailer.Mailer
 }
 
 func New() *FiberServer {
+	provider, err := payments.ProviderFromEnv()
+	if err != nil {
+		log.Fatalf("failed to initialise payment provider: %v", err)
+	}
+
 	server := &FiberServer{
 		App: fiber.New(fiber.Config{
 			ServerHeader: "AirBnb",
@@ -20,7 +31,16 @@ func New() *FiberServer {
 		}),
 
 		db: database.New(),
+		// Nil when no provider is configured, RegisterFiberRoutes then leaves out the booking routes
+		payments: provider,
+		mailer:   mailer.New(mailer.BackendFromEnv(), mailer.ConfigFromEnv()),
 	}
 
 	return server
 }
+
+// Mailer returns the mailer of the server, so background jobs can send emails and the
+// queue can be drained on shutdown.
+func (s *FiberServer) Mailer() *mailer.Mailer {
+	return s.mailer
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"fmt"
+	"time"
+)
+
+const DateLayout = "2006-01-02"
+
+// ParseDate parses a calendar date in the YYYY-MM-DD format.
+func ParseDate(value string) (time.Time, error) {
+	date, err := time.Parse(DateLayout, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected format YYYY-MM-DD", value)
+	}
+	return date, nil
+}
+
+// TruncateToDay drops the time of day so that dates can be compared night by night.
+func TruncateToDay(t time.Time) time.Time {
+	year, month, day := t.UTC().Date()
+	return time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+}
+
+// NightsBetween returns the number of nights between a check-in and a check-out date.
+func NightsBetween(start, end time.Time) int {
+	return int(TruncateToDay(end).Sub(TruncateToDay(start)).Hours() / 24)
+}
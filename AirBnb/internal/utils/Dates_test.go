@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNightsBetween(t *testing.T) {
+	start := time.Date(2025, 3, 10, 15, 0, 0, 0, time.UTC)
+	end := time.Date(2025, 3, 13, 11, 0, 0, 0, time.UTC)
+
+	if nights := NightsBetween(start, end); nights != 3 {
+		t.Fatalf("expected 3 nights, got %d", nights)
+	}
+
+	if nights := NightsBetween(start, start); nights != 0 {
+		t.Fatalf("expected 0 nights for the same day, got %d", nights)
+	}
+}
+
+func TestParseDate(t *testing.T) {
+	date, err := ParseDate("2025-03-10")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if date.Year() != 2025 || date.Month() != time.March || date.Day() != 10 {
+		t.Fatalf("unexpected date %v", date)
+	}
+
+	if _, err := ParseDate("10/03/2025"); err == nil {
+		t.Fatal("expected an error for an invalid date format")
+	}
+}
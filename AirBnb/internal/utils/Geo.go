@@ -0,0 +1,46 @@
+package utils
+
+import "math"
+
+// EarthRadiusKm is the mean radius of the earth used for distance calculations.
+const EarthRadiusKm = 6371.0
+
+// kmPerDegree is the length of one degree of latitude.
+const kmPerDegree = math.Pi * EarthRadiusKm / 180
+
+// RadiusBounds returns the smallest latitude/longitude box that contains the circle of radiusKm
+// around a point. The longitude range is left open (-180 to 180) when the circle reaches a pole.
+// A box that crosses the antimeridian has minLng greater than maxLng.
+func RadiusBounds(lat, lng, radiusKm float64) (minLat, minLng, maxLat, maxLng float64) {
+	latDelta := radiusKm / kmPerDegree
+	minLat = math.Max(lat-latDelta, -90)
+	maxLat = math.Min(lat+latDelta, 90)
+	if minLat == -90 || maxLat == 90 {
+		return minLat, -180, maxLat, 180
+	}
+
+	lngDelta := radiusKm / (kmPerDegree * math.Cos(lat*math.Pi/180))
+	if lngDelta >= 180 {
+		return minLat, -180, maxLat, 180
+	}
+	return minLat, normalizeLongitude(lng - lngDelta), maxLat, normalizeLongitude(lng + lngDelta)
+}
+
+// BoundsCenter returns the center of a latitude/longitude box, taking boxes that cross the
+// antimeridian (minLng greater than maxLng) into account.
+func BoundsCenter(minLat, minLng, maxLat, maxLng float64) (lat, lng float64) {
+	if minLng > maxLng {
+		maxLng += 360
+	}
+	return (minLat + maxLat) / 2, normalizeLongitude((minLng + maxLng) / 2)
+}
+
+func normalizeLongitude(lng float64) float64 {
+	for lng > 180 {
+		lng -= 360
+	}
+	for lng < -180 {
+		lng += 360
+	}
+	return lng
+}
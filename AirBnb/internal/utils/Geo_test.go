@@ -0,0 +1,40 @@
+package utils
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRadiusBounds(t *testing.T) {
+	minLat, minLng, maxLat, maxLng := RadiusBounds(0, 0, 111.195)
+	if math.Abs(minLat+1) > 0.001 || math.Abs(maxLat-1) > 0.001 {
+		t.Fatalf("expected a latitude range of one degree, got %f to %f", minLat, maxLat)
+	}
+	if math.Abs(minLng+1) > 0.001 || math.Abs(maxLng-1) > 0.001 {
+		t.Fatalf("expected a longitude range of one degree at the equator, got %f to %f", minLng, maxLng)
+	}
+
+	// Around the antimeridian the box wraps and minLng is greater than maxLng
+	_, minLng, _, maxLng = RadiusBounds(0, 179.5, 111.195)
+	if minLng <= maxLng {
+		t.Fatalf("expected a wrapped longitude range, got %f to %f", minLng, maxLng)
+	}
+
+	// A circle reaching a pole covers every longitude
+	_, minLng, maxLat, maxLng = RadiusBounds(89.5, 10, 111.195)
+	if maxLat != 90 || minLng != -180 || maxLng != 180 {
+		t.Fatalf("expected an open box at the pole, got lat %f and lng %f to %f", maxLat, minLng, maxLng)
+	}
+}
+
+func TestBoundsCenter(t *testing.T) {
+	lat, lng := BoundsCenter(10, 20, 30, 40)
+	if lat != 20 || lng != 30 {
+		t.Fatalf("expected center 20,30, got %f,%f", lat, lng)
+	}
+
+	_, lng = BoundsCenter(-10, 170, 10, -170)
+	if math.Abs(math.Abs(lng)-180) > 0.001 {
+		t.Fatalf("expected the center on the antimeridian, got %f", lng)
+	}
+}
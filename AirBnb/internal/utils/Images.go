@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif"
+	"image/jpeg"
+	_ "image/png"
+	"io"
+	"os"
+)
+
+const ThumbnailWidth = 400
+
+// Limits on the dimensions of images accepted for thumbnailing. A small compressed file can
+// declare huge dimensions, and decoding allocates memory for every pixel.
+const (
+	MaxImageWidth  = 10000
+	MaxImageHeight = 10000
+	MaxImagePixels = 40_000_000
+)
+
+var ErrImageTooLarge = errors.New("image dimensions exceed the allowed limit")
+
+// CreateThumbnail decodes the JPEG, PNG or GIF image at srcPath, scales it down to at most
+// maxWidth pixels wide keeping the aspect ratio, and writes it as a JPEG to dstPath.
+func CreateThumbnail(srcPath, dstPath string, maxWidth int) error {
+	srcFile, err := os.Open(srcPath)
+	if err != nil {
+		return err
+	}
+	defer srcFile.Close()
+
+	// Check the declared dimensions before decoding the whole image
+	config, _, err := image.DecodeConfig(srcFile)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %v", err)
+	}
+	if config.Width > MaxImageWidth || config.Height > MaxImageHeight ||
+		int64(config.Width)*int64(config.Height) > MaxImagePixels {
+		return ErrImageTooLarge
+	}
+	if _, err := srcFile.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	src, _, err := image.Decode(srcFile)
+	if err != nil {
+		return fmt.Errorf("failed to decode image: %v", err)
+	}
+
+	bounds := src.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width > maxWidth {
+		height = height * maxWidth / width
+		width = maxWidth
+	}
+	if height < 1 {
+		height = 1
+	}
+
+	dstFile, err := os.Create(dstPath)
+	if err != nil {
+		return err
+	}
+	defer dstFile.Close()
+
+	return jpeg.Encode(dstFile, scaleDown(src, width, height), &jpeg.Options{Quality: 80})
+}
+
+// scaleDown resizes src to width x height by averaging the source pixels covered by
+// each destination pixel. It is meant for shrinking images, not enlarging them.
+func scaleDown(src image.Image, width, height int) *image.RGBA {
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	bounds := src.Bounds()
+
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + y*bounds.Dy()/height
+		y1 := bounds.Min.Y + (y+1)*bounds.Dy()/height
+		if y1 <= y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + x*bounds.Dx()/width
+			x1 := bounds.Min.X + (x+1)*bounds.Dx()/width
+			if x1 <= x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					pr, pg, pb, pa := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(pr), g+uint64(pg), b+uint64(pb), a+uint64(pa)
+					count++
+				}
+			}
+
+			dst.Set(x, y, color.RGBA64{
+				R: uint16(r / count),
+				G: uint16(g / count),
+				B: uint16(b / count),
+				A: uint16(a / count),
+			})
+		}
+	}
+
+	return dst
+}
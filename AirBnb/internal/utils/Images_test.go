@@ -0,0 +1,72 @@
+package utils
+
+import (
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateThumbnail(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "source.png")
+	dstPath := filepath.Join(dir, "thumbnail.jpg")
+
+	src := image.NewRGBA(image.Rect(0, 0, 1200, 600))
+	for y := 0; y < 600; y++ {
+		for x := 0; x < 1200; x++ {
+			src.Set(x, y, color.RGBA{R: 200, G: 50, B: 50, A: 255})
+		}
+	}
+
+	file, err := os.Create(srcPath)
+	if err != nil {
+		t.Fatalf("failed to create source image: %v", err)
+	}
+	if err := png.Encode(file, src); err != nil {
+		t.Fatalf("failed to encode source image: %v", err)
+	}
+	file.Close()
+
+	if err := CreateThumbnail(srcPath, dstPath, ThumbnailWidth); err != nil {
+		t.Fatalf("CreateThumbnail returned an error: %v", err)
+	}
+
+	thumbnail, err := os.Open(dstPath)
+	if err != nil {
+		t.Fatalf("failed to open thumbnail: %v", err)
+	}
+	defer thumbnail.Close()
+
+	config, err := jpeg.DecodeConfig(thumbnail)
+	if err != nil {
+		t.Fatalf("thumbnail is not a JPEG: %v", err)
+	}
+	if config.Width != 400 || config.Height != 200 {
+		t.Fatalf("expected a 400x200 thumbnail, got %dx%d", config.Width, config.Height)
+	}
+}
+
+func TestCreateThumbnailRejectsHugeImages(t *testing.T) {
+	dir := t.TempDir()
+	srcPath := filepath.Join(dir, "huge.png")
+
+	// A single-row image wider than MaxImageWidth is only a few bytes once compressed
+	file, err := os.Create(srcPath)
+	if err != nil {
+		t.Fatalf("failed to create source image: %v", err)
+	}
+	if err := png.Encode(file, image.NewGray(image.Rect(0, 0, MaxImageWidth+1, 1))); err != nil {
+		t.Fatalf("failed to encode source image: %v", err)
+	}
+	file.Close()
+
+	err = CreateThumbnail(srcPath, filepath.Join(dir, "thumbnail.jpg"), ThumbnailWidth)
+	if !errors.Is(err, ErrImageTooLarge) {
+		t.Fatalf("expected ErrImageTooLarge, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"Tiktok/internal/database"
+	"Tiktok/internal/jobs"
 	"Tiktok/internal/server"
 	"context"
 	"fmt"
@@ -53,6 +54,20 @@ func main() {
 		}
 	}()
 
+	// Background jobs stop when the server shuts down
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	reconcileInterval := jobs.DefaultReconcileInterval
+	if raw := os.Getenv("FOLLOW_RECONCILE_INTERVAL"); raw != "" {
+		parsed, err := time.ParseDuration(raw)
+		if err != nil {
+			log.Fatalf("invalid FOLLOW_RECONCILE_INTERVAL %q: %v", raw, err)
+		}
+		reconcileInterval = parsed
+	}
+	go jobs.RunFollowCountReconciler(backgroundCtx, db, reconcileInterval)
+
 	// Create a done channel to signal when the shutdown is complete
 	done := make(chan bool, 1)
 
@@ -69,5 +84,8 @@ func main() {
 
 	// Wait for the graceful shutdown to complete
 	<-done
+	stopBackground()
+	server.Processor().Close()
+	server.Counters().Close()
 	log.Println("Graceful shutdown complete.")
 }
@@ -3,13 +3,19 @@ package controllers
 import (
 	"Tiktok/internal/config"
 	"Tiktok/internal/database"
+	"Tiktok/internal/storage"
 	"Tiktok/internal/utils"
 	"context"
 	"errors"
+	"fmt"
 	"html"
 	"log"
+	"mime"
 	"mime/multipart"
+	"path/filepath"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
@@ -20,12 +26,14 @@ import (
 type AuthController struct {
 	db       database.Service    // The database service to interact with the database.
 	validate *validator.Validate // Validator instance for validating user inputs.
+	storage  storage.Storage     // Where edited avatars are uploaded.
 }
 
-func NewAuthController(db database.Service) *AuthController {
+func NewAuthController(db database.Service, files storage.Storage) *AuthController {
 	return &AuthController{
 		db:       db,              // Setting the provided database service.
 		validate: validator.New(), // Initializing a new validator instance.
+		storage:  files,           // Setting the media storage backend.
 	}
 }
 
@@ -165,7 +173,7 @@ func (ac *AuthController) VerifyEmail(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Bad Verification token", err.Error())
 	}
 
-	JWT, err := utils.GenerateToken(int(updateResult.ID), updateResult.Avatar, updateResult.Email, updateResult.Name, updateResult.Token, updateResult.Bio, updateResult.EmailVerified, updateResult.FollowerCount, updateResult.FollowingCount)
+	JWT, err := utils.GenerateToken(int(updateResult.ID), updateResult.Email, updateResult.Name, updateResult.Avatar, updateResult.Token, updateResult.Bio, updateResult.EmailVerified, updateResult.FollowerCount, updateResult.FollowingCount)
 
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate JWT token", err.Error())
@@ -221,7 +229,7 @@ func (ac *AuthController) Login(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Error during login", utils.FormatValidationErrors(err))
 	}
 
-	JWT, err := utils.GenerateToken(int(user.ID), user.Avatar, user.Email, user.Name, user.Token, user.Bio, user.EmailVerified, user.FollowerCount, user.FollowingCount)
+	JWT, err := utils.GenerateToken(int(user.ID), user.Email, user.Name, user.Avatar, user.Token, user.Bio, user.EmailVerified, user.FollowerCount, user.FollowingCount)
 
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate JWT token", err.Error())
@@ -313,7 +321,7 @@ func (ac *AuthController) RestPassword(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
 	}
 
-	JWT, err := utils.GenerateToken(int(FindToken.ID), FindToken.Avatar, FindToken.Email, FindToken.Name, FindToken.Token, FindToken.Bio, FindToken.EmailVerified, FindToken.FollowerCount, FindToken.FollowingCount)
+	JWT, err := utils.GenerateToken(int(FindToken.ID), FindToken.Email, FindToken.Name, FindToken.Avatar, FindToken.Token, FindToken.Bio, FindToken.EmailVerified, FindToken.FollowerCount, FindToken.FollowingCount)
 
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate JWT token", err.Error())
@@ -444,16 +452,20 @@ func (ac *AuthController) DeleteUser(c *fiber.Ctx) error {
 // ------------------------------------------------------------------------------------------------------------
 
 type EditUserRequest struct {
-	Name     string `form:"name" validate:"omitempty,max=255"`
-	Email    string `form:"email" validate:"omitempty,email,max=255"`
-	Password string `form:"password" validate:"omitempty,max=255,min=8"`
-	Bio      string `form:"bio" validate:"omitempty,max=255"`
+	Name *string `form:"name" json:"name" validate:"omitempty,max=255"`
+	Bio  *string `form:"bio" json:"bio" validate:"omitempty,max=255"`
 }
 
+// EditUser updates the current user's name, bio and avatar. Only the fields
+// sent are changed; an empty bio clears it. A new avatar replaces the old
+// one, which is deleted from storage once the profile has been saved.
 func (ac *AuthController) EditUser(c *fiber.Ctx) error {
-	claims := c.Locals("user").(*utils.Claims)
-	var req EditUserRequest
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
 
+	var req EditUserRequest
 	if err := c.BodyParser(&req); err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid form data", err.Error())
 	}
@@ -462,100 +474,74 @@ func (ac *AuthController) EditUser(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", utils.FormatValidationErrors(err))
 	}
 
-	// Get existing user
+	var update database.ProfileUpdate
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", map[string]string{"name": "name cannot be empty"})
+		}
+		name = html.EscapeString(name)
+		update.Name = &name
+	}
+	if req.Bio != nil {
+		bio := html.EscapeString(strings.TrimSpace(*req.Bio))
+		update.Bio = &bio
+	}
+
 	existingUser, err := ac.db.FindUserById(uint(claims.UserID))
 	if err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusNotFound, "User not found", err.Error())
 	}
 
-	// Handle avatar upload if provided
-	file, err := c.FormFile("avatar")
-	if err == nil {
-		uploadChan := make(chan struct {
-			url string
-			err error
-		})
+	// Upload the new avatar first so a failed upload leaves the old one in
+	// place.
+	var newAvatar string
+	if file, err := c.FormFile("avatar"); err == nil {
+		if err := utils.ValidateImageFile(file); err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid avatar", err.Error())
+		}
 
-		cld, err := config.InitCloudinary()
+		f, err := file.Open()
 		if err != nil {
-			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to initialize Cloudinary", err.Error())
+			return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Failed to read avatar", err.Error())
 		}
+		defer f.Close()
 
-		ctx := context.Background()
-
-		go func(file *multipart.FileHeader) {
-			var result struct {
-				url string
-				err error
-			}
-
-			if err := utils.ValidateImageFile(file); err != nil {
-				result.err = err
-				uploadChan <- result
-				return
-			}
-
-			fileHeader, err := file.Open()
-			if err != nil {
-				result.err = err
-				uploadChan <- result
-				return
-			}
-			defer fileHeader.Close()
-
-			// Delete old avatar if exists
-			if existingUser.Avatar != "" {
-				publicID, err := utils.ExtractPublicID(existingUser.Avatar)
-				if err == nil {
-					utils.DeleteImageFromCloudinary(cld, publicID)
-				}
-			}
-
-			url, err := utils.UploadToCloudinary(cld, ctx, fileHeader)
-			if err != nil {
-				result.err = err
-				uploadChan <- result
-				return
-			}
-
-			result.url = url
-			uploadChan <- result
-		}(file)
-
-		uploadResult := <-uploadChan
-		if uploadResult.err != nil {
-			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to upload image", uploadResult.err.Error())
+		ext := strings.ToLower(filepath.Ext(file.Filename))
+		key := fmt.Sprintf("avatars/%d_%d%s", existingUser.ID, time.Now().UnixNano(), ext)
+		newAvatar, err = ac.storage.Put(c.Context(), key, f, mime.TypeByExtension(ext))
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to upload avatar", err.Error())
 		}
-		existingUser.Avatar = uploadResult.url
+		update.Avatar = &newAvatar
 	}
 
-	// Update user fields if provided
-	if req.Name != "" {
-		existingUser.Name = html.EscapeString(req.Name)
-	}
-	if req.Email != "" {
-		existingUser.Email = html.EscapeString(req.Email)
+	if update == (database.ProfileUpdate{}) {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Nothing to update", "Send a name, bio or avatar")
 	}
-	if req.Password != "" {
-		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
-		if err != nil {
-			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to hash password", err.Error())
+
+	updatedUser, err := ac.db.UpdateUserProfile(existingUser.ID, update)
+	if err != nil {
+		if newAvatar != "" {
+			ac.deleteAvatar(newAvatar)
 		}
-		existingUser.Password = string(hashedPassword)
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update user", err.Error())
 	}
-	if req.Bio != "" {
-		existingUser.Bio = html.EscapeString(req.Bio)
+
+	if newAvatar != "" && existingUser.Avatar != "" {
+		ac.deleteAvatar(existingUser.Avatar)
 	}
 
-	// Update user in database
-	updatedUser, err := ac.db.UpdateUser(*existingUser)
+	// The name and avatar are carried in the JWT, so hand back a fresh one.
+	JWT, err := utils.GenerateToken(int(updatedUser.ID), updatedUser.Email, updatedUser.Name, updatedUser.Avatar, updatedUser.Token, updatedUser.Bio, updatedUser.EmailVerified, updatedUser.FollowerCount, updatedUser.FollowingCount)
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update user", err.Error())
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to generate JWT token", err.Error())
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "User updated successfully",
 		"status":  fiber.StatusOK,
+		"JWT":     JWT,
 		"user": fiber.Map{
 			"id":     updatedUser.ID,
 			"name":   updatedUser.Name,
@@ -566,6 +552,20 @@ func (ac *AuthController) EditUser(c *fiber.Ctx) error {
 	})
 }
 
+// deleteAvatar removes an avatar file in the background. Avatars uploaded
+// outside the configured storage backend are left alone, but logged so files
+// orphaned by a backend switch can be cleaned up by hand.
+func (ac *AuthController) deleteAvatar(url string) {
+	go func() {
+		err := ac.storage.Delete(context.Background(), url)
+		if errors.Is(err, storage.ErrNotOwned) {
+			log.Printf("Avatar %s is not stored by the active storage backend and was not deleted", url)
+		} else if err != nil {
+			log.Printf("Error deleting avatar %s: %v", url, err)
+		}
+	}()
+}
+
 // ------------------------------------------------------------------------------------------------------------
 // ------------------------------ these is the End of the EditRequest logic -------------------------
 // ------------------------------------------------------------------------------------------------------------
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"Tiktok/internal/database"
+	"Tiktok/internal/models"
 	"Tiktok/internal/utils"
+	"fmt"
 	"html"
 	"strconv"
 	"strings"
@@ -28,7 +30,7 @@ func NewCommentController(db database.Service) *CommentController {
 // --------------------------------------------------------------------------------------------------
 
 type CommentPostRequest struct {
-	Comment string `json:"comment" validate:"required, max=255"`
+	Comment string `json:"comment" validate:"required,max=255"`
 }
 
 func (cc *CommentController) CommentPost(c *fiber.Ctx) error {
@@ -52,6 +54,14 @@ func (cc *CommentController) CommentPost(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", utils.FormatValidationErrors(err))
 	}
 
+	post, err := cc.db.FindPostById(uint(postID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Post not found", err.Error())
+	}
+	if !postVisibleTo(post, uint(claims.UserID)) {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Post not found", "")
+	}
+
 	Comment := database.Comment{
 		UserID: uint(claims.UserID),
 		PostID: uint(postID),
@@ -61,12 +71,20 @@ func (cc *CommentController) CommentPost(c *fiber.Ctx) error {
 	CreateComment, err := cc.db.CreateComment(Comment)
 
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update post", err.Error())
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create comment", err.Error())
 	}
 
-	return c.Status(fiber.StatusOK).JSON(fiber.Map{
-		"message": "Post updated successfully",
-		"post":    CreateComment,
+	notify(cc.db, database.Notification{
+		UserID:  post.UserID,
+		FromID:  uint(claims.UserID),
+		PostID:  &post.ID,
+		Type:    models.NotificationComment,
+		Content: fmt.Sprintf("%s commented: %s", claims.Name, Comment.Text),
+	})
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Comment created successfully",
+		"comment": CreateComment,
 	})
 }
 
@@ -79,7 +97,7 @@ func (cc *CommentController) CommentPost(c *fiber.Ctx) error {
 // --------------------------------------------------------------------------------------------------
 
 type UpdateCommentPostRequest struct {
-	Comment string `json:"comment" validate:"required, max=255"`
+	Comment string `json:"comment" validate:"required,max=255"`
 }
 
 func (cc *CommentController) UpdateCommentPost(c *fiber.Ctx) error {
@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"Tiktok/internal/database"
+	"Tiktok/internal/engagement"
+	"Tiktok/internal/models"
+	"Tiktok/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type EngagementController struct {
+	db       database.Service   // The database service to interact with the database.
+	counters *engagement.Buffer // Batches view, share and save counter updates.
+}
+
+func NewEngagementController(db database.Service, counters *engagement.Buffer) *EngagementController {
+	return &EngagementController{
+		db:       db,       // Setting the provided database service.
+		counters: counters, // Setting the counter buffer.
+	}
+}
+
+const (
+	defaultSavedLimit = 20
+	maxSavedLimit     = 50
+)
+
+// visiblePost loads the post from the :id param if the current user may see
+// it, writing the error response otherwise.
+func (ec *EngagementController) visiblePost(c *fiber.Ctx) (*models.Post, *utils.Claims, error) {
+	postID, err := c.ParamsInt("id")
+	if err != nil || postID <= 0 {
+		return nil, nil, utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid post ID", "Post ID must be a positive number")
+	}
+
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return nil, nil, utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	post, err := ec.db.FindPostById(uint(postID))
+	if err != nil {
+		return nil, nil, utils.SendErrorResponse(c, fiber.StatusNotFound, "Post not found", err.Error())
+	}
+
+	if !postVisibleTo(post, uint(claims.UserID)) {
+		return nil, nil, utils.SendErrorResponse(c, fiber.StatusNotFound, "Post not found", "")
+	}
+
+	return post, claims, nil
+}
+
+// postVisibleTo reports whether the user may engage with the post: it has to be ready, and
+// private posts are only visible to their owner.
+func postVisibleTo(post *models.Post, userID uint) bool {
+	isOwner := post.UserID == userID
+	return post.Status == models.PostStatusReady && (!post.IsPrivate || isOwner)
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the view / share logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (ec *EngagementController) RecordView(c *fiber.Ctx) error {
+	post, claims, err := ec.visiblePost(c)
+	if post == nil {
+		return err
+	}
+
+	counted, err := ec.db.RecordPostView(uint(claims.UserID), post.ID, engagement.ViewWindow, time.Now())
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to record view", err.Error())
+	}
+	if counted {
+		ec.counters.AddView(post.ID)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"counted": counted,
+	})
+}
+
+func (ec *EngagementController) RecordShare(c *fiber.Ctx) error {
+	post, _, err := ec.visiblePost(c)
+	if post == nil {
+		return err
+	}
+
+	ec.counters.AddShare(post.ID)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Share recorded",
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the view / share logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the save logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (ec *EngagementController) SavePost(c *fiber.Ctx) error {
+	post, claims, err := ec.visiblePost(c)
+	if post == nil {
+		return err
+	}
+
+	saved, err := ec.db.SavePost(uint(claims.UserID), post.ID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save post", err.Error())
+	}
+	if !saved {
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"message": "Post already saved",
+		})
+	}
+
+	ec.counters.AddSave(post.ID, 1)
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Post saved",
+	})
+}
+
+// UnsavePost doesn't require the post to still be visible, so users can clean
+// up saves of posts that have since gone private.
+func (ec *EngagementController) UnsavePost(c *fiber.Ctx) error {
+	postID, err := c.ParamsInt("id")
+	if err != nil || postID <= 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid post ID", "Post ID must be a positive number")
+	}
+
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	removed, err := ec.db.UnsavePost(uint(claims.UserID), uint(postID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to unsave post", err.Error())
+	}
+	if !removed {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Post is not saved", "")
+	}
+
+	ec.counters.AddSave(uint(postID), -1)
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Post removed from saved",
+	})
+}
+
+func (ec *EngagementController) GetSavedPosts(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	before := c.QueryInt("before", 0)
+	if before < 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", "before must be a positive save ID")
+	}
+
+	limit := c.QueryInt("limit", defaultSavedLimit)
+	if limit <= 0 {
+		limit = defaultSavedLimit
+	}
+	if limit > maxSavedLimit {
+		limit = maxSavedLimit
+	}
+
+	saves, err := ec.db.FindSavedPosts(uint(claims.UserID), uint(before), limit+1)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load saved posts", err.Error())
+	}
+
+	hasMore := len(saves) > limit
+	if hasMore {
+		saves = saves[:limit]
+	}
+
+	var nextCursor uint
+	if hasMore {
+		nextCursor = saves[len(saves)-1].ID
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"saves":       saves,
+		"next_cursor": nextCursor,
+		"has_more":    hasMore,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the save logic -------------------------
+// --------------------------------------------------------------------------------------------------
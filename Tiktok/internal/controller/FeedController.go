@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"Tiktok/internal/database"
+	"Tiktok/internal/feed"
+	"Tiktok/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type FeedController struct {
+	db      database.Service // The database service to interact with the database.
+	weights feed.Weights     // Scoring weights for the "for you" ranking.
+}
+
+func NewFeedController(db database.Service) *FeedController {
+	return &FeedController{
+		db:      db,                  // Setting the provided database service.
+		weights: feed.DefaultWeights, // Using the default ranking weights.
+	}
+}
+
+type FeedItem struct {
+	database.PublicPost
+	LikeCount    int64    `json:"like_count"`
+	CommentCount int64    `json:"comment_count"`
+	Score        *float64 `json:"score,omitempty"`
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the following feed logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (fc *FeedController) FollowingFeed(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	cursor, err := feed.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", err.Error())
+	}
+	limit := feed.ClampLimit(c.QueryInt("limit", feed.DefaultLimit))
+
+	var beforeTime time.Time
+	var beforeID uint
+	if cursor != nil {
+		beforeTime, beforeID = cursor.At, cursor.ID
+	}
+
+	// Ask for one extra row so we know whether another page exists.
+	posts, err := fc.db.FindFollowingFeed(uint(claims.UserID), beforeTime, beforeID, limit+1)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load feed", err.Error())
+	}
+
+	hasMore := len(posts) > limit
+	if hasMore {
+		posts = posts[:limit]
+	}
+
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	engagement, err := fc.db.CountPostEngagement(ids)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load feed", err.Error())
+	}
+	items := feedItems(posts, engagement, nil)
+
+	var nextCursor string
+	if hasMore {
+		last := posts[len(posts)-1]
+		nextCursor = feed.Cursor{At: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"posts":       items,
+		"next_cursor": nextCursor,
+		"has_more":    hasMore,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the following feed logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the for you feed logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (fc *FeedController) ForYouFeed(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	cursor, err := feed.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", err.Error())
+	}
+	limit := feed.ClampLimit(c.QueryInt("limit", feed.DefaultLimit))
+
+	// Later pages are ranked against the clock of the first page so that
+	// recency decay does not shuffle posts the client has already seen.
+	now := time.Now()
+	if cursor != nil {
+		now = cursor.At
+	}
+
+	candidates, err := fc.db.FindFeedCandidates(uint(claims.UserID), now.Add(-feed.CandidateWindow), now, feed.CandidatePool)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load feed", err.Error())
+	}
+
+	ids := make([]uint, len(candidates))
+	for i, post := range candidates {
+		ids[i] = post.ID
+	}
+
+	engagement, err := fc.db.CountPostEngagement(ids)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load feed", err.Error())
+	}
+
+	history, err := fc.db.FindHashtagAffinity(uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load feed", err.Error())
+	}
+
+	pool := make([]feed.Candidate, len(candidates))
+	for i, post := range candidates {
+		tags := make([]string, len(post.Hashtags))
+		for j, tag := range post.Hashtags {
+			tags[j] = tag.Name
+		}
+		pool[i] = feed.Candidate{
+			PostID:    post.ID,
+			Likes:     engagement[post.ID].Likes,
+			Comments:  engagement[post.ID].Comments,
+			Views:     post.ViewCount,
+			Shares:    post.ShareCount,
+			Hashtags:  tags,
+			CreatedAt: post.CreatedAt,
+		}
+	}
+
+	ranked := fc.weights.Rank(pool, feed.NewAffinity(history), now)
+	page, hasMore := feed.Page(ranked, cursor, limit)
+
+	pageIDs := make([]uint, len(page))
+	scores := make(map[uint]float64, len(page))
+	for i, r := range page {
+		pageIDs[i] = r.PostID
+		scores[r.PostID] = r.Score
+	}
+
+	posts, err := fc.db.FindPostsByIds(pageIDs)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load feed", err.Error())
+	}
+
+	items := feedItems(posts, engagement, scores)
+
+	var nextCursor string
+	if hasMore {
+		last := page[len(page)-1]
+		nextCursor = feed.Cursor{At: now, Score: last.Score, ID: last.PostID}.Encode()
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"posts":       items,
+		"next_cursor": nextCursor,
+		"has_more":    hasMore,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the for you feed logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// feedItems attaches like and comment totals (and the ranking score when one
+// is given) to each post.
+func feedItems(posts []database.PublicPost, engagement map[uint]database.PostEngagement, scores map[uint]float64) []FeedItem {
+	items := make([]FeedItem, len(posts))
+	for i, post := range posts {
+		items[i] = FeedItem{
+			PublicPost:   post,
+			LikeCount:    engagement[post.ID].Likes,
+			CommentCount: engagement[post.ID].Comments,
+		}
+		if score, ok := scores[post.ID]; ok {
+			items[i].Score = &score
+		}
+	}
+	return items
+}
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"Tiktok/internal/database"
+	"Tiktok/internal/models"
 	"Tiktok/internal/utils"
+	"fmt"
 
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
@@ -31,6 +33,14 @@ func (fc *FollowController) FollowUser(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
 	}
 
+	if userToFollowID == claims.UserID {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "You cannot follow yourself", "")
+	}
+
+	if _, err := fc.db.FindUserById(uint(userToFollowID)); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "User not found", err.Error())
+	}
+
 	// Check if already following
 	existingFollow, err := fc.db.FindFollowByUsers(uint(claims.UserID), uint(userToFollowID))
 	if err == nil && existingFollow != nil {
@@ -54,6 +64,13 @@ func (fc *FollowController) FollowUser(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to follow user", err.Error())
 	}
 
+	notify(fc.db, database.Notification{
+		UserID:  uint(userToFollowID),
+		FromID:  uint(claims.UserID),
+		Type:    models.NotificationFollow,
+		Content: fmt.Sprintf("%s started following you", claims.Name),
+	})
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "User followed successfully",
 		"follow":  result,
@@ -0,0 +1,174 @@
+package controllers
+
+import (
+	"Tiktok/internal/database"
+	"Tiktok/internal/feed"
+	"Tiktok/internal/trending"
+	"Tiktok/internal/utils"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type HashtagController struct {
+	db database.Service // The database service to interact with the database.
+}
+
+func NewHashtagController(db database.Service) *HashtagController {
+	return &HashtagController{
+		db: db, // Setting the provided database service.
+	}
+}
+
+const (
+	defaultHashtagSearchLimit = 10
+	maxHashtagSearchLimit     = 25
+	defaultTrendingLimit      = 20
+	maxTrendingLimit          = 50
+)
+
+// hashtagName normalises a tag from the URL or query string: "%23Dance" and
+// "dance" refer to the same tag.
+func hashtagName(raw string) string {
+	if unescaped, err := url.PathUnescape(raw); err == nil {
+		raw = unescaped
+	}
+	return strings.TrimPrefix(strings.TrimSpace(raw), "#")
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the hashtag posts logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (hc *HashtagController) GetHashtagPosts(c *fiber.Ctx) error {
+	name := hashtagName(c.Params("name"))
+	if name == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Hashtag name is required", "")
+	}
+
+	cursor, err := feed.DecodeCursor(c.Query("cursor"))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", err.Error())
+	}
+	limit := feed.ClampLimit(c.QueryInt("limit", feed.DefaultLimit))
+
+	hashtag, err := hc.db.FindHashtagByName(name)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Hashtag not found", "")
+	}
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load hashtag", err.Error())
+	}
+
+	var beforeTime time.Time
+	var beforeID uint
+	if cursor != nil {
+		beforeTime, beforeID = cursor.At, cursor.ID
+	}
+
+	posts, err := hc.db.FindHashtagPosts(hashtag.ID, beforeTime, beforeID, limit+1)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load posts", err.Error())
+	}
+
+	hasMore := len(posts) > limit
+	if hasMore {
+		posts = posts[:limit]
+	}
+
+	ids := make([]uint, len(posts))
+	for i, post := range posts {
+		ids[i] = post.ID
+	}
+
+	engagement, err := hc.db.CountPostEngagement(ids)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load posts", err.Error())
+	}
+
+	var nextCursor string
+	if hasMore {
+		last := posts[len(posts)-1]
+		nextCursor = feed.Cursor{At: last.CreatedAt, ID: last.ID}.Encode()
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"hashtag":     fiber.Map{"id": hashtag.ID, "name": hashtag.Name},
+		"posts":       feedItems(posts, engagement, nil),
+		"next_cursor": nextCursor,
+		"has_more":    hasMore,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the hashtag posts logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the hashtag search logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (hc *HashtagController) SearchHashtags(c *fiber.Ctx) error {
+	prefix := hashtagName(c.Query("q"))
+	if prefix == "" {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Search query is required", "Pass the start of a hashtag as ?q=")
+	}
+
+	limit := c.QueryInt("limit", defaultHashtagSearchLimit)
+	if limit <= 0 || limit > maxHashtagSearchLimit {
+		limit = defaultHashtagSearchLimit
+	}
+
+	hashtags, err := hc.db.SearchHashtags(prefix, limit)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to search hashtags", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"hashtags": hashtags,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the hashtag search logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the trending hashtags logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (hc *HashtagController) TrendingHashtags(c *fiber.Ctx) error {
+	limit := c.QueryInt("limit", defaultTrendingLimit)
+	if limit <= 0 || limit > maxTrendingLimit {
+		limit = defaultTrendingLimit
+	}
+
+	now := time.Now()
+	split := now.Add(-trending.RecentWindow)
+
+	usages, err := hc.db.CountHashtagUsage(split.Add(-trending.BaselineWindow), split)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load trending hashtags", err.Error())
+	}
+
+	counts := make([]trending.Usage, len(usages))
+	for i, u := range usages {
+		counts[i] = trending.Usage{Name: u.Name, Recent: u.Recent, Previous: u.Previous}
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"hashtags": trending.Rank(counts, limit),
+		"window": fiber.Map{
+			"recent":   trending.RecentWindow.String(),
+			"baseline": trending.BaselineWindow.String(),
+		},
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the trending hashtags logic -------------------------
+// --------------------------------------------------------------------------------------------------
@@ -2,7 +2,9 @@ package controllers
 
 import (
 	"Tiktok/internal/database"
+	"Tiktok/internal/models"
 	"Tiktok/internal/utils"
+	"fmt"
 
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
@@ -44,6 +46,14 @@ func (lc *LikeController) LikeVideos(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", utils.FormatValidationErrors(err))
 	}
 
+	post, err := lc.db.FindPostById(uint(postID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Post not found", err.Error())
+	}
+	if !postVisibleTo(post, uint(claims.UserID)) {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Post not found", "")
+	}
+
 	like := database.Like{
 		UserID: uint(claims.UserID),
 		PostID: uint(postID),
@@ -67,6 +77,14 @@ func (lc *LikeController) LikeVideos(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to like video", err.Error())
 	}
 
+	notify(lc.db, database.Notification{
+		UserID:  post.UserID,
+		FromID:  uint(claims.UserID),
+		PostID:  &post.ID,
+		Type:    models.NotificationLike,
+		Content: fmt.Sprintf("%s liked your video", claims.Name),
+	})
+
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
 		"message": "Video liked successfully",
 		"like":    createdLike,
@@ -0,0 +1,265 @@
+package controllers
+
+import (
+	"Tiktok/internal/database"
+	"Tiktok/internal/livechat"
+	"Tiktok/internal/models"
+	"Tiktok/internal/utils"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"log"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+const (
+	maxChatMessageSize = 4 * 1024
+	maxChatCommentLen  = 255
+	maxGiftQuantity    = 99
+	chatMessageGap     = 500 * time.Millisecond // minimum time between two messages from one connection
+	chatPongWait       = 60 * time.Second
+	chatPingPeriod     = chatPongWait * 9 / 10
+	chatWriteWait      = 10 * time.Second
+)
+
+type LiveChatController struct {
+	db   database.Service   // The database service to interact with the database.
+	chat *livechat.Registry // The per-stream hubs connected viewers are attached to.
+}
+
+func NewLiveChatController(db database.Service, chat *livechat.Registry) *LiveChatController {
+	return &LiveChatController{
+		db:   db,   // Setting the provided database service.
+		chat: chat, // Setting the live chat registry.
+	}
+}
+
+// ChatMessage is what a client sends over the socket.
+type ChatMessage struct {
+	Type     string `json:"type"`
+	Text     string `json:"text"`
+	GiftID   uint   `json:"gift_id"`
+	Quantity int    `json:"quantity"`
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the live chat logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// Upgrade checks the request before the WebSocket handshake. Viewers can only
+// join a stream that is live; the host can also connect while backstage.
+func (lc *LiveChatController) Upgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return utils.SendErrorResponse(c, fiber.StatusUpgradeRequired, "WebSocket upgrade required", "")
+	}
+
+	streamID, err := c.ParamsInt("id")
+	if err != nil || streamID <= 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid stream ID", "Stream ID must be a positive number")
+	}
+
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	stream, err := lc.db.FindLiveStreamById(uint(streamID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Stream not found", err.Error())
+	}
+
+	isHost := stream.UserID == uint(claims.UserID)
+	switch {
+	case stream.Status == models.LiveStreamEnded:
+		return utils.SendErrorResponse(c, fiber.StatusGone, "Stream has ended", "")
+	case stream.Status != models.LiveStreamLive && !isHost:
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Stream is not live yet", "")
+	}
+
+	c.Locals("live_stream", stream)
+	return c.Next()
+}
+
+// Chat serves one viewer's connection: events from the stream's hub are
+// written out, and comments and gifts read in are stored and broadcast.
+func (lc *LiveChatController) Chat() fiber.Handler {
+	return websocket.New(func(conn *websocket.Conn) {
+		claims, _ := conn.Locals("user").(*utils.Claims)
+		stream, _ := conn.Locals("live_stream").(*models.LiveStream)
+		if claims == nil || stream == nil {
+			conn.Close()
+			return
+		}
+
+		sender := livechat.User{ID: uint(claims.UserID), Name: claims.Name, Avatar: claims.Avatar}
+		client := livechat.NewClient(sender.ID, stream.UserID == sender.ID)
+		hub := lc.chat.Join(stream.ID, client)
+
+		// The connection is recycled once this handler returns, so wait for
+		// the writer; leaving the hub closes client.Send, which stops it.
+		written := make(chan struct{})
+		go func() {
+			writeChat(conn, client)
+			close(written)
+		}()
+		defer func() {
+			hub.Leave(client)
+			<-written
+		}()
+
+		conn.SetReadLimit(maxChatMessageSize)
+		conn.SetReadDeadline(time.Now().Add(chatPongWait))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(chatPongWait))
+		})
+
+		var last time.Time
+		for {
+			_, data, err := conn.ReadMessage()
+			if err != nil {
+				if websocket.IsUnexpectedCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					log.Printf("live chat %d: read failed for user %d: %v", stream.ID, sender.ID, err)
+				}
+				return
+			}
+
+			if time.Since(last) < chatMessageGap {
+				hub.SendTo(client, livechat.Event{Type: livechat.EventError, Message: "You are sending messages too fast"})
+				continue
+			}
+			last = time.Now()
+
+			var msg ChatMessage
+			if err := json.Unmarshal(data, &msg); err != nil {
+				hub.SendTo(client, livechat.Event{Type: livechat.EventError, Message: "Invalid message"})
+				continue
+			}
+
+			event, problem := lc.handleChatMessage(stream.ID, sender, msg)
+			if problem != "" {
+				hub.SendTo(client, livechat.Event{Type: livechat.EventError, Message: problem})
+				continue
+			}
+			hub.Broadcast(event)
+		}
+	})
+}
+
+// handleChatMessage validates and stores an incoming message and returns the
+// event to broadcast, or a message explaining why it was rejected.
+func (lc *LiveChatController) handleChatMessage(streamID uint, sender livechat.User, msg ChatMessage) (livechat.Event, string) {
+	switch msg.Type {
+	case livechat.EventComment:
+		text := strings.TrimSpace(msg.Text)
+		if text == "" {
+			return livechat.Event{}, "Comment cannot be empty"
+		}
+		if utf8.RuneCountInString(text) > maxChatCommentLen {
+			return livechat.Event{}, "Comment is too long"
+		}
+		text = html.EscapeString(text)
+
+		comment, err := lc.db.CreateLiveStreamComment(database.LiveStreamComment{
+			UserID:       sender.ID,
+			LiveStreamID: streamID,
+			Text:         text,
+		})
+		if err != nil {
+			log.Printf("live chat %d: failed to save comment: %v", streamID, err)
+			return livechat.Event{}, "Failed to send comment"
+		}
+
+		return livechat.Event{Type: livechat.EventComment, User: &sender, Text: comment.Text, At: comment.CreatedAt}, ""
+
+	case livechat.EventGift:
+		if msg.Quantity == 0 {
+			msg.Quantity = 1
+		}
+		if msg.Quantity < 0 || msg.Quantity > maxGiftQuantity {
+			return livechat.Event{}, "Gift quantity must be between 1 and 99"
+		}
+
+		gift, _, err := lc.db.SendGift(database.GiftPurchase{
+			SenderID:     sender.ID,
+			LiveStreamID: streamID,
+			GiftItemID:   msg.GiftID,
+			Quantity:     msg.Quantity,
+		})
+		switch {
+		case errors.Is(err, database.ErrInsufficientCoins):
+			return livechat.Event{}, "Not enough coins"
+		case errors.Is(err, database.ErrGiftToSelf):
+			return livechat.Event{}, "You cannot send gifts to your own stream"
+		case errors.Is(err, database.ErrStreamNotLive):
+			return livechat.Event{}, "Stream is not live"
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			return livechat.Event{}, "Gift not found"
+		case err != nil:
+			log.Printf("live chat %d: failed to send gift: %v", streamID, err)
+			return livechat.Event{}, "Failed to send gift"
+		}
+
+		notify(lc.db, database.Notification{
+			UserID:  gift.RecipientID,
+			FromID:  sender.ID,
+			Type:    models.NotificationGift,
+			Content: fmt.Sprintf("%s sent you %d x %s", sender.Name, gift.Quantity, gift.GiftType),
+		})
+
+		return livechat.Event{
+			Type: livechat.EventGift,
+			User: &sender,
+			Gift: &livechat.Gift{
+				ID:       gift.GiftItemID,
+				Name:     gift.GiftType,
+				Icon:     gift.GiftItem.Icon,
+				Quantity: gift.Quantity,
+				Coins:    gift.Coins,
+			},
+			At: gift.CreatedAt,
+		}, ""
+	}
+
+	return livechat.Event{}, "Unknown message type"
+}
+
+// writeChat forwards hub events to the socket until the hub drops the client,
+// then closes the connection so the read loop exits too.
+func writeChat(conn *websocket.Conn, client *livechat.Client) {
+	ticker := time.NewTicker(chatPingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case payload, ok := <-client.Send:
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+				return
+			}
+			if err := conn.WriteMessage(websocket.TextMessage, payload); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(chatWriteWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the live chat logic -------------------------
+// --------------------------------------------------------------------------------------------------
@@ -2,77 +2,249 @@ package controllers
 
 import (
 	"Tiktok/internal/database"
+	"Tiktok/internal/livechat"
+	"Tiktok/internal/livestream"
 	"Tiktok/internal/models"
 	"Tiktok/internal/utils"
-	"context"
-	"strconv"
+	"errors"
+	"html"
+	"log"
+	"strings"
+	"time"
 
-	"github.com/GetStream/getstream-go"
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type LiveStreamController struct {
-	db           database.Service
-	validate     *validator.Validate
-	streamClient *getstream.Client
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+	provider livestream.Provider // The video backend the streams run on.
+	chat     *livechat.Registry  // Live chat hubs, closed when a stream ends.
 }
 
+func NewLiveStreamController(db database.Service, provider livestream.Provider, chat *livechat.Registry) *LiveStreamController {
+	return &LiveStreamController{
+		db:       db,              // Setting the provided database service.
+		validate: validator.New(), // Initializing a new validator instance.
+		provider: provider,        // Setting the streaming provider.
+		chat:     chat,            // Setting the live chat registry.
+	}
+}
+
+const (
+	defaultLiveStreamLimit = 20
+	maxLiveStreamLimit     = 50
+)
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the start stream logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
 type StartStreamRequest struct {
-	Title       string `json:"title" validate:"required"`
-	Description string `json:"description" validate:"required"`
+	Title       string `json:"title" validate:"required,max=255"`
+	Description string `json:"description" validate:"required,max=1000"`
 }
 
 func (lc *LiveStreamController) StartStream(c *fiber.Ctx) error {
-	ctx := context.Background()
-	claims := c.Locals("user").(*utils.Claims)
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
 
 	var req StartStreamRequest
 	if err := c.BodyParser(&req); err != nil {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err.Error())
 	}
 
-	userID := strconv.Itoa(claims.UserID)
+	if err := lc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", utils.FormatValidationErrors(err))
+	}
+
+	// One stream at a time per host.
+	active, err := lc.db.FindActiveLiveStreamByUser(uint(claims.UserID))
+	if err == nil {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "You already have an active live stream", fiber.Map{
+			"stream_id": active.ID,
+			"status":    active.Status,
+		})
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to check active streams", err.Error())
+	}
+
+	title := html.EscapeString(strings.TrimSpace(req.Title))
+	description := html.EscapeString(strings.TrimSpace(req.Description))
 	callID := uuid.New().String()
 
-	// Create livestream call
-	call := lc.streamClient.Video().Call("livestream", callID)
-
-	response, err := call.GetOrCreate(ctx, &getstream.GetOrCreateCallRequest{
-		Data: &getstream.CallRequest{
-			CreatedByID: getstream.PtrTo(userID),
-			Members: []getstream.MemberRequest{
-				{UserID: userID, Role: getstream.PtrTo("host")},
-			},
-			Custom: map[string]interface{}{
-				"title":       req.Title,
-				"description": req.Description,
-			},
-		},
+	ingest, err := lc.provider.Create(c.Context(), livestream.StartRequest{
+		CallID:      callID,
+		HostID:      uint(claims.UserID),
+		HostName:    claims.Name,
+		Title:       title,
+		Description: description,
 	})
-
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to create stream", err.Error())
+		return utils.SendErrorResponse(c, fiber.StatusBadGateway, "Failed to create stream", err.Error())
 	}
 
-	// Save to database
 	stream := &models.LiveStream{
-		UserID:      claims.UserID,
-		Title:       req.Title,
-		Description: req.Description,
+		UserID:      uint(claims.UserID),
+		Title:       title,
+		Description: description,
 		CallID:      callID,
-		Status:      "backstage",
-		StreamKey:   response.StreamKey,
-		RtmpUrl:     response.RtmpUrl,
+		Status:      models.LiveStreamBackstage,
+		StreamKey:   ingest.StreamKey,
+		RtmpUrl:     ingest.RtmpURL,
 	}
 
-	if err := lc.db.CreateLiveStream(ctx, stream); err != nil {
+	if err := lc.db.CreateLiveStream(stream); err != nil {
+		// Don't leave an orphaned call running on the provider.
+		if endErr := lc.provider.End(c.Context(), callID); endErr != nil {
+			log.Printf("failed to end orphaned call %s: %v", callID, endErr)
+		}
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save stream", err.Error())
 	}
 
-	return c.JSON(fiber.Map{
-		"status": "success",
-		"data":   stream,
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "Stream created, you are backstage",
+		"stream":  stream,
+		"ingest":  ingest,
 	})
 }
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the start stream logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the go live / end logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (lc *LiveStreamController) GoLive(c *fiber.Ctx) error {
+	return lc.transition(c, models.LiveStreamLive)
+}
+
+func (lc *LiveStreamController) EndStream(c *fiber.Ctx) error {
+	return lc.transition(c, models.LiveStreamEnded)
+}
+
+// transition moves the host's stream to the given status, first on the
+// provider and then in the database.
+func (lc *LiveStreamController) transition(c *fiber.Ctx, to string) error {
+	streamID, err := c.ParamsInt("id")
+	if err != nil || streamID <= 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid stream ID", "Stream ID must be a positive number")
+	}
+
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	stream, err := lc.db.FindLiveStreamById(uint(streamID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Stream not found", err.Error())
+	}
+
+	if stream.UserID != uint(claims.UserID) {
+		return utils.SendErrorResponse(c, fiber.StatusForbidden, "Not authorized to manage this stream", "")
+	}
+
+	if !livestream.CanTransition(stream.Status, to) {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "Invalid stream status change", fiber.Map{
+			"from": stream.Status,
+			"to":   to,
+		})
+	}
+
+	switch to {
+	case models.LiveStreamLive:
+		err = lc.provider.GoLive(c.Context(), stream.CallID)
+	case models.LiveStreamEnded:
+		err = lc.provider.End(c.Context(), stream.CallID)
+	}
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadGateway, "Streaming provider rejected the request", err.Error())
+	}
+
+	updated, err := lc.db.UpdateLiveStreamStatus(stream.ID, stream.Status, to, time.Now())
+	if errors.Is(err, database.ErrStatusConflict) {
+		return utils.SendErrorResponse(c, fiber.StatusConflict, "Stream status changed, please retry", "")
+	}
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to update stream", err.Error())
+	}
+
+	if to == models.LiveStreamEnded {
+		lc.chat.End(stream.ID)
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": "Stream is now " + to,
+		"stream":  updated,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the go live / end logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the list streams logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (lc *LiveStreamController) ListLiveStreams(c *fiber.Ctx) error {
+	before := c.QueryInt("before", 0)
+	if before < 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", "before must be a positive stream ID")
+	}
+
+	limit := c.QueryInt("limit", defaultLiveStreamLimit)
+	if limit <= 0 || limit > maxLiveStreamLimit {
+		limit = defaultLiveStreamLimit
+	}
+
+	streams, err := lc.db.FindLiveStreams(uint(before), limit+1)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load live streams", err.Error())
+	}
+
+	hasMore := len(streams) > limit
+	if hasMore {
+		streams = streams[:limit]
+	}
+
+	var nextCursor uint
+	if hasMore {
+		nextCursor = streams[len(streams)-1].ID
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"streams":     streams,
+		"next_cursor": nextCursor,
+		"has_more":    hasMore,
+	})
+}
+
+func (lc *LiveStreamController) GetLiveStream(c *fiber.Ctx) error {
+	streamID, err := c.ParamsInt("id")
+	if err != nil || streamID <= 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid stream ID", "Stream ID must be a positive number")
+	}
+
+	stream, err := lc.db.FindLiveStreamById(uint(streamID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Stream not found", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"stream": stream,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the list streams logic -------------------------
+// --------------------------------------------------------------------------------------------------
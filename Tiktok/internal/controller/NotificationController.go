@@ -0,0 +1,162 @@
+package controllers
+
+import (
+	"Tiktok/internal/database"
+	"Tiktok/internal/utils"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type NotificationController struct {
+	db database.Service // The database service to interact with the database.
+}
+
+func NewNotificationController(db database.Service) *NotificationController {
+	return &NotificationController{
+		db: db, // Setting the provided database service.
+	}
+}
+
+const (
+	defaultNotificationLimit = 20
+	maxNotificationLimit     = 50
+	maxNotificationContent   = 255
+)
+
+// notify records a notification for the recipient. Failing to notify must
+// not undo the like, comment or follow that triggered it, so errors are only
+// logged.
+func notify(db database.Service, notification database.Notification) {
+	if runes := []rune(notification.Content); len(runes) > maxNotificationContent {
+		notification.Content = string(runes[:maxNotificationContent-1]) + "…"
+	}
+
+	if _, err := db.CreateNotification(notification); err != nil {
+		log.Printf("failed to create %s notification for user %d: %v", notification.Type, notification.UserID, err)
+	}
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the list notifications logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (nc *NotificationController) GetNotifications(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	before := c.QueryInt("before", 0)
+	if before < 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", "before must be a positive notification ID")
+	}
+
+	limit := c.QueryInt("limit", defaultNotificationLimit)
+	if limit <= 0 {
+		limit = defaultNotificationLimit
+	}
+	if limit > maxNotificationLimit {
+		limit = maxNotificationLimit
+	}
+
+	notifications, err := nc.db.FindUserNotifications(uint(claims.UserID), uint(before), limit+1)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load notifications", err.Error())
+	}
+
+	hasMore := len(notifications) > limit
+	if hasMore {
+		notifications = notifications[:limit]
+	}
+
+	var nextCursor uint
+	if hasMore {
+		nextCursor = notifications[len(notifications)-1].ID
+	}
+
+	unread, err := nc.db.CountUnreadNotifications(uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load notifications", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"notifications": notifications,
+		"unread_count":  unread,
+		"next_cursor":   nextCursor,
+		"has_more":      hasMore,
+	})
+}
+
+func (nc *NotificationController) GetUnreadCount(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	unread, err := nc.db.CountUnreadNotifications(uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to count notifications", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"unread_count": unread,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the list notifications logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the mark read logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (nc *NotificationController) MarkRead(c *fiber.Ctx) error {
+	notificationID, err := c.ParamsInt("id")
+	if err != nil || notificationID <= 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid notification ID", "Notification ID must be a positive number")
+	}
+
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	notification, err := nc.db.MarkNotificationRead(uint(claims.UserID), uint(notificationID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Notification not found", "")
+	}
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to mark notification as read", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":      "Notification marked as read",
+		"notification": notification,
+	})
+}
+
+func (nc *NotificationController) MarkAllRead(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	updated, err := nc.db.MarkAllNotificationsRead(uint(claims.UserID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to mark notifications as read", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message": fmt.Sprintf("%d notifications marked as read", updated),
+		"updated": updated,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the mark read logic -------------------------
+// --------------------------------------------------------------------------------------------------
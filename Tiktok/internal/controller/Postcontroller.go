@@ -1,28 +1,38 @@
 package controllers
 
 import (
-	"Tiktok/internal/config"
 	"Tiktok/internal/database"
+	"Tiktok/internal/media"
+	"Tiktok/internal/models"
+	"Tiktok/internal/storage"
 	"Tiktok/internal/utils"
 	"context"
+	"errors"
 	"html"
+	"io"
+	"log"
 	"mime/multipart"
+	"os"
+	"path/filepath"
 	"strings"
-	"time"
 
 	"github.com/go-playground/validator"
 	"github.com/gofiber/fiber/v2"
 )
 
 type PostController struct {
-	db       database.Service    // The database service to interact with the database.
-	validate *validator.Validate // Validator instance for validating user inputs.
+	db        database.Service    // The database service to interact with the database.
+	validate  *validator.Validate // Validator instance for validating user inputs.
+	storage   storage.Storage     // Where processed videos and thumbnails live.
+	processor *media.Processor    // Background queue that processes uploaded videos.
 }
 
-func NewPostController(db database.Service) *PostController {
+func NewPostController(db database.Service, files storage.Storage, processor *media.Processor) *PostController {
 	return &PostController{
-		db:       db,              // Setting the provided database service.
-		validate: validator.New(), // Initializing a new validator instance.
+		db:        db,              // Setting the provided database service.
+		validate:  validator.New(), // Initializing a new validator instance.
+		storage:   files,           // Setting the media storage backend.
+		processor: processor,       // Setting the video processing queue.
 	}
 }
 
@@ -66,95 +76,84 @@ func (pc *PostController) CreatePost(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Video file too large", "Maximum file size is 100MB")
 	}
 
-	uploadChan := make(chan struct {
-		url      string
-		duration float64
-		err      error
-	})
+	if err := utils.ValidateVideoFile(file); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid video file", err.Error())
+	}
 
-	cld, err := config.InitCloudinary()
+	// The processor owns the temp file once the job is queued; until then
+	// we clean it up ourselves.
+	tempPath, err := saveUpload(file)
 	if err != nil {
-		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to initialize Cloudinary", err.Error())
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to store upload", err.Error())
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
-	defer cancel()
-
-	go func(file *multipart.FileHeader) {
-		var result struct {
-			url      string
-			duration float64
-			err      error
-		}
-
-		if err := utils.ValidateVideoFile(file); err != nil {
-			result.err = err
-			uploadChan <- result
-			return
-		}
-
-		fileHeader, err := file.Open()
-		if err != nil {
-			result.err = err
-			uploadChan <- result
-			return
-		}
-		defer fileHeader.Close()
-
-		url, err := utils.UploadToCloudinary(cld, ctx, fileHeader)
-		if err != nil {
-			result.err = err
-			uploadChan <- result
-			return
+	hashtagSlice := strings.Split(req.Hashtags, ",")
+	var cleanedHashtags []string
+	for _, tag := range hashtagSlice {
+		tag = strings.TrimSpace(tag)
+		tag = strings.TrimPrefix(tag, "#")
+		if tag != "" {
+			cleanedHashtags = append(cleanedHashtags, tag)
 		}
+	}
 
-		result.url = url
-		uploadChan <- result
-	}(file)
+	post := database.Post{
+		UserID:    database.User{ID: uint(claims.UserID)},
+		Text:      html.EscapeString(strings.TrimSpace(req.Text)),
+		IsPrivate: req.IsPrivate,
+		Music:     html.EscapeString(strings.TrimSpace(req.Music)),
+		Location:  html.EscapeString(strings.TrimSpace(req.Location)),
+		Status:    models.PostStatusProcessing,
+	}
 
-	select {
-	case result := <-uploadChan:
-		if result.err != nil {
-			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Upload failed", result.err.Error())
-		}
+	createdPost, err := pc.db.CreatePost(post, cleanedHashtags)
+	if err != nil {
+		os.Remove(tempPath)
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save post", err.Error())
+	}
 
-		sanitizedText := html.EscapeString(strings.TrimSpace(req.Text))
-		sanitizedLocation := html.EscapeString(strings.TrimSpace(req.Location))
-		sanitizedMusic := html.EscapeString(strings.TrimSpace(req.Music))
-
-		hashtagSlice := strings.Split(req.Hashtags, ",")
-		var cleanedHashtags []string
-		for _, tag := range hashtagSlice {
-			tag = strings.TrimSpace(tag)
-			tag = strings.TrimPrefix(tag, "#")
-			if tag != "" {
-				cleanedHashtags = append(cleanedHashtags, tag)
-			}
+	err = pc.processor.Enqueue(media.Job{
+		PostID:      createdPost.ID,
+		Path:        tempPath,
+		ContentType: file.Header.Get("Content-Type"),
+	})
+	if err != nil {
+		os.Remove(tempPath)
+		if delErr := pc.db.DeletePost(createdPost.ID); delErr != nil {
+			log.Printf("failed to remove unprocessed post %d: %v", createdPost.ID, delErr)
 		}
+		return utils.SendErrorResponse(c, fiber.StatusServiceUnavailable, "Video processing is busy, please try again shortly", err.Error())
+	}
 
-		post := database.Post{
-			UserID:    database.User{ID: uint(claims.UserID)},
-			Text:      sanitizedText,
-			Video:     result.url,
-			IsPrivate: req.IsPrivate,
-			Music:     sanitizedMusic,
-			Location:  sanitizedLocation,
-			Duration:  result.duration,
-		}
+	return c.Status(fiber.StatusAccepted).JSON(fiber.Map{
+		"message": "Post created, your video is being processed",
+		"post":    createdPost,
+	})
+}
 
-		createdPost, err := pc.db.CreatePost(post, cleanedHashtags)
-		if err != nil {
-			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to save post", err.Error())
-		}
+// saveUpload copies the uploaded video to a temp file for the processor.
+func saveUpload(file *multipart.FileHeader) (string, error) {
+	src, err := file.Open()
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
 
-		return c.Status(fiber.StatusCreated).JSON(fiber.Map{
-			"message": "Post created successfully",
-			"post":    createdPost,
-		})
+	dst, err := os.CreateTemp("", "tiktok-upload-*"+strings.ToLower(filepath.Ext(file.Filename)))
+	if err != nil {
+		return "", err
+	}
 
-	case <-ctx.Done():
-		return utils.SendErrorResponse(c, fiber.StatusGatewayTimeout, "Upload timeout", "Request took too long to process")
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", err
 	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	return dst.Name(), nil
 }
 
 // --------------------------------------------------------------------------------------------------
@@ -266,6 +265,15 @@ func (pc *PostController) DeletePost(c *fiber.Ctx) error {
 		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to delete post", err.Error())
 	}
 
+	for _, url := range []string{existingPost.Video, existingPost.Thumbnail} {
+		if url == "" {
+			continue
+		}
+		if err := pc.storage.Delete(context.Background(), url); err != nil && !errors.Is(err, storage.ErrNotOwned) {
+			log.Printf("failed to delete %s for post %d: %v", url, postID, err)
+		}
+	}
+
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"message": "Post deleted successfully",
 	})
@@ -274,3 +282,45 @@ func (pc *PostController) DeletePost(c *fiber.Ctx) error {
 // --------------------------------------------------------------------------------------------------
 //------------------------------ these is the End of the delete logic -------------------------
 // --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the processing status logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (pc *PostController) GetPostStatus(c *fiber.Ctx) error {
+	postID, err := c.ParamsInt("id")
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid post ID", err.Error())
+	}
+
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	post, err := pc.db.FindPostById(uint(postID))
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Post not found", err.Error())
+	}
+
+	// Only the author gets to see posts that are private or not yet ready.
+	if post.UserID != uint(claims.UserID) && (post.IsPrivate || post.Status != models.PostStatusReady) {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "Post not found", "")
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"id":        post.ID,
+		"status":    post.Status,
+		"error":     post.ProcessingError,
+		"video":     post.Video,
+		"thumbnail": post.Thumbnail,
+		"duration":  post.Duration,
+		"width":     post.Width,
+		"height":    post.Height,
+		"codec":     post.Codec,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the processing status logic -------------------------
+// --------------------------------------------------------------------------------------------------
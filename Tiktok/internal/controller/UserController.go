@@ -0,0 +1,165 @@
+package controllers
+
+import (
+	"Tiktok/internal/database"
+	"Tiktok/internal/utils"
+	"errors"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+type UserController struct {
+	db database.Service // The database service to interact with the database.
+}
+
+func NewUserController(db database.Service) *UserController {
+	return &UserController{
+		db: db, // Setting the provided database service.
+	}
+}
+
+const (
+	defaultProfilePostLimit = 18 // a 3-column grid of six rows
+	maxProfilePostLimit     = 60
+	defaultFollowListLimit  = 20
+	maxFollowListLimit      = 50
+)
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the profile logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (uc *UserController) GetProfile(c *fiber.Ctx) error {
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID", "User ID must be a positive number")
+	}
+
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	before := c.QueryInt("before", 0)
+	if before < 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", "before must be a positive post ID")
+	}
+
+	limit := c.QueryInt("limit", defaultProfilePostLimit)
+	if limit <= 0 {
+		limit = defaultProfilePostLimit
+	}
+	if limit > maxProfilePostLimit {
+		limit = maxProfilePostLimit
+	}
+
+	profile, err := uc.db.FindUserProfile(uint(userID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "User not found", "")
+	}
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load profile", err.Error())
+	}
+
+	isSelf := profile.ID == uint(claims.UserID)
+
+	posts, err := uc.db.FindUserPosts(profile.ID, isSelf, uint(before), limit+1)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load posts", err.Error())
+	}
+
+	hasMore := len(posts) > limit
+	if hasMore {
+		posts = posts[:limit]
+	}
+
+	var nextCursor uint
+	if hasMore {
+		nextCursor = posts[len(posts)-1].ID
+	}
+
+	isFollowing := false
+	if !isSelf {
+		_, err := uc.db.FindFollowByUsers(uint(claims.UserID), profile.ID)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load profile", err.Error())
+		}
+		isFollowing = err == nil
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"user":         profile,
+		"is_self":      isSelf,
+		"is_following": isFollowing,
+		"posts":        posts,
+		"next_cursor":  nextCursor,
+		"has_more":     hasMore,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the profile logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the followers / following logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (uc *UserController) GetFollowers(c *fiber.Ctx) error {
+	return uc.followList(c, uc.db.FindFollowers)
+}
+
+func (uc *UserController) GetFollowing(c *fiber.Ctx) error {
+	return uc.followList(c, uc.db.FindFollowing)
+}
+
+// followList pages through one side of the :id user's follow graph.
+func (uc *UserController) followList(c *fiber.Ctx, find func(userID, beforeID uint, limit int) ([]database.UserSummary, error)) error {
+	userID, err := c.ParamsInt("id")
+	if err != nil || userID <= 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid user ID", "User ID must be a positive number")
+	}
+
+	before := c.QueryInt("before", 0)
+	if before < 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", "before must be a positive cursor")
+	}
+
+	limit := c.QueryInt("limit", defaultFollowListLimit)
+	if limit <= 0 {
+		limit = defaultFollowListLimit
+	}
+	if limit > maxFollowListLimit {
+		limit = maxFollowListLimit
+	}
+
+	if _, err := uc.db.FindUserById(uint(userID)); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "User not found", err.Error())
+	}
+
+	users, err := find(uint(userID), uint(before), limit+1)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load users", err.Error())
+	}
+
+	hasMore := len(users) > limit
+	if hasMore {
+		users = users[:limit]
+	}
+
+	var nextCursor uint
+	if hasMore {
+		nextCursor = users[len(users)-1].FollowID
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"users":       users,
+		"next_cursor": nextCursor,
+		"has_more":    hasMore,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the followers / following logic -------------------------
+// --------------------------------------------------------------------------------------------------
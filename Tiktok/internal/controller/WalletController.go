@@ -0,0 +1,200 @@
+package controllers
+
+import (
+	"Tiktok/internal/database"
+	"Tiktok/internal/utils"
+	"Tiktok/internal/wallet"
+	"errors"
+	"fmt"
+
+	"github.com/go-playground/validator"
+	"github.com/gofiber/fiber/v2"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type WalletController struct {
+	db       database.Service    // The database service to interact with the database.
+	validate *validator.Validate // Validator instance for validating user inputs.
+	payments wallet.Payments     // The provider that charges for coin top-ups.
+}
+
+func NewWalletController(db database.Service, payments wallet.Payments) *WalletController {
+	return &WalletController{
+		db:       db,              // Setting the provided database service.
+		validate: validator.New(), // Initializing a new validator instance.
+		payments: payments,        // Setting the payment provider.
+	}
+}
+
+const (
+	defaultTransactionLimit = 20
+	maxTransactionLimit     = 50
+)
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the balance logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (wc *WalletController) GetWallet(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	balance, err := wc.db.FindWalletBalance(uint(claims.UserID))
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return utils.SendErrorResponse(c, fiber.StatusNotFound, "User not found", "")
+	}
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load wallet", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"balance": balance,
+	})
+}
+
+func (wc *WalletController) GetTransactions(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	before := c.QueryInt("before", 0)
+	if before < 0 {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid cursor", "before must be a positive transaction ID")
+	}
+
+	limit := c.QueryInt("limit", defaultTransactionLimit)
+	if limit <= 0 {
+		limit = defaultTransactionLimit
+	}
+	if limit > maxTransactionLimit {
+		limit = maxTransactionLimit
+	}
+
+	transactions, err := wc.db.FindCoinTransactions(uint(claims.UserID), uint(before), limit+1)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load transactions", err.Error())
+	}
+
+	hasMore := len(transactions) > limit
+	if hasMore {
+		transactions = transactions[:limit]
+	}
+
+	var nextCursor uint
+	if hasMore {
+		nextCursor = transactions[len(transactions)-1].ID
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"transactions": transactions,
+		"next_cursor":  nextCursor,
+		"has_more":     hasMore,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the balance logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the top-up logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+type TopUpRequest struct {
+	PackageID     string `json:"package_id" validate:"required"`
+	PaymentSource string `json:"payment_source" validate:"required,max=255"`
+	// Clients should send the same key when retrying a purchase so it is
+	// only charged and credited once.
+	IdempotencyKey string `json:"idempotency_key" validate:"max=255"`
+}
+
+func (wc *WalletController) GetPackages(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"packages": wallet.Packages,
+	})
+}
+
+func (wc *WalletController) TopUp(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*utils.Claims)
+	if !ok || claims == nil {
+		return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
+	}
+
+	var req TopUpRequest
+	if err := c.BodyParser(&req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Invalid request", err.Error())
+	}
+
+	if err := wc.validate.Struct(req); err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Validation failed", utils.FormatValidationErrors(err))
+	}
+
+	pack, err := wallet.FindPackage(req.PackageID)
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadRequest, "Unknown coin package", req.PackageID)
+	}
+
+	idempotencyKey := req.IdempotencyKey
+	if idempotencyKey == "" {
+		idempotencyKey = uuid.New().String()
+	}
+
+	receipt, err := wc.payments.Charge(c.Context(), wallet.Charge{
+		UserID:   uint(claims.UserID),
+		Amount:   pack.Price,
+		Currency: pack.Currency,
+		Source:   req.PaymentSource,
+		// Scope the key to the user so clients can't collide with each other.
+		IdempotencyKey: fmt.Sprintf("%d:%s", claims.UserID, idempotencyKey),
+		Description:    fmt.Sprintf("%d coins", pack.Coins),
+	})
+	if errors.Is(err, wallet.ErrPaymentDeclined) {
+		return utils.SendErrorResponse(c, fiber.StatusPaymentRequired, "Payment declined", "")
+	}
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusBadGateway, "Payment failed", err.Error())
+	}
+
+	entry, err := wc.db.TopUpCoins(uint(claims.UserID), pack.Coins, receipt.Reference)
+	if err != nil {
+		// The card was charged, so this needs reconciling by hand against
+		// the provider's records using the reference.
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to credit coins", fiber.Map{
+			"payment_reference": receipt.Reference,
+			"error":             err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"message":     "Coins added",
+		"transaction": entry,
+		"balance":     entry.BalanceAfter,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the top-up logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the start of the gift catalogue logic -------------------------
+// --------------------------------------------------------------------------------------------------
+
+func (wc *WalletController) GetGifts(c *fiber.Ctx) error {
+	items, err := wc.db.FindGiftItems()
+	if err != nil {
+		return utils.SendErrorResponse(c, fiber.StatusInternalServerError, "Failed to load gifts", err.Error())
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"gifts": items,
+	})
+}
+
+// --------------------------------------------------------------------------------------------------
+//------------------------------ these is the End of the gift catalogue logic -------------------------
+// --------------------------------------------------------------------------------------------------
@@ -0,0 +1,21 @@
+package database
+
+// ReconcileFollowCounts recomputes every user's follower and following
+// counters from the follows table and returns how many users were corrected.
+// CreateFollow and DeleteFollow keep the counters in step; this catches drift
+// from anything that bypasses them, such as deleted accounts.
+func (s *service) ReconcileFollowCounts() (int64, error) {
+	result := s.db.Exec(`
+		UPDATE users u
+		SET follower_count = c.followers,
+			following_count = c.following
+		FROM (
+			SELECT users.id,
+				(SELECT COUNT(*) FROM follows f WHERE f.following_id = users.id) AS followers,
+				(SELECT COUNT(*) FROM follows f WHERE f.follower_id = users.id) AS following
+			FROM users
+		) c
+		WHERE c.id = u.id
+			AND (u.follower_count <> c.followers OR u.following_count <> c.following)`)
+	return result.RowsAffected, result.Error
+}
@@ -3,6 +3,7 @@ package database
 import (
 	"Tiktok/internal/models"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -13,6 +14,7 @@ import (
 	_ "github.com/joho/godotenv/autoload"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"gorm.io/gorm/logger"
 )
 
@@ -42,6 +44,7 @@ type Post struct {
 	IsPrivate bool
 	Music     string
 	Location  string
+	Status    string
 	CreatedAt time.Time
 	UpdatedAt time.Time
 	Comments  []models.Comment
@@ -81,11 +84,14 @@ type Follow struct {
 type Gift struct {
 	ID           uint
 	UserID       uint
+	RecipientID  uint
 	LivestreamID uint
+	GiftItemID   uint
 	User         User
 	LiveStream   LiveStream
 	GiftType     string
-	Amount       float64
+	Quantity     int
+	Coins        int64
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
@@ -130,6 +136,7 @@ type Notification struct {
 	ID        uint
 	UserID    uint
 	FromID    uint
+	PostID    *uint
 	User      User
 	From      User
 	Type      string
@@ -158,6 +165,48 @@ type Service interface {
 	FindFollowByUsers(followerID, followingID uint) (*models.Follow, error)
 	FindLikeByUserAndPost(userID, postID uint) (*models.Like, error)
 	FindCommentById(id uint) (*models.Comment, error)
+	FindPostsByIds(ids []uint) ([]PublicPost, error)
+	// --------------------Feed-----------------------------
+	FindFollowingFeed(userID uint, beforeTime time.Time, beforeID uint, limit int) ([]PublicPost, error)
+	FindFeedCandidates(viewerID uint, since, until time.Time, limit int) ([]models.Post, error)
+	FindHashtagAffinity(userID uint) (map[string]int64, error)
+	CountPostEngagement(postIDs []uint) (map[uint]PostEngagement, error)
+	// --------------------Live streams---------------------
+	FindLiveStreamById(id uint) (*models.LiveStream, error)
+	FindActiveLiveStreamByUser(userID uint) (*models.LiveStream, error)
+	FindLiveStreams(beforeID uint, limit int) ([]models.LiveStream, error)
+	UpdateLiveStreamStatus(id uint, from, to string, at time.Time) (*models.LiveStream, error)
+	UpdateLiveStreamViewerCount(id uint, count uint) error
+	CreateLiveStreamComment(comment LiveStreamComment) (*models.LiveStreamComment, error)
+	// --------------------Wallet---------------------------
+	FindWalletBalance(userID uint) (int64, error)
+	FindCoinTransactions(userID, beforeID uint, limit int) ([]models.CoinTransaction, error)
+	TopUpCoins(userID uint, coins int64, paymentRef string) (*models.CoinTransaction, error)
+	FindGiftItems() ([]models.GiftItem, error)
+	SendGift(purchase GiftPurchase) (*models.Gift, int64, error)
+	// --------------------Engagement-----------------------
+	RecordPostView(userID, postID uint, window time.Duration, at time.Time) (bool, error)
+	SavePost(userID, postID uint) (bool, error)
+	UnsavePost(userID, postID uint) (bool, error)
+	FindSavedPosts(userID, beforeID uint, limit int) ([]SavedPost, error)
+	ApplyPostCounters(deltas map[uint]PostCounters) error
+	// --------------------Profiles-------------------------
+	FindUserProfile(id uint) (*UserProfile, error)
+	FindUserPosts(userID uint, includePrivate bool, beforeID uint, limit int) ([]PublicPost, error)
+	FindFollowers(userID, beforeID uint, limit int) ([]UserSummary, error)
+	FindFollowing(userID, beforeID uint, limit int) ([]UserSummary, error)
+	UpdateUserProfile(userID uint, update ProfileUpdate) (*models.User, error)
+	// --------------------Hashtags-------------------------
+	FindHashtagByName(name string) (*models.Hashtag, error)
+	FindHashtagPosts(hashtagID uint, beforeTime time.Time, beforeID uint, limit int) ([]PublicPost, error)
+	SearchHashtags(prefix string, limit int) ([]HashtagSummary, error)
+	CountHashtagUsage(since, split time.Time) ([]HashtagUsage, error)
+	// --------------------Notifications--------------------
+	CreateNotification(notification Notification) (*models.Notification, error)
+	FindUserNotifications(userID, beforeID uint, limit int) ([]models.Notification, error)
+	CountUnreadNotifications(userID uint) (int64, error)
+	MarkNotificationRead(userID, notificationID uint) (*models.Notification, error)
+	MarkAllNotificationsRead(userID uint) (int64, error)
 
 	//-----------------------Create ------------------------
 	CreateUser(user User) (*models.User, error)
@@ -177,8 +226,11 @@ type Service interface {
 	// --------------------Update---------------------------
 	UpdateUser(user models.User) (*models.User, error)
 	UpdatePost(post Post, hashtags []string) (*models.Post, error)
+	MarkPostReady(postID uint, media PostMedia) error
+	MarkPostFailed(postID uint, reason string) error
 
 	UpdateComment(comment models.Comment) (*models.Comment, error)
+	ReconcileFollowCounts() (int64, error)
 }
 
 // --------------------------------------------------------------
@@ -263,13 +315,28 @@ func (s *service) FindCommentById(id uint) (*models.Comment, error) {
 // ----------------- Create ---------------
 // ----------------------------------------
 
+// CreateFollow stores the follow and bumps both users' counters in the same
+// transaction so the cached counts never drift from the follows table.
 func (s *service) CreateFollow(follow Follow) (*models.Follow, error) {
 	newFollow := &models.Follow{
 		FollowerID:  follow.FollowerID,
 		FollowingID: follow.FollowingID,
 	}
 
-	if err := s.db.Create(newFollow).Error; err != nil {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newFollow).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", follow.FollowingID).
+			UpdateColumn("follower_count", gorm.Expr("follower_count + 1")).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", follow.FollowerID).
+			UpdateColumn("following_count", gorm.Expr("following_count + 1")).Error
+	})
+	if err != nil {
 		return nil, err
 	}
 	return newFollow, nil
@@ -298,10 +365,20 @@ func (s *service) CreatePost(post Post, hashtags []string) (*models.Post, error)
 	tx := s.db.Begin()
 
 	// 📦 Create the new posts
+	status := post.Status
+	if status == "" {
+		status = models.PostStatusReady
+	}
+
 	newPost := &models.Post{
 		UserID:    post.UserID.ID,
 		Text:      post.Text,
 		Video:     post.Video,
+		Duration:  post.Duration,
+		IsPrivate: post.IsPrivate,
+		Music:     post.Music,
+		Location:  post.Location,
+		Status:    status,
 		CreatedAt: time.Now(),
 		UpdatedAt: time.Now(),
 	}
@@ -312,7 +389,7 @@ func (s *service) CreatePost(post Post, hashtags []string) (*models.Post, error)
 	}
 
 	// 🏷️ Handle the hashtags
-	for _, tag := range hashtags {
+	for _, tag := range normalizeHashtags(hashtags) {
 		var hashtag models.Hashtag
 
 		// Try to find existing hashtag or create new one
@@ -416,8 +493,31 @@ func (s *service) DeleteUser(id string) (*models.User, error) {
 	return &user, nil
 }
 
+// DeleteFollow removes the follow and decrements both users' counters in the
+// same transaction. Deleting a follow that is already gone is a no-op.
 func (s *service) DeleteFollow(followID uint) error {
-	return s.db.Delete(&models.Follow{}, followID).Error
+	return s.db.Transaction(func(tx *gorm.DB) error {
+		var follow models.Follow
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&follow, followID).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&follow).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", follow.FollowingID).
+			UpdateColumn("follower_count", gorm.Expr("GREATEST(follower_count - 1, 0)")).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.User{}).Where("id = ?", follow.FollowerID).
+			UpdateColumn("following_count", gorm.Expr("GREATEST(following_count - 1, 0)")).Error
+	})
 }
 
 func (s *service) DeletePost(postID uint) error {
@@ -434,6 +534,16 @@ func (s *service) DeletePost(postID uint) error {
 		return err
 	}
 
+	if err := tx.Where("post_id = ?", postID).Delete(&models.Save{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Where("post_id = ?", postID).Delete(&models.PostView{}).Error; err != nil {
+		tx.Rollback()
+		return err
+	}
+
 	// Remove hashtag associations
 	if err := tx.Model(&models.Post{ID: postID}).Association("Hashtags").Clear(); err != nil {
 		tx.Rollback()
@@ -500,7 +610,7 @@ func (s *service) UpdatePost(post Post, hashtags []string) (*models.Post, error)
 	}
 
 	// Add new hashtags
-	for _, tagName := range hashtags {
+	for _, tagName := range normalizeHashtags(hashtags) {
 		var hashtag models.Hashtag
 
 		if err := tx.Where(models.Hashtag{Name: tagName}).FirstOrCreate(&hashtag).Error; err != nil {
@@ -559,12 +669,61 @@ func New() Service {
 }
 
 func AutoMigrate(db *gorm.DB) error {
-	return db.AutoMigrate(
+	if err := dedupeFollows(db); err != nil {
+		return err
+	}
+	if err := mergeHashtagCase(db); err != nil {
+		return err
+	}
+
+	err := db.AutoMigrate(
 		&models.User{},
 		&models.Like{},
 		&models.Post{},
 		&models.Comment{},
+		&models.Follow{},
+		&models.Hashtag{},
+		&models.Notification{},
+		&models.LiveStream{},
+		&models.LiveStreamComment{},
+		&models.GiftItem{},
+		&models.Gift{},
+		&models.CoinTransaction{},
+		&models.Save{},
+		&models.PostView{},
 	)
+	if err != nil {
+		return err
+	}
+	if err := OpenWalletLedgers(db); err != nil {
+		return err
+	}
+	return SeedGiftItems(db)
+}
+
+// dedupeFollows removes duplicate follows, keeping the lowest id of each
+// pair, so the unique idx_follows_pair index can be created on databases from
+// before it existed. The follow counters of the affected users are recounted.
+func dedupeFollows(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Follow{}) {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Exec(`
+			DELETE FROM follows dup USING follows kept
+			WHERE dup.follower_id = kept.follower_id
+				AND dup.following_id = kept.following_id
+				AND dup.id > kept.id`)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error
+		}
+
+		return tx.Exec(`
+			UPDATE users SET
+				follower_count = (SELECT COUNT(*) FROM follows WHERE follows.following_id = users.id),
+				following_count = (SELECT COUNT(*) FROM follows WHERE follows.follower_id = users.id)`).Error
+	})
 }
 
 // Health checks the health of the database connection by pinging the database.
@@ -37,20 +37,24 @@ func mustStartPostgresContainer() (func(context.Context) error, error) {
 	password = dbPwd
 	username = dbUser
 
+	terminate := func(ctx context.Context) error {
+		return dbContainer.Terminate(ctx)
+	}
+
 	dbHost, err := dbContainer.Host(context.Background())
 	if err != nil {
-		return dbContainer.Terminate, err
+		return terminate, err
 	}
 
 	dbPort, err := dbContainer.MappedPort(context.Background(), "5432/tcp")
 	if err != nil {
-		return dbContainer.Terminate, err
+		return terminate, err
 	}
 
 	host = dbHost
 	port = dbPort.Port()
 
-	return dbContainer.Terminate, err
+	return terminate, err
 }
 
 func TestMain(m *testing.M) {
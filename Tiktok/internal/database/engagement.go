@@ -0,0 +1,136 @@
+package database
+
+import (
+	"Tiktok/internal/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"gorm.io/gorm/clause"
+)
+
+// PostCounters is a change to a post's view, share and save counters.
+type PostCounters struct {
+	Views  int64
+	Shares int64
+	Saves  int64
+}
+
+// SavedPost is a save as listed to its owner. Its Post shadows the embedded
+// models.Save.Post when encoded to JSON, so the author is a PostAuthor.
+type SavedPost struct {
+	models.Save
+	Post PublicPost
+}
+
+// ---------------------------------------------------------
+// ----------------- Views ---------------------------------
+// ---------------------------------------------------------
+
+// RecordPostView notes that the user watched the post and reports whether the
+// view should be counted: it is the first view, or the previous counted one
+// is older than window.
+func (s *service) RecordPostView(userID, postID uint, window time.Duration, at time.Time) (bool, error) {
+	result := s.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "post_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"viewed_at": at}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			clause.Lt{Column: clause.Column{Table: "post_views", Name: "viewed_at"}, Value: at.Add(-window)},
+		}},
+	}).Create(&models.PostView{UserID: userID, PostID: postID, ViewedAt: at})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ---------------------------------------------------------
+// ----------------- Saves ---------------------------------
+// ---------------------------------------------------------
+
+// SavePost adds the post to the user's collection and reports whether it was
+// newly saved.
+func (s *service) SavePost(userID, postID uint) (bool, error) {
+	result := s.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.Save{UserID: userID, PostID: postID})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// UnsavePost removes the post from the user's collection and reports whether
+// it had been saved.
+func (s *service) UnsavePost(userID, postID uint) (bool, error) {
+	result := s.db.Where("user_id = ? AND post_id = ?", userID, postID).Delete(&models.Save{})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// FindSavedPosts lists the user's saves, most recently saved first, with the
+// posts preloaded. Posts that have since gone private or failed processing
+// are left out. When beforeID is non-zero only saves with a smaller id are
+// returned.
+func (s *service) FindSavedPosts(userID, beforeID uint, limit int) ([]SavedPost, error) {
+	var saves []models.Save
+
+	query := s.db.Joins("JOIN posts ON posts.id = saves.post_id").
+		Where("saves.user_id = ?", userID).
+		Where("posts.status = ?", models.PostStatusReady).
+		Where("posts.is_private = ? OR posts.user_id = ?", false, userID)
+	if beforeID != 0 {
+		query = query.Where("saves.id < ?", beforeID)
+	}
+
+	err := query.Preload("Post").
+		Preload("Post.User", preloadAuthor).
+		Preload("Post.Hashtags").
+		Order("saves.id DESC").
+		Limit(limit).
+		Find(&saves).Error
+	if err != nil {
+		return nil, err
+	}
+
+	posts := make([]models.Post, len(saves))
+	for i, save := range saves {
+		posts[i] = save.Post
+	}
+
+	saved := make([]SavedPost, len(saves))
+	for i, post := range publicPosts(posts) {
+		saved[i] = SavedPost{Save: saves[i], Post: post}
+	}
+	return saved, nil
+}
+
+// ---------------------------------------------------------
+// ----------------- Counters ------------------------------
+// ---------------------------------------------------------
+
+// ApplyPostCounters adds a batch of counter changes in a single UPDATE.
+// Counters never drop below zero.
+func (s *service) ApplyPostCounters(deltas map[uint]PostCounters) error {
+	if len(deltas) == 0 {
+		return nil
+	}
+
+	rows := make([]string, 0, len(deltas))
+	args := make([]interface{}, 0, len(deltas)*4)
+	for postID, d := range deltas {
+		rows = append(rows, "(?::bigint, ?::bigint, ?::bigint, ?::bigint)")
+		args = append(args, postID, d.Views, d.Shares, d.Saves)
+	}
+
+	query := fmt.Sprintf(`
+		UPDATE posts p
+		SET view_count = GREATEST(p.view_count + v.views, 0),
+			share_count = GREATEST(p.share_count + v.shares, 0),
+			save_count = GREATEST(p.save_count + v.saves, 0)
+		FROM (VALUES %s) AS v(id, views, shares, saves)
+		WHERE p.id = v.id`, strings.Join(rows, ", "))
+
+	return s.db.Exec(query, args...).Error
+}
@@ -0,0 +1,202 @@
+package database
+
+import (
+	"Tiktok/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostEngagement holds the like and comment totals for a post.
+type PostEngagement struct {
+	PostID   uint  `json:"-"`
+	Likes    int64 `json:"like_count"`
+	Comments int64 `json:"comment_count"`
+}
+
+// PostAuthor is the public part of a post's author. The field names match
+// models.User so clients read the same keys, but private fields such as the
+// email address are never loaded or sent.
+type PostAuthor struct {
+	ID         uint
+	Name       string
+	Avatar     string
+	IsVerified bool
+}
+
+// PublicPost is a post as listed to other users. Its User shadows the
+// embedded models.Post.User when encoded to JSON.
+type PublicPost struct {
+	models.Post
+	User PostAuthor
+}
+
+// preloadAuthor limits a "User" preload to the columns of PostAuthor.
+func preloadAuthor(db *gorm.DB) *gorm.DB {
+	return db.Select("id", "name", "avatar", "is_verified")
+}
+
+func publicPosts(posts []models.Post) []PublicPost {
+	public := make([]PublicPost, len(posts))
+	for i, post := range posts {
+		public[i] = PublicPost{
+			Post: post,
+			User: PostAuthor{
+				ID:         post.User.ID,
+				Name:       post.User.Name,
+				Avatar:     post.User.Avatar,
+				IsVerified: post.User.IsVerified,
+			},
+		}
+	}
+	return public
+}
+
+// ---------------------------------------------------------
+// ----------------- Feed ----------------------------------
+// ---------------------------------------------------------
+
+// FindFollowingFeed returns public, processed posts from accounts the user
+// follows, newest first. When beforeID is non-zero only posts strictly older
+// than the (beforeTime, beforeID) position are returned.
+func (s *service) FindFollowingFeed(userID uint, beforeTime time.Time, beforeID uint, limit int) ([]PublicPost, error) {
+	var posts []models.Post
+
+	query := s.db.Preload("User", preloadAuthor).
+		Preload("Hashtags").
+		Where("is_private = ? AND status = ?", false, models.PostStatusReady).
+		Where("user_id IN (?)", s.db.Model(&models.Follow{}).Select("following_id").Where("follower_id = ?", userID))
+
+	if beforeID != 0 {
+		query = query.Where("(created_at < ?) OR (created_at = ? AND id < ?)", beforeTime, beforeTime, beforeID)
+	}
+
+	err := query.Order("created_at DESC").Order("id DESC").Limit(limit).Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	return publicPosts(posts), nil
+}
+
+// FindFeedCandidates returns public, processed posts created in [since, until]
+// that are not the viewer's own, with their hashtags loaded, as the pool the
+// ranked feed scores. Bounding the pool by until keeps posts created after the
+// first page out of later pages ranked against the same clock.
+func (s *service) FindFeedCandidates(viewerID uint, since, until time.Time, limit int) ([]models.Post, error) {
+	var posts []models.Post
+
+	err := s.db.Preload("Hashtags").
+		Where("is_private = ? AND status = ?", false, models.PostStatusReady).
+		Where("user_id <> ?", viewerID).
+		Where("created_at >= ? AND created_at <= ?", since, until).
+		Order("created_at DESC").
+		Limit(limit).
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	return posts, nil
+}
+
+// CountPostEngagement returns like and comment totals keyed by post id. Posts
+// with no engagement are still present with zero counts.
+func (s *service) CountPostEngagement(postIDs []uint) (map[uint]PostEngagement, error) {
+	counts := make(map[uint]PostEngagement, len(postIDs))
+	if len(postIDs) == 0 {
+		return counts, nil
+	}
+	for _, id := range postIDs {
+		counts[id] = PostEngagement{PostID: id}
+	}
+
+	var likes []struct {
+		PostID uint
+		Total  int64
+	}
+	if err := s.db.Model(&models.Like{}).
+		Select("post_id, COUNT(*) AS total").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&likes).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range likes {
+		entry := counts[row.PostID]
+		entry.Likes = row.Total
+		counts[row.PostID] = entry
+	}
+
+	var comments []struct {
+		PostID uint
+		Total  int64
+	}
+	if err := s.db.Model(&models.Comment{}).
+		Select("post_id, COUNT(*) AS total").
+		Where("post_id IN ?", postIDs).
+		Group("post_id").
+		Scan(&comments).Error; err != nil {
+		return nil, err
+	}
+	for _, row := range comments {
+		entry := counts[row.PostID]
+		entry.Comments = row.Total
+		counts[row.PostID] = entry
+	}
+
+	return counts, nil
+}
+
+// FindHashtagAffinity counts how often the user has liked or commented on
+// posts carrying each hashtag.
+func (s *service) FindHashtagAffinity(userID uint) (map[string]int64, error) {
+	var rows []struct {
+		Name  string
+		Total int64
+	}
+
+	err := s.db.Raw(`
+		SELECT h.name, COUNT(*) AS total
+		FROM hashtags h
+		JOIN post_hashtags ph ON ph.hashtag_id = h.id
+		JOIN (
+			SELECT post_id FROM likes WHERE user_id = ?
+			UNION ALL
+			SELECT post_id FROM comments WHERE user_id = ?
+		) interactions ON interactions.post_id = ph.post_id
+		GROUP BY h.name`, userID, userID).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	affinity := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		affinity[row.Name] = row.Total
+	}
+	return affinity, nil
+}
+
+// FindPostsByIds loads posts with their author and hashtags, returned in the
+// same order as ids. Ids that no longer exist are skipped.
+func (s *service) FindPostsByIds(ids []uint) ([]PublicPost, error) {
+	if len(ids) == 0 {
+		return []PublicPost{}, nil
+	}
+
+	var found []models.Post
+	if err := s.db.Preload("User", preloadAuthor).Preload("Hashtags").Where("id IN ?", ids).Find(&found).Error; err != nil {
+		return nil, err
+	}
+
+	byID := make(map[uint]models.Post, len(found))
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+
+	posts := make([]models.Post, 0, len(ids))
+	for _, id := range ids {
+		if post, ok := byID[id]; ok {
+			posts = append(posts, post)
+		}
+	}
+	return publicPosts(posts), nil
+}
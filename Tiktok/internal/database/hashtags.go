@@ -0,0 +1,150 @@
+package database
+
+import (
+	"Tiktok/internal/models"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// HashtagSummary is a hashtag with the number of visible posts using it.
+type HashtagSummary struct {
+	ID        uint   `json:"id"`
+	Name      string `json:"name"`
+	PostCount int64  `json:"post_count"`
+}
+
+// HashtagUsage counts posts using a tag before and after a split point.
+type HashtagUsage struct {
+	Name     string
+	Recent   int64
+	Previous int64
+}
+
+// ---------------------------------------------------------
+// ----------------- Hashtags ------------------------------
+// ---------------------------------------------------------
+
+// normalizeHashtags lowercases tag names and drops empty and repeated ones,
+// so "Go" and "go" are stored as the same hashtag.
+func normalizeHashtags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// duplicateHashtags selects every hashtag whose lowercased name is also used
+// by a hashtag with a lower id, together with the id of that first hashtag.
+const duplicateHashtags = `
+	SELECT h.id, first.id AS keep_id
+	FROM hashtags h
+	JOIN (SELECT LOWER(name) AS name, MIN(id) AS id FROM hashtags GROUP BY LOWER(name)) first
+		ON first.name = LOWER(h.name) AND first.id <> h.id`
+
+// mergeHashtagCase backfills hashtags stored before names were lowercased.
+// Posts tagged with a case variant are moved to the first hashtag of that
+// name, the variants are deleted and the remaining names are lowercased.
+func mergeHashtagCase(db *gorm.DB) error {
+	if !db.Migrator().HasTable(&models.Hashtag{}) || !db.Migrator().HasTable("post_hashtags") {
+		return nil
+	}
+
+	return db.Transaction(func(tx *gorm.DB) error {
+		statements := []string{
+			`INSERT INTO post_hashtags (post_id, hashtag_id)
+				SELECT DISTINCT ph.post_id, dup.keep_id
+				FROM post_hashtags ph JOIN (` + duplicateHashtags + `) dup ON dup.id = ph.hashtag_id
+				ON CONFLICT DO NOTHING`,
+			`DELETE FROM post_hashtags WHERE hashtag_id IN (SELECT id FROM (` + duplicateHashtags + `) dup)`,
+			`DELETE FROM hashtags WHERE id IN (SELECT id FROM (` + duplicateHashtags + `) dup)`,
+			`UPDATE hashtags SET name = LOWER(name) WHERE name <> LOWER(name)`,
+		}
+		for _, statement := range statements {
+			if err := tx.Exec(statement).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// FindHashtagByName looks a tag up case-insensitively. Names are stored
+// lowercased, see normalizeHashtags.
+func (s *service) FindHashtagByName(name string) (*models.Hashtag, error) {
+	var hashtag models.Hashtag
+	err := s.db.Where("name = ?", strings.ToLower(name)).First(&hashtag).Error
+	if err != nil {
+		return nil, err
+	}
+	return &hashtag, nil
+}
+
+// FindHashtagPosts returns public, processed posts carrying the tag, newest
+// first, paged the same way as the following feed.
+func (s *service) FindHashtagPosts(hashtagID uint, beforeTime time.Time, beforeID uint, limit int) ([]PublicPost, error) {
+	var posts []models.Post
+
+	query := s.db.Preload("User", preloadAuthor).
+		Preload("Hashtags").
+		Where("is_private = ? AND status = ?", false, models.PostStatusReady).
+		Where("id IN (?)", s.db.Table("post_hashtags").Select("post_id").Where("hashtag_id = ?", hashtagID))
+
+	if beforeID != 0 {
+		query = query.Where("(created_at < ?) OR (created_at = ? AND id < ?)", beforeTime, beforeTime, beforeID)
+	}
+
+	if err := query.Order("created_at DESC").Order("id DESC").Limit(limit).Find(&posts).Error; err != nil {
+		return nil, err
+	}
+	return publicPosts(posts), nil
+}
+
+// SearchHashtags autocompletes tag names by prefix, most used first.
+func (s *service) SearchHashtags(prefix string, limit int) ([]HashtagSummary, error) {
+	escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(strings.ToLower(prefix))
+
+	var results []HashtagSummary
+	err := s.db.Table("hashtags h").
+		Select("h.id, h.name, COUNT(p.id) AS post_count").
+		Joins("LEFT JOIN post_hashtags ph ON ph.hashtag_id = h.id").
+		Joins("LEFT JOIN posts p ON p.id = ph.post_id AND p.is_private = ? AND p.status = ?", false, models.PostStatusReady).
+		Where("LOWER(h.name) LIKE ?", escaped+"%").
+		Group("h.id, h.name").
+		Order("post_count DESC").
+		Order("h.name").
+		Limit(limit).
+		Scan(&results).Error
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// CountHashtagUsage counts, per tag, the visible posts created in
+// [since, split) as Previous and from split onwards as Recent.
+func (s *service) CountHashtagUsage(since, split time.Time) ([]HashtagUsage, error) {
+	var usages []HashtagUsage
+	err := s.db.Table("hashtags h").
+		Select(`h.name,
+			COUNT(*) FILTER (WHERE p.created_at >= ?) AS recent,
+			COUNT(*) FILTER (WHERE p.created_at < ?) AS previous`, split, split).
+		Joins("JOIN post_hashtags ph ON ph.hashtag_id = h.id").
+		Joins("JOIN posts p ON p.id = ph.post_id").
+		Where("p.is_private = ? AND p.status = ?", false, models.PostStatusReady).
+		Where("p.created_at >= ?", since).
+		Group("h.name").
+		Scan(&usages).Error
+	if err != nil {
+		return nil, err
+	}
+	return usages, nil
+}
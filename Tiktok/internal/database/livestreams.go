@@ -0,0 +1,115 @@
+package database
+
+import (
+	"Tiktok/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrStatusConflict is returned when a live stream is no longer in the status
+// a transition expected, e.g. it was ended from another request.
+var ErrStatusConflict = errors.New("live stream status changed concurrently")
+
+// ---------------------------------------------------------
+// ----------------- Live streams --------------------------
+// ---------------------------------------------------------
+
+func (s *service) CreateLiveStream(stream *models.LiveStream) error {
+	return s.db.Create(stream).Error
+}
+
+// FindLiveStreamById loads the stream with only the public columns of its
+// host, since streams are shown to every viewer.
+func (s *service) FindLiveStreamById(id uint) (*models.LiveStream, error) {
+	var stream models.LiveStream
+	if err := s.db.Preload("User", preloadAuthor).Where("id = ?", id).First(&stream).Error; err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
+
+// FindActiveLiveStreamByUser returns the user's backstage or live stream.
+func (s *service) FindActiveLiveStreamByUser(userID uint) (*models.LiveStream, error) {
+	var stream models.LiveStream
+	err := s.db.Where("user_id = ? AND status IN ?", userID, []string{models.LiveStreamBackstage, models.LiveStreamLive}).
+		Order("id DESC").
+		First(&stream).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stream, nil
+}
+
+// FindLiveStreams lists streams that are currently live, newest first. When
+// beforeID is non-zero only streams with a smaller id are returned.
+func (s *service) FindLiveStreams(beforeID uint, limit int) ([]models.LiveStream, error) {
+	var streams []models.LiveStream
+
+	query := s.db.Preload("User", preloadAuthor).Where("status = ?", models.LiveStreamLive)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Find(&streams).Error; err != nil {
+		return nil, err
+	}
+	return streams, nil
+}
+
+// UpdateLiveStreamStatus moves a stream from one status to another, stamping
+// StartedAt when it goes live and EndedAt when it ends. The update only
+// applies if the stream is still in the from status; otherwise
+// ErrStatusConflict is returned.
+func (s *service) UpdateLiveStreamStatus(id uint, from, to string, at time.Time) (*models.LiveStream, error) {
+	updates := map[string]interface{}{
+		"status":     to,
+		"updated_at": at,
+	}
+	switch to {
+	case models.LiveStreamLive:
+		updates["started_at"] = at
+	case models.LiveStreamEnded:
+		updates["ended_at"] = at
+		updates["viewer_count"] = 0
+	}
+
+	result := s.db.Model(&models.LiveStream{}).Where("id = ? AND status = ?", id, from).Updates(updates)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		if _, err := s.FindLiveStreamById(id); errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, err
+		}
+		return nil, ErrStatusConflict
+	}
+
+	return s.FindLiveStreamById(id)
+}
+
+// UpdateLiveStreamViewerCount stores the number of connected viewers. Streams
+// that have already ended keep their zero count.
+func (s *service) UpdateLiveStreamViewerCount(id uint, count uint) error {
+	return s.db.Model(&models.LiveStream{}).
+		Where("id = ? AND status <> ?", id, models.LiveStreamEnded).
+		UpdateColumn("viewer_count", count).Error
+}
+
+// ---------------------------------------------------------
+// ----------------- Live chat -----------------------------
+// ---------------------------------------------------------
+
+func (s *service) CreateLiveStreamComment(comment LiveStreamComment) (*models.LiveStreamComment, error) {
+	newComment := &models.LiveStreamComment{
+		UserID:       comment.UserID,
+		LiveStreamID: comment.LiveStreamID,
+		Text:         comment.Text,
+	}
+
+	if err := s.db.Create(newComment).Error; err != nil {
+		return nil, err
+	}
+	return newComment, nil
+}
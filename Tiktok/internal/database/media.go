@@ -0,0 +1,55 @@
+package database
+
+import (
+	"Tiktok/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// PostMedia is what the processing pipeline learned about an uploaded video.
+type PostMedia struct {
+	Video     string
+	Thumbnail string
+	Duration  float64
+	Width     int
+	Height    int
+	Codec     string
+}
+
+// MarkPostReady stores the processed media and makes the post visible. It
+// returns gorm.ErrRecordNotFound if the post was deleted while processing.
+func (s *service) MarkPostReady(postID uint, media PostMedia) error {
+	result := s.db.Model(&models.Post{}).Where("id = ?", postID).Updates(map[string]interface{}{
+		"video":            media.Video,
+		"thumbnail":        media.Thumbnail,
+		"duration":         media.Duration,
+		"width":            media.Width,
+		"height":           media.Height,
+		"codec":            media.Codec,
+		"status":           models.PostStatusReady,
+		"processing_error": "",
+		"updated_at":       time.Now(),
+	})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// MarkPostFailed records why processing failed. The reason is truncated to
+// fit the column.
+func (s *service) MarkPostFailed(postID uint, reason string) error {
+	if runes := []rune(reason); len(runes) > 255 {
+		reason = string(runes[:255])
+	}
+
+	return s.db.Model(&models.Post{}).Where("id = ?", postID).Updates(map[string]interface{}{
+		"status":           models.PostStatusFailed,
+		"processing_error": reason,
+		"updated_at":       time.Now(),
+	}).Error
+}
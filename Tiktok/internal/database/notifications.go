@@ -0,0 +1,90 @@
+package database
+
+import (
+	"Tiktok/internal/models"
+	"time"
+)
+
+// ---------------------------------------------------------
+// ----------------- Notifications -------------------------
+// ---------------------------------------------------------
+
+// CreateNotification stores a notification for its recipient. Users are never
+// notified about their own activity, in which case nil is returned.
+func (s *service) CreateNotification(notification Notification) (*models.Notification, error) {
+	if notification.UserID == notification.FromID {
+		return nil, nil
+	}
+
+	newNotification := &models.Notification{
+		UserID:  notification.UserID,
+		FromID:  notification.FromID,
+		PostID:  notification.PostID,
+		Type:    notification.Type,
+		Content: notification.Content,
+	}
+
+	if err := s.db.Create(newNotification).Error; err != nil {
+		return nil, err
+	}
+	return newNotification, nil
+}
+
+// FindUserNotifications returns the user's notifications newest first, with
+// the sender's public profile loaded. When beforeID is non-zero only older
+// notifications are returned.
+func (s *service) FindUserNotifications(userID, beforeID uint, limit int) ([]models.Notification, error) {
+	var notifications []models.Notification
+
+	query := s.db.Preload("From", preloadAuthor).Where("user_id = ?", userID)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Find(&notifications).Error; err != nil {
+		return nil, err
+	}
+	return notifications, nil
+}
+
+func (s *service) CountUnreadNotifications(userID uint) (int64, error) {
+	var count int64
+	err := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read = ?", userID, false).
+		Count(&count).Error
+	return count, err
+}
+
+// MarkNotificationRead flags a single notification as read. It returns
+// gorm.ErrRecordNotFound when the notification does not belong to the user.
+func (s *service) MarkNotificationRead(userID, notificationID uint) (*models.Notification, error) {
+	var notification models.Notification
+	if err := s.db.Where("id = ? AND user_id = ?", notificationID, userID).First(&notification).Error; err != nil {
+		return nil, err
+	}
+
+	if notification.Read {
+		return &notification, nil
+	}
+
+	err := s.db.Model(&notification).Updates(map[string]interface{}{
+		"read":       true,
+		"updated_at": time.Now(),
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+	return &notification, nil
+}
+
+// MarkAllNotificationsRead flags every unread notification of the user as read
+// and reports how many were changed.
+func (s *service) MarkAllNotificationsRead(userID uint) (int64, error) {
+	result := s.db.Model(&models.Notification{}).
+		Where("user_id = ? AND read = ?", userID, false).
+		Updates(map[string]interface{}{
+			"read":       true,
+			"updated_at": time.Now(),
+		})
+	return result.RowsAffected, result.Error
+}
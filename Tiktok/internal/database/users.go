@@ -0,0 +1,148 @@
+package database
+
+import (
+	"Tiktok/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// UserProfile is what anyone can see on a user's profile page.
+type UserProfile struct {
+	ID             uint      `json:"id"`
+	Name           string    `json:"name"`
+	Bio            string    `json:"bio"`
+	Avatar         string    `json:"avatar"`
+	IsVerified     bool      `json:"is_verified"`
+	FollowerCount  uint      `json:"follower_count"`
+	FollowingCount uint      `json:"following_count"`
+	PostCount      int64     `json:"post_count"`
+	LikeCount      int64     `json:"like_count"` // likes received across all posts
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// UserSummary is a user as shown in follower and following lists.
+type UserSummary struct {
+	ID         uint   `json:"id"`
+	Name       string `json:"name"`
+	Bio        string `json:"bio"`
+	Avatar     string `json:"avatar"`
+	IsVerified bool   `json:"is_verified"`
+	FollowID   uint   `json:"-"` // the follows row, used as the paging cursor
+}
+
+// ProfileUpdate lists the profile fields to change. Nil fields are left as
+// they are.
+type ProfileUpdate struct {
+	Name   *string
+	Bio    *string
+	Avatar *string
+}
+
+// ---------------------------------------------------------
+// ----------------- Profiles ------------------------------
+// ---------------------------------------------------------
+
+// FindUserProfile returns the user's public profile with post and like
+// totals. Only public, ready posts count towards PostCount.
+func (s *service) FindUserProfile(id uint) (*UserProfile, error) {
+	var profile UserProfile
+	result := s.db.Model(&models.User{}).
+		Select(`users.id, users.name, users.bio, users.avatar, users.is_verified,
+			users.follower_count, users.following_count, users.created_at,
+			(SELECT COUNT(*) FROM posts p
+				WHERE p.user_id = users.id AND p.is_private = false AND p.status = ?) AS post_count,
+			(SELECT COUNT(*) FROM likes l JOIN posts p ON p.id = l.post_id
+				WHERE p.user_id = users.id) AS like_count`, models.PostStatusReady).
+		Where("users.id = ?", id).
+		Scan(&profile)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &profile, nil
+}
+
+// FindUserPosts lists a user's ready posts, newest first. Private posts are
+// only included when includePrivate is set, i.e. for the owner. When beforeID
+// is non-zero only posts with a smaller id are returned.
+func (s *service) FindUserPosts(userID uint, includePrivate bool, beforeID uint, limit int) ([]PublicPost, error) {
+	var posts []models.Post
+
+	query := s.db.Where("user_id = ? AND status = ?", userID, models.PostStatusReady)
+	if !includePrivate {
+		query = query.Where("is_private = ?", false)
+	}
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	err := query.Preload("User", preloadAuthor).
+		Preload("Hashtags").
+		Order("id DESC").
+		Limit(limit).
+		Find(&posts).Error
+	if err != nil {
+		return nil, err
+	}
+	return publicPosts(posts), nil
+}
+
+// FindFollowers lists the users following userID, most recent first.
+func (s *service) FindFollowers(userID, beforeID uint, limit int) ([]UserSummary, error) {
+	return s.findFollowList("follows.follower_id", "follows.following_id", userID, beforeID, limit)
+}
+
+// FindFollowing lists the users userID follows, most recent first.
+func (s *service) FindFollowing(userID, beforeID uint, limit int) ([]UserSummary, error) {
+	return s.findFollowList("follows.following_id", "follows.follower_id", userID, beforeID, limit)
+}
+
+// findFollowList pages through follows where filterColumn is userID and
+// returns the users on the other side, found through listColumn. When
+// beforeID is non-zero only follows with a smaller id are returned.
+func (s *service) findFollowList(listColumn, filterColumn string, userID, beforeID uint, limit int) ([]UserSummary, error) {
+	var users []UserSummary
+
+	query := s.db.Table("follows").
+		Select("users.id, users.name, users.bio, users.avatar, users.is_verified, follows.id AS follow_id").
+		Joins("JOIN users ON users.id = "+listColumn).
+		Where(filterColumn+" = ?", userID)
+	if beforeID != 0 {
+		query = query.Where("follows.id < ?", beforeID)
+	}
+
+	if err := query.Order("follows.id DESC").Limit(limit).Scan(&users).Error; err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UpdateUserProfile writes only the given profile fields, so counters and
+// balances updated concurrently elsewhere are never overwritten.
+func (s *service) UpdateUserProfile(userID uint, update ProfileUpdate) (*models.User, error) {
+	updates := map[string]interface{}{}
+	if update.Name != nil {
+		updates["name"] = *update.Name
+	}
+	if update.Bio != nil {
+		updates["bio"] = *update.Bio
+	}
+	if update.Avatar != nil {
+		updates["avatar"] = *update.Avatar
+	}
+
+	if len(updates) > 0 {
+		result := s.db.Model(&models.User{}).Where("id = ?", userID).Updates(updates)
+		if result.Error != nil {
+			return nil, result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil, gorm.ErrRecordNotFound
+		}
+	}
+
+	return s.FindUserById(userID)
+}
@@ -0,0 +1,233 @@
+package database
+
+import (
+	"Tiktok/internal/models"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrInsufficientCoins = errors.New("not enough coins")
+	ErrGiftToSelf        = errors.New("cannot send a gift to yourself")
+	ErrStreamNotLive     = errors.New("live stream is not live")
+)
+
+// GiftPurchase is a viewer sending Quantity of a catalogue gift to the host
+// of a live stream.
+type GiftPurchase struct {
+	SenderID     uint
+	LiveStreamID uint
+	GiftItemID   uint
+	Quantity     int
+}
+
+// defaultGiftItems is the catalogue a fresh database starts with.
+var defaultGiftItems = []models.GiftItem{
+	{Name: "Rose", Price: 1, Active: true},
+	{Name: "Heart", Price: 5, Active: true},
+	{Name: "Donut", Price: 30, Active: true},
+	{Name: "Sunglasses", Price: 199, Active: true},
+	{Name: "Galaxy", Price: 1000, Active: true},
+	{Name: "Lion", Price: 29999, Active: true},
+}
+
+// SeedGiftItems adds any missing default gifts. Gifts that already exist are
+// left alone so price changes made in the database survive restarts.
+func SeedGiftItems(db *gorm.DB) error {
+	items := make([]models.GiftItem, len(defaultGiftItems))
+	copy(items, defaultGiftItems)
+	return db.Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "name"}}, DoNothing: true}).Create(&items).Error
+}
+
+// OpenWalletLedgers gives every user holding coins but no ledger rows an
+// opening balance row, so balances from before the ledger existed still equal
+// the sum of the user's ledger. Users that have ledger rows are skipped, which
+// makes it safe to run on every start.
+func OpenWalletLedgers(db *gorm.DB) error {
+	return db.Exec(`
+		INSERT INTO coin_transactions (user_id, kind, amount, balance_after, created_at)
+		SELECT users.id, ?, users.coins, users.coins, ?
+		FROM users
+		WHERE users.coins <> 0
+			AND NOT EXISTS (SELECT 1 FROM coin_transactions ct WHERE ct.user_id = users.id)`,
+		models.CoinOpeningBalance, time.Now()).Error
+}
+
+// ---------------------------------------------------------
+// ----------------- Wallet --------------------------------
+// ---------------------------------------------------------
+
+func (s *service) FindWalletBalance(userID uint) (int64, error) {
+	var user models.User
+	if err := s.db.Select("id", "coins").Where("id = ?", userID).First(&user).Error; err != nil {
+		return 0, err
+	}
+	return user.Coins, nil
+}
+
+// FindCoinTransactions lists a user's ledger, newest first. When beforeID is
+// non-zero only rows with a smaller id are returned.
+func (s *service) FindCoinTransactions(userID, beforeID uint, limit int) ([]models.CoinTransaction, error) {
+	var transactions []models.CoinTransaction
+
+	query := s.db.Where("user_id = ?", userID)
+	if beforeID != 0 {
+		query = query.Where("id < ?", beforeID)
+	}
+
+	if err := query.Order("id DESC").Limit(limit).Find(&transactions).Error; err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// TopUpCoins credits coins bought with the given payment. Crediting the same
+// payment reference again returns the original ledger row instead of paying
+// out twice.
+func (s *service) TopUpCoins(userID uint, coins int64, paymentRef string) (*models.CoinTransaction, error) {
+	if coins <= 0 {
+		return nil, errors.New("top-up amount must be positive")
+	}
+
+	var entry models.CoinTransaction
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("payment_ref = ?", paymentRef).First(&entry).Error
+		if err == nil {
+			if entry.UserID != userID {
+				return errors.New("payment reference belongs to another user")
+			}
+			return nil
+		}
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "coins").
+			Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+
+		balance := user.Coins + coins
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).UpdateColumn("coins", balance).Error; err != nil {
+			return err
+		}
+
+		entry = models.CoinTransaction{
+			UserID:       userID,
+			Kind:         models.CoinTopUp,
+			Amount:       coins,
+			BalanceAfter: balance,
+			PaymentRef:   &paymentRef,
+		}
+		return tx.Create(&entry).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// ---------------------------------------------------------
+// ----------------- Gifts ---------------------------------
+// ---------------------------------------------------------
+
+// FindGiftItems returns the active gift catalogue, cheapest first.
+func (s *service) FindGiftItems() ([]models.GiftItem, error) {
+	var items []models.GiftItem
+	if err := s.db.Where("active = ?", true).Order("price ASC, id ASC").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// SendGift moves the gift's price from the sender to the stream's host and
+// records the gift and both ledger rows, all in one transaction. It returns
+// the gift and the sender's new balance. Unknown or retired gifts and
+// missing streams yield gorm.ErrRecordNotFound.
+func (s *service) SendGift(purchase GiftPurchase) (*models.Gift, int64, error) {
+	if purchase.Quantity <= 0 {
+		return nil, 0, errors.New("gift quantity must be positive")
+	}
+
+	var gift models.Gift
+	var balance int64
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var item models.GiftItem
+		if err := tx.Where("id = ? AND active = ?", purchase.GiftItemID, true).First(&item).Error; err != nil {
+			return err
+		}
+
+		var stream models.LiveStream
+		if err := tx.Select("id", "user_id", "status").Where("id = ?", purchase.LiveStreamID).First(&stream).Error; err != nil {
+			return err
+		}
+		if stream.Status != models.LiveStreamLive {
+			return ErrStreamNotLive
+		}
+		if stream.UserID == purchase.SenderID {
+			return ErrGiftToSelf
+		}
+
+		cost := item.Price * int64(purchase.Quantity)
+
+		// Lock both wallets in id order so two users gifting each other at
+		// the same time can't deadlock.
+		var wallets []models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Select("id", "coins").
+			Where("id IN ?", []uint{purchase.SenderID, stream.UserID}).
+			Order("id").Find(&wallets).Error; err != nil {
+			return err
+		}
+		if len(wallets) != 2 {
+			return gorm.ErrRecordNotFound
+		}
+
+		var senderCoins, hostCoins int64
+		for _, w := range wallets {
+			if w.ID == purchase.SenderID {
+				senderCoins = w.Coins
+			} else {
+				hostCoins = w.Coins
+			}
+		}
+		if senderCoins < cost {
+			return ErrInsufficientCoins
+		}
+
+		balance = senderCoins - cost
+		if err := tx.Model(&models.User{}).Where("id = ?", purchase.SenderID).UpdateColumn("coins", balance).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.User{}).Where("id = ?", stream.UserID).UpdateColumn("coins", hostCoins+cost).Error; err != nil {
+			return err
+		}
+
+		gift = models.Gift{
+			UserID:       purchase.SenderID,
+			RecipientID:  stream.UserID,
+			LiveStreamID: stream.ID,
+			GiftItemID:   item.ID,
+			GiftType:     item.Name,
+			Quantity:     purchase.Quantity,
+			Coins:        cost,
+		}
+		if err := tx.Create(&gift).Error; err != nil {
+			return err
+		}
+		gift.GiftItem = item
+
+		ledger := []models.CoinTransaction{
+			{UserID: purchase.SenderID, Kind: models.CoinGiftSent, Amount: -cost, BalanceAfter: balance, GiftID: &gift.ID},
+			{UserID: stream.UserID, Kind: models.CoinGiftReceived, Amount: cost, BalanceAfter: hostCoins + cost, GiftID: &gift.ID},
+		}
+		return tx.Create(&ledger).Error
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+	return &gift, balance, nil
+}
@@ -0,0 +1,163 @@
+// Package engagement buffers post view, share and save counter changes in
+// memory and writes them out in batches, so a post going viral costs one
+// UPDATE per flush instead of one per request.
+package engagement
+
+import (
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"Tiktok/internal/database"
+)
+
+// ViewWindow is how long after a counted view the same user's views of the
+// same post are ignored.
+const ViewWindow = 30 * time.Minute
+
+// Store is the slice of database.Service the buffer needs.
+type Store interface {
+	ApplyPostCounters(deltas map[uint]database.PostCounters) error
+}
+
+type Config struct {
+	// FlushInterval is how often pending changes are written.
+	FlushInterval time.Duration
+	// MaxPending is how many posts may have pending changes before a flush
+	// is triggered early.
+	MaxPending int
+}
+
+func ConfigFromEnv() Config {
+	config := Config{FlushInterval: 5 * time.Second, MaxPending: 1000}
+	if d, err := time.ParseDuration(os.Getenv("COUNTER_FLUSH_INTERVAL")); err == nil && d > 0 {
+		config.FlushInterval = d
+	}
+	if n, err := strconv.Atoi(os.Getenv("COUNTER_MAX_PENDING")); err == nil && n > 0 {
+		config.MaxPending = n
+	}
+	return config
+}
+
+type Buffer struct {
+	store      Store
+	maxPending int
+
+	mu      sync.Mutex
+	pending map[uint]database.PostCounters
+
+	flushNow  chan struct{}
+	stop      chan struct{}
+	stopOnce  sync.Once
+	flushDone chan struct{}
+}
+
+// NewBuffer starts the goroutine that flushes every config.FlushInterval.
+func NewBuffer(store Store, config Config) *Buffer {
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = 5 * time.Second
+	}
+	if config.MaxPending <= 0 {
+		config.MaxPending = 1
+	}
+
+	b := &Buffer{
+		store:      store,
+		maxPending: config.MaxPending,
+		pending:    make(map[uint]database.PostCounters),
+		flushNow:   make(chan struct{}, 1),
+		stop:       make(chan struct{}),
+		flushDone:  make(chan struct{}),
+	}
+	go b.run(config.FlushInterval)
+	return b
+}
+
+func (b *Buffer) AddView(postID uint) {
+	b.add(postID, database.PostCounters{Views: 1})
+}
+
+func (b *Buffer) AddShare(postID uint) {
+	b.add(postID, database.PostCounters{Shares: 1})
+}
+
+// AddSave records a save (+1) or an unsave (-1).
+func (b *Buffer) AddSave(postID uint, delta int64) {
+	b.add(postID, database.PostCounters{Saves: delta})
+}
+
+func (b *Buffer) add(postID uint, delta database.PostCounters) {
+	b.mu.Lock()
+	merge(b.pending, postID, delta)
+	full := len(b.pending) >= b.maxPending
+	b.mu.Unlock()
+
+	if full {
+		select {
+		case b.flushNow <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// Flush writes all pending changes. If the write fails they are put back to
+// be retried on the next flush.
+func (b *Buffer) Flush() error {
+	b.mu.Lock()
+	batch := b.pending
+	b.pending = make(map[uint]database.PostCounters)
+	b.mu.Unlock()
+
+	if len(batch) == 0 {
+		return nil
+	}
+
+	if err := b.store.ApplyPostCounters(batch); err != nil {
+		b.mu.Lock()
+		for postID, delta := range batch {
+			merge(b.pending, postID, delta)
+		}
+		b.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+// Close stops the background flushes and writes whatever is still pending.
+func (b *Buffer) Close() {
+	b.stopOnce.Do(func() { close(b.stop) })
+	<-b.flushDone
+}
+
+func (b *Buffer) run(interval time.Duration) {
+	defer close(b.flushDone)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+		case <-b.flushNow:
+		case <-b.stop:
+			if err := b.Flush(); err != nil {
+				log.Printf("engagement: final counter flush failed: %v", err)
+			}
+			return
+		}
+
+		if err := b.Flush(); err != nil {
+			log.Printf("engagement: counter flush failed: %v", err)
+		}
+	}
+}
+
+func merge(pending map[uint]database.PostCounters, postID uint, delta database.PostCounters) {
+	current := pending[postID]
+	current.Views += delta.Views
+	current.Shares += delta.Shares
+	current.Saves += delta.Saves
+	pending[postID] = current
+}
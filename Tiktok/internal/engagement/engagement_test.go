@@ -0,0 +1,129 @@
+package engagement
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"Tiktok/internal/database"
+)
+
+type recordingStore struct {
+	mu      sync.Mutex
+	fail    bool
+	batches []map[uint]database.PostCounters
+}
+
+func (s *recordingStore) ApplyPostCounters(deltas map[uint]database.PostCounters) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.fail {
+		return errors.New("database unavailable")
+	}
+	s.batches = append(s.batches, deltas)
+	return nil
+}
+
+func (s *recordingStore) total() map[uint]database.PostCounters {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := map[uint]database.PostCounters{}
+	for _, batch := range s.batches {
+		for id, d := range batch {
+			merge(total, id, d)
+		}
+	}
+	return total
+}
+
+func TestFlushMergesChangesPerPost(t *testing.T) {
+	store := &recordingStore{}
+	b := NewBuffer(store, Config{FlushInterval: time.Hour, MaxPending: 100})
+	defer b.Close()
+
+	for i := 0; i < 5; i++ {
+		b.AddView(1)
+	}
+	b.AddShare(1)
+	b.AddSave(2, 1)
+	b.AddSave(2, 1)
+	b.AddSave(2, -1)
+
+	if err := b.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	if len(store.batches) != 1 {
+		t.Fatalf("got %d batches, want 1", len(store.batches))
+	}
+	batch := store.batches[0]
+	if got := batch[1]; got != (database.PostCounters{Views: 5, Shares: 1}) {
+		t.Errorf("post 1 = %+v", got)
+	}
+	if got := batch[2]; got != (database.PostCounters{Saves: 1}) {
+		t.Errorf("post 2 = %+v", got)
+	}
+
+	if err := b.Flush(); err != nil {
+		t.Fatalf("empty Flush: %v", err)
+	}
+	if len(store.batches) != 1 {
+		t.Errorf("empty flush wrote a batch")
+	}
+}
+
+func TestFailedFlushIsRetried(t *testing.T) {
+	store := &recordingStore{fail: true}
+	b := NewBuffer(store, Config{FlushInterval: time.Hour, MaxPending: 100})
+	defer b.Close()
+
+	b.AddView(3)
+	if err := b.Flush(); err == nil {
+		t.Fatal("Flush succeeded against a failing store")
+	}
+
+	b.AddView(3)
+	store.mu.Lock()
+	store.fail = false
+	store.mu.Unlock()
+
+	if err := b.Flush(); err != nil {
+		t.Fatalf("retry Flush: %v", err)
+	}
+	if got := store.total()[3]; got.Views != 2 {
+		t.Errorf("views after retry = %d, want 2", got.Views)
+	}
+}
+
+func TestMaxPendingTriggersFlush(t *testing.T) {
+	store := &recordingStore{}
+	b := NewBuffer(store, Config{FlushInterval: time.Hour, MaxPending: 3})
+	defer b.Close()
+
+	for id := uint(1); id <= 3; id++ {
+		b.AddView(id)
+	}
+
+	deadline := time.After(time.Second)
+	for len(store.total()) < 3 {
+		select {
+		case <-deadline:
+			t.Fatal("reaching MaxPending did not flush")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestCloseFlushesPending(t *testing.T) {
+	store := &recordingStore{}
+	b := NewBuffer(store, Config{FlushInterval: time.Hour, MaxPending: 100})
+
+	b.AddShare(9)
+	b.Close()
+	b.Close()
+
+	if got := store.total()[9]; got.Shares != 1 {
+		t.Errorf("shares after Close = %d, want 1", got.Shares)
+	}
+}
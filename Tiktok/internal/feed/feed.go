@@ -0,0 +1,216 @@
+// Package feed ranks posts for the "for you" feed and encodes the opaque
+// cursors used to page through both feeds.
+package feed
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultLimit is the page size used when the client does not ask for one.
+	DefaultLimit = 20
+	// MaxLimit caps the page size a client can request.
+	MaxLimit = 50
+	// CandidateWindow is how far back the "for you" feed looks for posts.
+	CandidateWindow = 30 * 24 * time.Hour
+	// CandidatePool is the maximum number of recent posts scored per request.
+	CandidatePool = 500
+)
+
+var ErrInvalidCursor = errors.New("invalid feed cursor")
+
+// Weights tunes how much each signal contributes to a post's score.
+type Weights struct {
+	Like     float64
+	Comment  float64
+	Share    float64
+	View     float64
+	Affinity float64       // multiplier applied to the viewer's hashtag affinity (0..1)
+	HalfLife time.Duration // age at which a post's score is halved
+}
+
+var DefaultWeights = Weights{
+	Like:     1,
+	Comment:  2,
+	Share:    3,
+	View:     0.1,
+	Affinity: 1.5,
+	HalfLife: 24 * time.Hour,
+}
+
+// Candidate carries the signals needed to score a single post.
+type Candidate struct {
+	PostID    uint
+	Likes     int64
+	Comments  int64
+	Views     uint
+	Shares    uint
+	Hashtags  []string
+	CreatedAt time.Time
+}
+
+// Ranked is a candidate together with the score it was ranked by.
+type Ranked struct {
+	Candidate
+	Score float64
+}
+
+// Affinity maps a lower-cased hashtag to how strongly the viewer engages with
+// it, normalised so the viewer's favourite tag is 1.
+type Affinity map[string]float64
+
+// NewAffinity normalises raw interaction counts per hashtag.
+func NewAffinity(counts map[string]int64) Affinity {
+	var max int64
+	for _, n := range counts {
+		if n > max {
+			max = n
+		}
+	}
+
+	affinity := make(Affinity, len(counts))
+	if max == 0 {
+		return affinity
+	}
+	for tag, n := range counts {
+		if n > 0 {
+			affinity[strings.ToLower(tag)] = float64(n) / float64(max)
+		}
+	}
+	return affinity
+}
+
+// Match returns the strongest affinity the viewer has for any of the tags.
+func (a Affinity) Match(tags []string) float64 {
+	best := 0.0
+	for _, tag := range tags {
+		if w := a[strings.ToLower(tag)]; w > best {
+			best = w
+		}
+	}
+	return best
+}
+
+// Score combines engagement, hashtag affinity and recency. Engagement is
+// log-damped so a handful of viral posts cannot drown out everything newer,
+// and the result decays exponentially with the post's age.
+func (w Weights) Score(c Candidate, affinity Affinity, now time.Time) float64 {
+	engagement := w.Like*float64(c.Likes) +
+		w.Comment*float64(c.Comments) +
+		w.Share*float64(c.Shares) +
+		w.View*float64(c.Views)
+
+	score := (1 + math.Log1p(engagement)) * (1 + w.Affinity*affinity.Match(c.Hashtags))
+
+	age := now.Sub(c.CreatedAt)
+	if age < 0 {
+		age = 0
+	}
+	if w.HalfLife > 0 {
+		score *= math.Exp2(-age.Hours() / w.HalfLife.Hours())
+	}
+	return score
+}
+
+// Rank scores every candidate and orders them best first. Ties fall back to
+// the newer post id so the order is stable between requests.
+func (w Weights) Rank(candidates []Candidate, affinity Affinity, now time.Time) []Ranked {
+	ranked := make([]Ranked, len(candidates))
+	for i, c := range candidates {
+		ranked[i] = Ranked{Candidate: c, Score: w.Score(c, affinity, now)}
+	}
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		return ranked[i].PostID > ranked[j].PostID
+	})
+	return ranked
+}
+
+// Page returns up to limit ranked posts that come after the cursor position,
+// and whether more remain.
+func Page(ranked []Ranked, after *Cursor, limit int) ([]Ranked, bool) {
+	start := 0
+	if after != nil {
+		start = len(ranked)
+		for i, r := range ranked {
+			if r.Score < after.Score || (r.Score == after.Score && r.PostID < after.ID) {
+				start = i
+				break
+			}
+		}
+	}
+
+	end := start + limit
+	if end >= len(ranked) {
+		return ranked[start:], false
+	}
+	return ranked[start:end], true
+}
+
+// Cursor marks the last item a client has seen. For the following feed At is
+// the post's creation time; for the ranked feed it is the moment the ranking
+// was computed, so later pages are scored against the same clock.
+type Cursor struct {
+	At    time.Time
+	Score float64
+	ID    uint
+}
+
+// Encode renders the cursor as an opaque URL-safe token.
+func (c Cursor) Encode() string {
+	raw := fmt.Sprintf("%d:%s:%d", c.At.UnixNano(), strconv.FormatFloat(c.Score, 'g', -1, 64), c.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeCursor parses a token produced by Cursor.Encode. An empty token
+// yields a nil cursor, meaning "start from the top".
+func DecodeCursor(token string) (*Cursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	parts := strings.Split(string(raw), ":")
+	if len(parts) != 3 {
+		return nil, ErrInvalidCursor
+	}
+
+	nanos, err := strconv.ParseInt(parts[0], 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	score, err := strconv.ParseFloat(parts[1], 64)
+	if err != nil || math.IsNaN(score) {
+		return nil, ErrInvalidCursor
+	}
+	id, err := strconv.ParseUint(parts[2], 10, 64)
+	if err != nil || id == 0 {
+		return nil, ErrInvalidCursor
+	}
+
+	return &Cursor{At: time.Unix(0, nanos), Score: score, ID: uint(id)}, nil
+}
+
+// ClampLimit applies the default and maximum page sizes.
+func ClampLimit(limit int) int {
+	if limit <= 0 {
+		return DefaultLimit
+	}
+	if limit > MaxLimit {
+		return MaxLimit
+	}
+	return limit
+}
@@ -0,0 +1,64 @@
+package feed
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRankPrefersEngagementAffinityAndRecency(t *testing.T) {
+	now := time.Date(2025, 1, 10, 12, 0, 0, 0, time.UTC)
+
+	candidates := []Candidate{
+		{PostID: 1, CreatedAt: now.Add(-time.Hour)},
+		{PostID: 2, Likes: 50, Comments: 10, CreatedAt: now.Add(-time.Hour)},
+		{PostID: 3, Likes: 50, Comments: 10, CreatedAt: now.Add(-10 * 24 * time.Hour)},
+		{PostID: 4, CreatedAt: now.Add(-time.Hour), Hashtags: []string{"Cooking"}},
+	}
+	affinity := NewAffinity(map[string]int64{"cooking": 4, "travel": 2})
+
+	ranked := DefaultWeights.Rank(candidates, affinity, now)
+
+	order := make([]uint, len(ranked))
+	for i, r := range ranked {
+		order[i] = r.PostID
+	}
+	want := []uint{2, 4, 1, 3}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Fatalf("rank order = %v, want %v", order, want)
+		}
+	}
+}
+
+func TestPageResumesAfterCursor(t *testing.T) {
+	ranked := []Ranked{
+		{Candidate: Candidate{PostID: 9}, Score: 5},
+		{Candidate: Candidate{PostID: 7}, Score: 3},
+		{Candidate: Candidate{PostID: 4}, Score: 3},
+		{Candidate: Candidate{PostID: 8}, Score: 1},
+	}
+
+	first, more := Page(ranked, nil, 2)
+	if len(first) != 2 || !more || first[1].PostID != 7 {
+		t.Fatalf("first page = %+v more=%v", first, more)
+	}
+
+	token := Cursor{At: time.Unix(100, 0), Score: first[1].Score, ID: first[1].PostID}.Encode()
+	cursor, err := DecodeCursor(token)
+	if err != nil {
+		t.Fatalf("DecodeCursor: %v", err)
+	}
+
+	second, more := Page(ranked, cursor, 2)
+	if len(second) != 2 || more || second[0].PostID != 4 || second[1].PostID != 8 {
+		t.Fatalf("second page = %+v more=%v", second, more)
+	}
+}
+
+func TestDecodeCursorRejectsGarbage(t *testing.T) {
+	for _, token := range []string{"not-base64!", "MTIz", Cursor{At: time.Unix(1, 0)}.Encode()} {
+		if _, err := DecodeCursor(token); err != ErrInvalidCursor {
+			t.Errorf("DecodeCursor(%q) error = %v, want ErrInvalidCursor", token, err)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+// Package jobs holds the periodic background work started from main.
+package jobs
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// DefaultReconcileInterval is how often follower counters are recomputed.
+const DefaultReconcileInterval = time.Hour
+
+// FollowCountStore is the slice of database.Service the reconciler needs.
+type FollowCountStore interface {
+	ReconcileFollowCounts() (int64, error)
+}
+
+// RunFollowCountReconciler recomputes follower/following counters once
+// immediately and then on every tick until ctx is cancelled.
+func RunFollowCountReconciler(ctx context.Context, store FollowCountStore, interval time.Duration) {
+	if interval <= 0 {
+		interval = DefaultReconcileInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		reconcileFollowCounts(store)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func reconcileFollowCounts(store FollowCountStore) {
+	fixed, err := store.ReconcileFollowCounts()
+	if err != nil {
+		log.Printf("follow count reconciliation failed: %v", err)
+		return
+	}
+	if fixed > 0 {
+		log.Printf("follow count reconciliation corrected %d users", fixed)
+	}
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type countingStore struct {
+	calls atomic.Int32
+}
+
+func (s *countingStore) ReconcileFollowCounts() (int64, error) {
+	s.calls.Add(1)
+	return 0, nil
+}
+
+func TestRunFollowCountReconcilerRunsUntilCancelled(t *testing.T) {
+	store := &countingStore{}
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan struct{})
+	go func() {
+		RunFollowCountReconciler(ctx, store, 5*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.After(time.Second)
+	for store.calls.Load() < 2 {
+		select {
+		case <-deadline:
+			t.Fatalf("reconciler ran %d times, want at least 2", store.calls.Load())
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("reconciler did not stop after cancel")
+	}
+}
@@ -0,0 +1,321 @@
+// Package livechat fans live-stream chat, gift and viewer-count events out
+// to every connected viewer. Each stream gets its own hub goroutine that owns
+// its client set, so streams never contend on a shared lock.
+package livechat
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+	"time"
+)
+
+// Event types sent to clients.
+const (
+	EventComment     = "comment"
+	EventGift        = "gift"
+	EventViewerCount = "viewer_count"
+	EventEnded       = "ended"
+	EventError       = "error"
+)
+
+// FlushInterval is how often a changed viewer count is written to the
+// database. Joins and leaves in between are coalesced into one write.
+const FlushInterval = time.Second
+
+// Store persists the viewer count a hub maintains.
+type Store interface {
+	UpdateLiveStreamViewerCount(streamID uint, count uint) error
+}
+
+type User struct {
+	ID     uint   `json:"id"`
+	Name   string `json:"name"`
+	Avatar string `json:"avatar,omitempty"`
+}
+
+type Gift struct {
+	ID       uint   `json:"id"`
+	Name     string `json:"name"`
+	Icon     string `json:"icon,omitempty"`
+	Quantity int    `json:"quantity"`
+	Coins    int64  `json:"coins"`
+}
+
+type Event struct {
+	Type        string    `json:"type"`
+	StreamID    uint      `json:"stream_id"`
+	User        *User     `json:"user,omitempty"`
+	Text        string    `json:"text,omitempty"`
+	Gift        *Gift     `json:"gift,omitempty"`
+	ViewerCount *uint     `json:"viewer_count,omitempty"`
+	Message     string    `json:"message,omitempty"`
+	At          time.Time `json:"at"`
+}
+
+// Client is one WebSocket connection. The hub closes Send when the client is
+// removed; the connection's writer should exit when it sees that.
+type Client struct {
+	UserID uint
+	IsHost bool
+	Send   chan []byte
+}
+
+func NewClient(userID uint, isHost bool) *Client {
+	return &Client{UserID: userID, IsHost: isHost, Send: make(chan []byte, 32)}
+}
+
+type directMessage struct {
+	client  *Client
+	payload []byte
+}
+
+// Hub serves a single live stream.
+type Hub struct {
+	streamID uint
+	store    Store
+	interval time.Duration
+
+	join      chan *Client
+	leave     chan *Client
+	broadcast chan []byte
+	direct    chan directMessage
+	stop      chan struct{}
+	stopOnce  sync.Once
+	done      chan struct{}
+
+	onExit func()
+}
+
+func newHub(streamID uint, store Store, interval time.Duration, onExit func()) *Hub {
+	return &Hub{
+		streamID:  streamID,
+		store:     store,
+		interval:  interval,
+		join:      make(chan *Client),
+		leave:     make(chan *Client),
+		broadcast: make(chan []byte, 64),
+		direct:    make(chan directMessage),
+		stop:      make(chan struct{}),
+		done:      make(chan struct{}),
+		onExit:    onExit,
+	}
+}
+
+// Join adds the client. It returns false if the hub has already shut down,
+// in which case the caller should fetch a fresh hub.
+func (h *Hub) Join(c *Client) bool {
+	select {
+	case h.join <- c:
+		return true
+	case <-h.done:
+		return false
+	}
+}
+
+// Leave removes the client. It is safe to call more than once and after the
+// hub has shut down.
+func (h *Hub) Leave(c *Client) {
+	select {
+	case h.leave <- c:
+	case <-h.done:
+	}
+}
+
+// Broadcast sends an event to every client on the stream.
+func (h *Hub) Broadcast(event Event) {
+	event.StreamID = h.streamID
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("livechat: failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	select {
+	case h.broadcast <- payload:
+	case <-h.done:
+	}
+}
+
+// SendTo delivers an event to a single client, e.g. to report that its own
+// message was rejected. It is dropped if the client has already left.
+func (h *Hub) SendTo(c *Client, event Event) {
+	event.StreamID = h.streamID
+	if event.At.IsZero() {
+		event.At = time.Now()
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("livechat: failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	select {
+	case h.direct <- directMessage{client: c, payload: payload}:
+	case <-h.done:
+	}
+}
+
+// Done is closed once the hub has shut down.
+func (h *Hub) Done() <-chan struct{} {
+	return h.done
+}
+
+// end tells every client the stream is over and shuts the hub down.
+func (h *Hub) end() {
+	h.stopOnce.Do(func() { close(h.stop) })
+}
+
+func (h *Hub) run() {
+	defer close(h.done)
+	defer h.onExit()
+
+	clients := make(map[*Client]struct{})
+	var viewers uint
+	dirty := false
+	joined := false
+
+	ticker := time.NewTicker(h.interval)
+	defer ticker.Stop()
+
+	remove := func(c *Client) {
+		delete(clients, c)
+		close(c.Send)
+		if !c.IsHost {
+			viewers--
+			dirty = true
+		}
+	}
+
+	deliver := func(c *Client, payload []byte) {
+		select {
+		case c.Send <- payload:
+		default:
+			// A client that can't keep up is dropped rather than
+			// allowed to stall the whole stream.
+			remove(c)
+		}
+	}
+
+	send := func(payload []byte) {
+		for c := range clients {
+			deliver(c, payload)
+		}
+	}
+
+	viewerCount := func() []byte {
+		count := viewers
+		payload, _ := json.Marshal(Event{Type: EventViewerCount, StreamID: h.streamID, ViewerCount: &count, At: time.Now()})
+		return payload
+	}
+
+	// flush stores and announces the viewer count if it changed since the
+	// last tick, so a burst of joins costs one write and one broadcast.
+	flush := func() {
+		if !dirty {
+			return
+		}
+		dirty = false
+		if err := h.store.UpdateLiveStreamViewerCount(h.streamID, viewers); err != nil {
+			log.Printf("livechat: failed to store viewer count for stream %d: %v", h.streamID, err)
+			dirty = true
+		}
+		send(viewerCount())
+	}
+
+	for {
+		select {
+		case c := <-h.join:
+			joined = true
+			clients[c] = struct{}{}
+			if !c.IsHost {
+				viewers++
+				dirty = true
+			}
+			deliver(c, viewerCount())
+
+		case c := <-h.leave:
+			if _, ok := clients[c]; ok {
+				remove(c)
+			}
+
+		case payload := <-h.broadcast:
+			send(payload)
+
+		case msg := <-h.direct:
+			if _, ok := clients[msg.client]; ok {
+				deliver(msg.client, msg.payload)
+			}
+
+		case <-ticker.C:
+			flush()
+
+		case <-h.stop:
+			payload, _ := json.Marshal(Event{Type: EventEnded, StreamID: h.streamID, At: time.Now()})
+			send(payload)
+			for c := range clients {
+				close(c.Send)
+			}
+			return
+		}
+
+		// The hub lives as long as someone is connected. A new hub is
+		// started with no clients, so wait for the first join.
+		if len(clients) == 0 && joined {
+			flush()
+			return
+		}
+	}
+}
+
+// Registry hands out the hub for each stream, starting one on first use and
+// forgetting it once its last client leaves.
+type Registry struct {
+	store    Store
+	interval time.Duration
+	hubs     sync.Map // stream ID -> *Hub
+}
+
+func NewRegistry(store Store) *Registry {
+	return &Registry{store: store, interval: FlushInterval}
+}
+
+// Join adds the client to the stream's hub and returns that hub.
+func (r *Registry) Join(streamID uint, c *Client) *Hub {
+	for {
+		h := r.hub(streamID)
+		if h.Join(c) {
+			return h
+		}
+		// The hub shut down between lookup and join; it has removed
+		// itself from the registry, so the next lookup starts a new one.
+	}
+}
+
+// End notifies everyone watching the stream that it is over and disconnects
+// them.
+func (r *Registry) End(streamID uint) {
+	if value, ok := r.hubs.Load(streamID); ok {
+		value.(*Hub).end()
+	}
+}
+
+func (r *Registry) hub(streamID uint) *Hub {
+	if value, ok := r.hubs.Load(streamID); ok {
+		return value.(*Hub)
+	}
+
+	var h *Hub
+	h = newHub(streamID, r.store, r.interval, func() { r.hubs.CompareAndDelete(streamID, h) })
+	actual, loaded := r.hubs.LoadOrStore(streamID, h)
+	if loaded {
+		return actual.(*Hub)
+	}
+	go h.run()
+	return h
+}
@@ -0,0 +1,194 @@
+package livechat
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+	"time"
+)
+
+type countStore struct {
+	mu     sync.Mutex
+	counts map[uint][]uint
+}
+
+func newCountStore() *countStore {
+	return &countStore{counts: map[uint][]uint{}}
+}
+
+func (s *countStore) UpdateLiveStreamViewerCount(streamID uint, count uint) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.counts[streamID] = append(s.counts[streamID], count)
+	return nil
+}
+
+func (s *countStore) last(streamID uint) (uint, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	counts := s.counts[streamID]
+	if len(counts) == 0 {
+		return 0, false
+	}
+	return counts[len(counts)-1], true
+}
+
+func newTestRegistry() (*Registry, *countStore) {
+	store := newCountStore()
+	r := NewRegistry(store)
+	r.interval = 5 * time.Millisecond
+	return r, store
+}
+
+// next reads events from the client until one of the given type arrives.
+func next(t *testing.T, c *Client, eventType string) Event {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case payload, ok := <-c.Send:
+			if !ok {
+				t.Fatalf("client closed while waiting for %s", eventType)
+			}
+			var event Event
+			if err := json.Unmarshal(payload, &event); err != nil {
+				t.Fatalf("decode event: %v", err)
+			}
+			if event.Type == eventType {
+				return event
+			}
+		case <-deadline:
+			t.Fatalf("timed out waiting for %s", eventType)
+		}
+	}
+}
+
+func waitClosed(t *testing.T, c *Client) {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for {
+		select {
+		case _, ok := <-c.Send:
+			if !ok {
+				return
+			}
+		case <-deadline:
+			t.Fatal("client was not disconnected")
+		}
+	}
+}
+
+func TestBroadcastReachesEveryClient(t *testing.T) {
+	r, _ := newTestRegistry()
+
+	host := NewClient(1, true)
+	viewer := NewClient(2, false)
+	hub := r.Join(7, host)
+	if got := r.Join(7, viewer); got != hub {
+		t.Fatal("clients of one stream got different hubs")
+	}
+
+	hub.Broadcast(Event{Type: EventComment, User: &User{ID: 2, Name: "viewer"}, Text: "hi"})
+
+	for _, c := range []*Client{host, viewer} {
+		event := next(t, c, EventComment)
+		if event.Text != "hi" || event.StreamID != 7 || event.User == nil || event.User.ID != 2 {
+			t.Errorf("user %d got %+v", c.UserID, event)
+		}
+	}
+
+	hub.Leave(host)
+	hub.Leave(viewer)
+}
+
+func TestViewerCountExcludesHostAndIsStored(t *testing.T) {
+	r, store := newTestRegistry()
+
+	host := NewClient(1, true)
+	hub := r.Join(3, host)
+	viewers := []*Client{NewClient(2, false), NewClient(3, false), NewClient(4, false)}
+	for _, v := range viewers {
+		r.Join(3, v)
+	}
+
+	deadline := time.After(time.Second)
+	for {
+		if count, ok := store.last(3); ok && count == 3 {
+			break
+		}
+		select {
+		case <-deadline:
+			count, _ := store.last(3)
+			t.Fatalf("stored viewer count = %d, want 3", count)
+		case <-time.After(time.Millisecond):
+		}
+	}
+
+	hub.Leave(viewers[0])
+	for {
+		event := next(t, host, EventViewerCount)
+		if event.ViewerCount != nil && *event.ViewerCount == 2 {
+			break
+		}
+	}
+
+	hub.Leave(host)
+	hub.Leave(viewers[1])
+	hub.Leave(viewers[2])
+
+	select {
+	case <-hub.Done():
+	case <-time.After(time.Second):
+		t.Fatal("hub did not stop after the last client left")
+	}
+	if count, _ := store.last(3); count != 0 {
+		t.Errorf("final stored viewer count = %d, want 0", count)
+	}
+
+	// A later viewer gets a fresh hub.
+	late := NewClient(5, false)
+	if fresh := r.Join(3, late); fresh == hub {
+		t.Error("joined a hub that had already stopped")
+	} else {
+		fresh.Leave(late)
+	}
+}
+
+func TestEndDisconnectsEveryone(t *testing.T) {
+	r, _ := newTestRegistry()
+
+	host := NewClient(1, true)
+	viewer := NewClient(2, false)
+	hub := r.Join(9, host)
+	r.Join(9, viewer)
+
+	r.End(9)
+
+	for _, c := range []*Client{host, viewer} {
+		next(t, c, EventEnded)
+		waitClosed(t, c)
+	}
+
+	<-hub.Done()
+	hub.Leave(viewer) // must not block or panic once the hub is gone
+}
+
+func TestSlowClientIsDropped(t *testing.T) {
+	r, _ := newTestRegistry()
+
+	fast := NewClient(1, false)
+	slow := NewClient(2, false)
+	hub := r.Join(4, fast)
+	r.Join(4, slow)
+
+	total := cap(slow.Send) + 8
+	for i := 0; i < total; i++ {
+		hub.Broadcast(Event{Type: EventComment, Text: "spam"})
+		next(t, fast, EventComment)
+	}
+
+	// slow never read, so its buffer overflowed and the hub let it go.
+	waitClosed(t, slow)
+	hub.Leave(fast)
+	<-hub.Done()
+}
@@ -0,0 +1,72 @@
+package livestream
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"sync"
+
+	"Tiktok/internal/models"
+)
+
+// Fake is an in-memory provider for local development and tests. It enforces
+// the same status transitions as a real backend.
+type Fake struct {
+	mu      sync.Mutex
+	streams map[string]string // call ID -> status
+}
+
+func NewFake() *Fake {
+	return &Fake{streams: make(map[string]string)}
+}
+
+func (f *Fake) Create(ctx context.Context, req StartRequest) (Ingest, error) {
+	key := make([]byte, 16)
+	if _, err := rand.Read(key); err != nil {
+		return Ingest{}, err
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, exists := f.streams[req.CallID]; exists {
+		return Ingest{}, fmt.Errorf("call %s already exists", req.CallID)
+	}
+	f.streams[req.CallID] = models.LiveStreamBackstage
+
+	return Ingest{
+		RtmpURL:   "rtmp://localhost:1935/live/" + req.CallID,
+		StreamKey: hex.EncodeToString(key),
+	}, nil
+}
+
+func (f *Fake) GoLive(ctx context.Context, callID string) error {
+	return f.transition(callID, models.LiveStreamLive)
+}
+
+func (f *Fake) End(ctx context.Context, callID string) error {
+	return f.transition(callID, models.LiveStreamEnded)
+}
+
+// Status returns the provider-side status of a call, or "" if unknown.
+func (f *Fake) Status(callID string) string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.streams[callID]
+}
+
+func (f *Fake) transition(callID, to string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	from, ok := f.streams[callID]
+	if !ok {
+		return ErrUnknownStream
+	}
+	if !CanTransition(from, to) {
+		return fmt.Errorf("%w: %s to %s", ErrInvalidTransition, from, to)
+	}
+	f.streams[callID] = to
+	return nil
+}
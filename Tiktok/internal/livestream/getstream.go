@@ -0,0 +1,61 @@
+package livestream
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/GetStream/getstream-go"
+)
+
+const callType = "livestream"
+
+// GetStream runs live streams as GetStream "livestream" calls. The host
+// pushes RTMP to the call's ingress address using a user token as the key.
+type GetStream struct {
+	client *getstream.Stream
+}
+
+func NewGetStream(client *getstream.Stream) *GetStream {
+	return &GetStream{client: client}
+}
+
+func (g *GetStream) Create(ctx context.Context, req StartRequest) (Ingest, error) {
+	hostID := strconv.FormatUint(uint64(req.HostID), 10)
+
+	call := g.client.Video().Call(callType, req.CallID)
+	response, err := call.GetOrCreate(ctx, &getstream.GetOrCreateCallRequest{
+		Data: &getstream.CallRequest{
+			CreatedByID: getstream.PtrTo(hostID),
+			Members: []getstream.MemberRequest{
+				{UserID: hostID, Role: getstream.PtrTo("host")},
+			},
+			Custom: map[string]any{
+				"title":       req.Title,
+				"description": req.Description,
+			},
+		},
+	})
+	if err != nil {
+		return Ingest{}, err
+	}
+
+	token, err := g.client.CreateToken(hostID)
+	if err != nil {
+		return Ingest{}, err
+	}
+
+	return Ingest{
+		RtmpURL:   response.Data.Call.Ingress.RTMP.Address,
+		StreamKey: token,
+	}, nil
+}
+
+func (g *GetStream) GoLive(ctx context.Context, callID string) error {
+	_, err := g.client.Video().Call(callType, callID).GoLive(ctx, &getstream.GoLiveRequest{})
+	return err
+}
+
+func (g *GetStream) End(ctx context.Context, callID string) error {
+	_, err := g.client.Video().Call(callType, callID).End(ctx, &getstream.EndCallRequest{})
+	return err
+}
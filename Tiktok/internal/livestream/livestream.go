@@ -0,0 +1,84 @@
+// Package livestream hides the video infrastructure behind a small provider
+// interface so the backstage → live → ended lifecycle can run against
+// GetStream in production and an in-memory fake offline.
+package livestream
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"Tiktok/internal/config"
+	"Tiktok/internal/models"
+)
+
+var (
+	ErrUnknownStream     = errors.New("unknown live stream")
+	ErrInvalidTransition = errors.New("invalid live stream status transition")
+)
+
+// StartRequest describes the stream a host wants to open.
+type StartRequest struct {
+	CallID      string
+	HostID      uint
+	HostName    string
+	Title       string
+	Description string
+}
+
+// Ingest is where the host's broadcasting software should push video.
+type Ingest struct {
+	RtmpURL   string `json:"rtmp_url"`
+	StreamKey string `json:"stream_key"`
+}
+
+// Provider is the video backend a live stream runs on.
+type Provider interface {
+	// Create opens the stream in backstage mode: the host can connect and
+	// test, but viewers cannot watch yet.
+	Create(ctx context.Context, req StartRequest) (Ingest, error)
+	// GoLive makes a backstage stream visible to viewers.
+	GoLive(ctx context.Context, callID string) error
+	// End closes the stream for everyone.
+	End(ctx context.Context, callID string) error
+}
+
+// CanTransition reports whether a stream may move from one status to another.
+// Backstage streams can be abandoned without ever going live.
+func CanTransition(from, to string) bool {
+	switch from {
+	case models.LiveStreamBackstage:
+		return to == models.LiveStreamLive || to == models.LiveStreamEnded
+	case models.LiveStreamLive:
+		return to == models.LiveStreamEnded
+	default:
+		return false
+	}
+}
+
+// ProviderFromEnv picks the provider from STREAM_PROVIDER. GetStream is the
+// default and needs STREAM_API_KEY. The in-memory fake has to be asked for
+// explicitly with STREAM_PROVIDER=fake, so that a missing key fails at startup
+// instead of silently running live streams nobody can watch.
+func ProviderFromEnv() (Provider, error) {
+	switch provider := strings.ToLower(os.Getenv("STREAM_PROVIDER")); provider {
+	case "", "getstream":
+		if os.Getenv("STREAM_API_KEY") == "" {
+			return nil, errors.New("STREAM_API_KEY is not set, set STREAM_PROVIDER=fake to use the local fake provider")
+		}
+	case "fake":
+		log.Println("STREAM_PROVIDER is fake, live streams will use the local fake provider")
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unsupported stream provider %q", provider)
+	}
+
+	client, err := config.InitStreamVideo()
+	if err != nil {
+		return nil, err
+	}
+	return NewGetStream(client), nil
+}
@@ -0,0 +1,79 @@
+package livestream
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"Tiktok/internal/models"
+)
+
+func TestFakeLifecycle(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	ingest, err := fake.Create(ctx, StartRequest{CallID: "call-1", HostID: 1, Title: "hello"})
+	if err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	if ingest.RtmpURL == "" || ingest.StreamKey == "" {
+		t.Fatalf("ingest = %+v", ingest)
+	}
+	if got := fake.Status("call-1"); got != models.LiveStreamBackstage {
+		t.Fatalf("status after create = %q", got)
+	}
+
+	if err := fake.GoLive(ctx, "call-1"); err != nil {
+		t.Fatalf("GoLive: %v", err)
+	}
+	if err := fake.GoLive(ctx, "call-1"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("second GoLive = %v, want ErrInvalidTransition", err)
+	}
+	if err := fake.End(ctx, "call-1"); err != nil {
+		t.Fatalf("End: %v", err)
+	}
+	if err := fake.GoLive(ctx, "call-1"); !errors.Is(err, ErrInvalidTransition) {
+		t.Errorf("GoLive after End = %v, want ErrInvalidTransition", err)
+	}
+
+	if err := fake.End(ctx, "missing"); !errors.Is(err, ErrUnknownStream) {
+		t.Errorf("End unknown = %v, want ErrUnknownStream", err)
+	}
+}
+
+func TestCanTransition(t *testing.T) {
+	cases := []struct {
+		from, to string
+		want     bool
+	}{
+		{models.LiveStreamBackstage, models.LiveStreamLive, true},
+		{models.LiveStreamBackstage, models.LiveStreamEnded, true},
+		{models.LiveStreamLive, models.LiveStreamEnded, true},
+		{models.LiveStreamLive, models.LiveStreamBackstage, false},
+		{models.LiveStreamEnded, models.LiveStreamLive, false},
+		{"offline", models.LiveStreamLive, false},
+	}
+	for _, tc := range cases {
+		if got := CanTransition(tc.from, tc.to); got != tc.want {
+			t.Errorf("CanTransition(%s, %s) = %v, want %v", tc.from, tc.to, got, tc.want)
+		}
+	}
+}
+
+func TestProviderFromEnv(t *testing.T) {
+	t.Setenv("STREAM_PROVIDER", "")
+	t.Setenv("STREAM_API_KEY", "")
+	if provider, err := ProviderFromEnv(); err == nil {
+		t.Errorf("missing STREAM_API_KEY = %v; want an error", provider)
+	}
+
+	t.Setenv("STREAM_PROVIDER", "fake")
+	if provider, err := ProviderFromEnv(); err != nil || provider == nil {
+		t.Errorf("fake provider = %v, %v; want the fake", provider, err)
+	}
+
+	t.Setenv("STREAM_PROVIDER", "twitch")
+	if _, err := ProviderFromEnv(); err == nil {
+		t.Error("unsupported provider did not fail")
+	}
+}
@@ -0,0 +1,221 @@
+// Package media runs uploaded videos through a background processing queue:
+// probe the file, render a thumbnail, push both to storage and flip the post
+// from processing to ready (or failed).
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"Tiktok/internal/database"
+	"Tiktok/internal/storage"
+)
+
+var (
+	ErrQueueFull = errors.New("video processing queue is full")
+	ErrClosed    = errors.New("video processor is closed")
+)
+
+// MaxDuration is the longest video we accept.
+const MaxDuration = 10 * time.Minute
+
+// Store is the slice of database.Service the processor needs.
+type Store interface {
+	MarkPostReady(postID uint, media database.PostMedia) error
+	MarkPostFailed(postID uint, reason string) error
+}
+
+// Job is one uploaded video waiting to be processed. Path is a local file the
+// processor owns and removes once it is done.
+type Job struct {
+	PostID      uint
+	Path        string
+	ContentType string
+}
+
+type Config struct {
+	Workers    int
+	QueueSize  int
+	JobTimeout time.Duration
+}
+
+func ConfigFromEnv() Config {
+	config := Config{Workers: 2, QueueSize: 64, JobTimeout: 10 * time.Minute}
+	if n, err := strconv.Atoi(os.Getenv("MEDIA_WORKERS")); err == nil && n > 0 {
+		config.Workers = n
+	}
+	if n, err := strconv.Atoi(os.Getenv("MEDIA_QUEUE_SIZE")); err == nil && n > 0 {
+		config.QueueSize = n
+	}
+	return config
+}
+
+type Processor struct {
+	store   Store
+	storage storage.Storage
+	prober  Prober
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	closed bool
+	jobs   chan Job
+	wg     sync.WaitGroup
+}
+
+// NewProcessor starts config.Workers goroutines consuming the queue.
+func NewProcessor(store Store, files storage.Storage, prober Prober, config Config) *Processor {
+	if config.Workers <= 0 {
+		config.Workers = 1
+	}
+	if config.QueueSize <= 0 {
+		config.QueueSize = 1
+	}
+	if config.JobTimeout <= 0 {
+		config.JobTimeout = 10 * time.Minute
+	}
+
+	p := &Processor{
+		store:   store,
+		storage: files,
+		prober:  prober,
+		timeout: config.JobTimeout,
+		jobs:    make(chan Job, config.QueueSize),
+	}
+
+	p.wg.Add(config.Workers)
+	for i := 0; i < config.Workers; i++ {
+		go p.work()
+	}
+	return p
+}
+
+// Enqueue hands a job to the workers without blocking. On error the caller
+// still owns job.Path.
+func (p *Processor) Enqueue(job Job) error {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+
+	if p.closed {
+		return ErrClosed
+	}
+
+	select {
+	case p.jobs <- job:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Close stops accepting jobs and waits for the queued ones to finish.
+func (p *Processor) Close() {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		return
+	}
+	p.closed = true
+	close(p.jobs)
+	p.mu.Unlock()
+
+	p.wg.Wait()
+}
+
+func (p *Processor) work() {
+	defer p.wg.Done()
+
+	for job := range p.jobs {
+		ctx, cancel := context.WithTimeout(context.Background(), p.timeout)
+		err := p.Process(ctx, job)
+		cancel()
+
+		if err != nil {
+			log.Printf("processing video for post %d failed: %v", job.PostID, err)
+			if markErr := p.store.MarkPostFailed(job.PostID, err.Error()); markErr != nil {
+				log.Printf("failed to mark post %d as failed: %v", job.PostID, markErr)
+			}
+		}
+	}
+}
+
+// Process runs a single job synchronously and marks the post ready on
+// success. The caller is responsible for marking it failed on error.
+func (p *Processor) Process(ctx context.Context, job Job) error {
+	defer os.Remove(job.Path)
+
+	info, err := p.prober.Probe(ctx, job.Path)
+	if err != nil {
+		return err
+	}
+	if info.Duration > MaxDuration.Seconds() {
+		return fmt.Errorf("video is %.0fs long, the maximum is %.0fs", info.Duration, MaxDuration.Seconds())
+	}
+
+	thumbPath := job.Path + ".jpg"
+	defer os.Remove(thumbPath)
+
+	// Grab the frame a second in (or halfway through very short clips) so
+	// the thumbnail is less likely to be a black fade-in.
+	at := 1.0
+	if info.Duration < 2 {
+		at = info.Duration / 2
+	}
+	if err := p.prober.Thumbnail(ctx, job.Path, thumbPath, at); err != nil {
+		return err
+	}
+
+	contentType := job.ContentType
+	if contentType == "" {
+		contentType = "video/mp4"
+	}
+
+	videoURL, err := p.put(ctx, fmt.Sprintf("videos/%d%s", job.PostID, filepath.Ext(job.Path)), job.Path, contentType)
+	if err != nil {
+		return fmt.Errorf("store video: %w", err)
+	}
+
+	thumbURL, err := p.put(ctx, fmt.Sprintf("thumbnails/%d.jpg", job.PostID), thumbPath, "image/jpeg")
+	if err != nil {
+		p.discard(videoURL)
+		return fmt.Errorf("store thumbnail: %w", err)
+	}
+
+	err = p.store.MarkPostReady(job.PostID, database.PostMedia{
+		Video:     videoURL,
+		Thumbnail: thumbURL,
+		Duration:  info.Duration,
+		Width:     info.Width,
+		Height:    info.Height,
+		Codec:     info.Codec,
+	})
+	if err != nil {
+		p.discard(videoURL)
+		p.discard(thumbURL)
+		return fmt.Errorf("mark post ready: %w", err)
+	}
+	return nil
+}
+
+func (p *Processor) put(ctx context.Context, key, path, contentType string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return p.storage.Put(ctx, key, f, contentType)
+}
+
+// discard removes an asset uploaded for a job that ultimately failed.
+func (p *Processor) discard(url string) {
+	if err := p.storage.Delete(context.Background(), url); err != nil {
+		log.Printf("failed to clean up %s: %v", url, err)
+	}
+}
@@ -0,0 +1,133 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"Tiktok/internal/database"
+	"Tiktok/internal/storage"
+)
+
+type fakeProber struct {
+	info Info
+	err  error
+}
+
+func (f fakeProber) Probe(ctx context.Context, path string) (Info, error) {
+	return f.info, f.err
+}
+
+func (f fakeProber) Thumbnail(ctx context.Context, path, out string, at float64) error {
+	return os.WriteFile(out, []byte("jpeg"), 0o644)
+}
+
+type fakeStore struct {
+	mu     sync.Mutex
+	ready  map[uint]database.PostMedia
+	failed map[uint]string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{ready: map[uint]database.PostMedia{}, failed: map[uint]string{}}
+}
+
+func (s *fakeStore) MarkPostReady(postID uint, media database.PostMedia) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.ready[postID] = media
+	return nil
+}
+
+func (s *fakeStore) MarkPostFailed(postID uint, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failed[postID] = reason
+	return nil
+}
+
+func writeUpload(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "upload.mp4")
+	if err := os.WriteFile(path, []byte("video"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestProcessorMarksPostReady(t *testing.T) {
+	dir := t.TempDir()
+	files, err := storage.NewLocal(dir, "/media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newFakeStore()
+	prober := fakeProber{info: Info{Duration: 12.5, Width: 1080, Height: 1920, Codec: "h264"}}
+
+	p := NewProcessor(store, files, prober, Config{Workers: 1, QueueSize: 1})
+	upload := writeUpload(t)
+	if err := p.Enqueue(Job{PostID: 7, Path: upload, ContentType: "video/mp4"}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	p.Close()
+
+	got, ok := store.ready[7]
+	if !ok {
+		t.Fatalf("post was not marked ready; failed = %v", store.failed)
+	}
+	if got.Video != "/media/videos/7.mp4" || got.Thumbnail != "/media/thumbnails/7.jpg" {
+		t.Errorf("urls = %q, %q", got.Video, got.Thumbnail)
+	}
+	if got.Duration != 12.5 || got.Width != 1080 || got.Height != 1920 || got.Codec != "h264" {
+		t.Errorf("media = %+v", got)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "videos", "7.mp4")); err != nil {
+		t.Errorf("video not stored: %v", err)
+	}
+	if _, err := os.Stat(upload); !os.IsNotExist(err) {
+		t.Errorf("temp upload was not removed: %v", err)
+	}
+}
+
+func TestProcessorMarksPostFailed(t *testing.T) {
+	files, err := storage.NewLocal(t.TempDir(), "/media")
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := newFakeStore()
+
+	p := NewProcessor(store, files, fakeProber{err: ErrNoVideoStream}, Config{Workers: 1, QueueSize: 1})
+	if err := p.Enqueue(Job{PostID: 3, Path: writeUpload(t)}); err != nil {
+		t.Fatalf("Enqueue: %v", err)
+	}
+	p.Close()
+
+	if reason := store.failed[3]; reason != ErrNoVideoStream.Error() {
+		t.Errorf("failure reason = %q", reason)
+	}
+	if _, ok := store.ready[3]; ok {
+		t.Error("failed post was marked ready")
+	}
+
+	if err := p.Enqueue(Job{PostID: 4}); !errors.Is(err, ErrClosed) {
+		t.Errorf("Enqueue after Close = %v, want ErrClosed", err)
+	}
+}
+
+func TestParseProbe(t *testing.T) {
+	raw := []byte(`{"streams":[{"codec_name":"hevc","width":720,"height":1280}],"format":{"duration":"8.041000"}}`)
+	info, err := parseProbe(raw)
+	if err != nil {
+		t.Fatalf("parseProbe: %v", err)
+	}
+	if info.Codec != "hevc" || info.Width != 720 || info.Height != 1280 || info.Duration != 8.041 {
+		t.Errorf("info = %+v", info)
+	}
+
+	if _, err := parseProbe([]byte(`{"streams":[],"format":{"duration":"3"}}`)); !errors.Is(err, ErrNoVideoStream) {
+		t.Errorf("audio-only file error = %v, want ErrNoVideoStream", err)
+	}
+}
@@ -0,0 +1,132 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+var ErrNoVideoStream = errors.New("file has no video stream")
+
+// Info describes the first video stream of a file.
+type Info struct {
+	Duration float64 // seconds
+	Width    int
+	Height   int
+	Codec    string
+}
+
+// Prober inspects videos and renders thumbnails.
+type Prober interface {
+	Probe(ctx context.Context, path string) (Info, error)
+	Thumbnail(ctx context.Context, path, out string, at float64) error
+}
+
+// FFmpeg shells out to the ffprobe and ffmpeg binaries.
+type FFmpeg struct {
+	FFprobePath string
+	FFmpegPath  string
+}
+
+func (f FFmpeg) Probe(ctx context.Context, path string) (Info, error) {
+	bin := f.FFprobePath
+	if bin == "" {
+		bin = "ffprobe"
+	}
+
+	out, err := run(ctx, bin,
+		"-v", "error",
+		"-select_streams", "v:0",
+		"-show_entries", "stream=codec_name,width,height,duration:format=duration",
+		"-of", "json",
+		path,
+	)
+	if err != nil {
+		return Info{}, fmt.Errorf("ffprobe: %w", err)
+	}
+	return parseProbe(out)
+}
+
+func (f FFmpeg) Thumbnail(ctx context.Context, path, out string, at float64) error {
+	bin := f.FFmpegPath
+	if bin == "" {
+		bin = "ffmpeg"
+	}
+
+	_, err := run(ctx, bin,
+		"-v", "error",
+		"-y",
+		"-ss", strconv.FormatFloat(at, 'f', 3, 64),
+		"-i", path,
+		"-frames:v", "1",
+		"-vf", "scale=480:-2",
+		out,
+	)
+	if err != nil {
+		return fmt.Errorf("ffmpeg thumbnail: %w", err)
+	}
+	return nil
+}
+
+func run(ctx context.Context, bin string, args ...string) ([]byte, error) {
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, bin, args...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%w: %s", err, msg)
+		}
+		return nil, err
+	}
+	return stdout.Bytes(), nil
+}
+
+type probeOutput struct {
+	Streams []struct {
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+		Duration  string `json:"duration"`
+	} `json:"streams"`
+	Format struct {
+		Duration string `json:"duration"`
+	} `json:"format"`
+}
+
+// parseProbe reads ffprobe's JSON output. The container duration is preferred
+// since some codecs do not report one per stream.
+func parseProbe(raw []byte) (Info, error) {
+	var out probeOutput
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return Info{}, fmt.Errorf("parse ffprobe output: %w", err)
+	}
+	if len(out.Streams) == 0 {
+		return Info{}, ErrNoVideoStream
+	}
+
+	stream := out.Streams[0]
+	info := Info{
+		Width:  stream.Width,
+		Height: stream.Height,
+		Codec:  stream.CodecName,
+	}
+
+	for _, candidate := range []string{out.Format.Duration, stream.Duration} {
+		if d, err := strconv.ParseFloat(candidate, 64); err == nil && d > 0 {
+			info.Duration = d
+			break
+		}
+	}
+	if info.Duration == 0 {
+		return Info{}, errors.New("could not determine video duration")
+	}
+
+	return info, nil
+}
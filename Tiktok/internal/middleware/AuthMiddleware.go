@@ -2,9 +2,9 @@ package middleware
 
 import (
 	"Tiktok/internal/utils"
-	"fmt"
 	"strings"
 
+	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -12,8 +12,11 @@ func AuthRequired() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		authHeader := c.Get("Authorization")
 
-		// Add debug logging
-		fmt.Printf("Auth Header: %s\n", authHeader)
+		// Browsers can't set headers on a WebSocket handshake, so sockets
+		// may pass the token as a query parameter instead.
+		if authHeader == "" && websocket.IsWebSocketUpgrade(c) && c.Query("token") != "" {
+			authHeader = "Bearer " + c.Query("token")
+		}
 
 		if authHeader == "" {
 			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
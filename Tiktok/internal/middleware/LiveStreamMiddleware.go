@@ -1,23 +1,36 @@
 package middleware
 
 import (
+	"Tiktok/internal/database"
 	"Tiktok/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-func CreateLiveStream() fiber.Handler {
+// MinLiveStreamFollowers is the follower count required to go live.
+const MinLiveStreamFollowers = 1000
+
+func CreateLiveStream(db database.Service) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		claims, ok := c.Locals("user").(*utils.Claims)
 		if !ok || claims == nil {
 			return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "Invalid or missing authentication", "")
 		}
 
-		// Check if user has more than 1000 followers
-		if claims.FollowerCount < 1000 {
-			return utils.SendErrorResponse(c, fiber.StatusForbidden, "You need at least 1000 followers to start a live stream", "")
+		// The follower count baked into the token is only a snapshot from
+		// login, so check the current value.
+		user, err := db.FindUserById(uint(claims.UserID))
+		if err != nil {
+			return utils.SendErrorResponse(c, fiber.StatusUnauthorized, "User not found", err.Error())
+		}
+
+		if user.FollowerCount < MinLiveStreamFollowers {
+			return utils.SendErrorResponse(c, fiber.StatusForbidden, "You need at least 1000 followers to start a live stream", fiber.Map{
+				"follower_count": user.FollowerCount,
+				"required":       MinLiveStreamFollowers,
+			})
 		}
 
-		return c.SendStatus(fiber.StatusOK)
+		return c.Next()
 	}
 }
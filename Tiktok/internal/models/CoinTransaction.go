@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Reasons a wallet balance changed.
+const (
+	CoinTopUp        = "top_up"
+	CoinGiftSent     = "gift_sent"
+	CoinGiftReceived = "gift_received"
+	// CoinOpeningBalance records a balance held from before the ledger existed.
+	CoinOpeningBalance = "opening_balance"
+)
+
+// CoinTransaction is one row of the wallet ledger. Rows are only ever
+// inserted, in the same transaction as the balance change they record, so a
+// user's balance always equals the sum of their Amounts.
+type CoinTransaction struct {
+	ID           uint    `gorm:"primaryKey;autoIncrement"`
+	UserID       uint    `gorm:"not null;index"`
+	User         User    `gorm:"foreignKey:UserID" json:"-"`
+	Kind         string  `gorm:"size:20;not null"`
+	Amount       int64   `gorm:"not null"` // signed change in coins
+	BalanceAfter int64   `gorm:"not null"`
+	GiftID       *uint   // set for gift_sent and gift_received
+	PaymentRef   *string `gorm:"size:255;uniqueIndex"` // set for top_up; unique so a payment is credited once
+	CreatedAt    time.Time
+}
@@ -4,8 +4,8 @@ import "time"
 
 type Follow struct {
 	ID          uint `gorm:"primaryKey;autoIncrement"`
-	FollowerID  uint // User who follows
-	FollowingID uint // User being followed
+	FollowerID  uint `gorm:"uniqueIndex:idx_follows_pair"` // User who follows
+	FollowingID uint `gorm:"uniqueIndex:idx_follows_pair"` // User being followed
 	Follower    User `gorm:"foreignKey:FollowerID"`
 	Following   User `gorm:"foreignKey:FollowingID"`
 	CreatedAt   time.Time
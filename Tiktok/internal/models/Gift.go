@@ -2,14 +2,30 @@ package models
 
 import "time"
 
+// GiftItem is an entry in the gift catalogue viewers can send to a streamer.
+type GiftItem struct {
+	ID        uint   `gorm:"primaryKey;autoIncrement"`
+	Name      string `gorm:"size:50;uniqueIndex"`
+	Icon      string `gorm:"size:255"`
+	Price     int64  `gorm:"not null"` // in coins
+	Active    bool   `gorm:"default:true"`
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
 type Gift struct {
 	ID           uint `gorm:"primaryKey;autoIncrement"`
 	UserID       uint // sender
-	LiveStreamID uint
+	RecipientID  uint `gorm:"index"`
+	LiveStreamID uint `gorm:"index"`
+	GiftItemID   uint
 	User         User       `gorm:"foreignKey:UserID"`
+	Recipient    User       `gorm:"foreignKey:RecipientID"`
 	LiveStream   LiveStream `gorm:"foreignKey:LiveStreamID"`
-	GiftType     string     `gorm:"size:50"`
-	Amount       float64
+	GiftItem     GiftItem   `gorm:"foreignKey:GiftItemID"`
+	GiftType     string     `gorm:"size:50"` // catalogue name at the time of sending
+	Quantity     int        `gorm:"not null;default:1"`
+	Coins        int64      `gorm:"not null"` // total charged to the sender
 	CreatedAt    time.Time
 	UpdatedAt    time.Time
 }
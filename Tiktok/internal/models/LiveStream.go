@@ -2,20 +2,27 @@ package models
 
 import "time"
 
+// Lifecycle of a live stream.
+const (
+	LiveStreamBackstage = "backstage"
+	LiveStreamLive      = "live"
+	LiveStreamEnded     = "ended"
+)
+
 type LiveStream struct {
-	ID          uint `gorm:"primaryKey;autoIncrement"`
-	UserID      uint
+	ID          uint   `gorm:"primaryKey;autoIncrement"`
+	UserID      uint   `gorm:"index"`
 	User        User   `gorm:"foreignKey:UserID"`
 	Title       string `gorm:"size:255"`
 	Description string `gorm:"size:1000"`
-	StreamKey   string `gorm:"unique;size:255"`
-	Status      string `gorm:"default:'offline'"` // offline, live, ended
+	StreamKey   string `gorm:"size:1024" json:"-"`
+	Status      string `gorm:"size:20;default:'backstage';index"` // backstage, live, ended
 	ViewerCount uint   `gorm:"default:0"`
 	IsPublic    bool   `gorm:"default:false"`
-	RtmpUrl     string `gorm:"size:255"`
-	CallID      string // Stream's call ID
-	StartedAt   time.Time
-	EndedAt     time.Time
+	RtmpUrl     string `gorm:"size:255" json:"-"`
+	CallID      string `gorm:"uniqueIndex;size:64"` // Stream's call ID
+	StartedAt   *time.Time
+	EndedAt     *time.Time
 	CreatedAt   time.Time
 	UpdatedAt   time.Time
 	Comments    []LiveStreamComment `gorm:"foreignKey:LiveStreamID"`
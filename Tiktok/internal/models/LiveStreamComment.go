@@ -5,7 +5,7 @@ import "time"
 type LiveStreamComment struct {
 	ID           uint `gorm:"primaryKey;autoIncrement"`
 	UserID       uint
-	LiveStreamID uint
+	LiveStreamID uint       `gorm:"index"`
 	User         User       `gorm:"foreignKey:UserID"`
 	LiveStream   LiveStream `gorm:"foreignKey:LiveStreamID"`
 	Text         string     `gorm:"size:255"`
@@ -2,15 +2,23 @@ package models
 
 import "time"
 
+const (
+	NotificationLike    = "like"
+	NotificationComment = "comment"
+	NotificationFollow  = "follow"
+	NotificationGift    = "gift"
+)
+
 type Notification struct {
 	ID        uint   `gorm:"primaryKey;autoIncrement"`
-	UserID    uint   // recipient
+	UserID    uint   `gorm:"index:idx_notifications_user_read"` // recipient
 	FromID    uint   // sender
+	PostID    *uint  // post the notification is about, if any
 	User      User   `gorm:"foreignKey:UserID"`
 	From      User   `gorm:"foreignKey:FromID"`
 	Type      string `gorm:"size:50"` // like, comment, follow, gift
 	Content   string `gorm:"size:255"`
-	Read      bool   `gorm:"default:false"`
+	Read      bool   `gorm:"default:false;index:idx_notifications_user_read"`
 	CreatedAt time.Time
 	UpdatedAt time.Time
 }
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+// PostView remembers when a user last had a view of a post counted, so
+// repeat views inside the dedup window don't inflate ViewCount.
+type PostView struct {
+	UserID   uint `gorm:"primaryKey;autoIncrement:false"`
+	PostID   uint `gorm:"primaryKey;autoIncrement:false"`
+	ViewedAt time.Time
+}
@@ -2,6 +2,13 @@ package models
 
 import "time"
 
+// Processing states for an uploaded video.
+const (
+	PostStatusProcessing = "processing"
+	PostStatusReady      = "ready"
+	PostStatusFailed     = "failed"
+)
+
 type Post struct {
 	ID        uint `gorm:"primaryKey;autoIncrement"`
 	UserID    uint
@@ -21,4 +28,11 @@ type Post struct {
 	IsPrivate  bool      `gorm:"default:false"`
 	Music      string    `gorm:"size:255"` // Background music/sound
 	Location   string    `gorm:"size:255"`
+	// Filled in by the video processing pipeline
+	Status          string `gorm:"size:20;default:'ready';index"`
+	ProcessingError string `gorm:"size:255"`
+	Thumbnail       string `gorm:"size:255"`
+	Width           int
+	Height          int
+	Codec           string `gorm:"size:50"`
 }
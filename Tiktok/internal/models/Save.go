@@ -0,0 +1,13 @@
+package models
+
+import "time"
+
+// Save is a post a user has added to their collection.
+type Save struct {
+	ID        uint `gorm:"primaryKey;autoIncrement"`
+	UserID    uint `gorm:"uniqueIndex:idx_saves_pair"`
+	PostID    uint `gorm:"uniqueIndex:idx_saves_pair"`
+	User      User `gorm:"foreignKey:UserID" json:"-"`
+	Post      Post `gorm:"foreignKey:PostID"`
+	CreatedAt time.Time
+}
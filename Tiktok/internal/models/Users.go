@@ -10,7 +10,7 @@ type User struct {
 	Email          string `gorm:"unique"`
 	EmailVerified  bool   `gorm:"default:false"`
 	Password       string `gorm:"not null" json:"-"`
-	Token          string `gorm:"not null;size:255"`
+	Token          string `gorm:"not null;size:255" json:"-"` // email verification / password reset token
 	CreatedAt      time.Time
 	UpdatedAt      time.Time
 	Posts          []Post         `gorm:"foreignKey:UserID"`
@@ -18,7 +18,7 @@ type User struct {
 	Likes          []Like         `gorm:"foreignKey:UserID"`
 	FollowerCount  uint           `gorm:"default:0"`
 	FollowingCount uint           `gorm:"default:0"`
-	Coins          float64        `gorm:"default:0"` // Virtual currency for gifts
+	Coins          int64          `gorm:"not null;default:0" json:"-"` // Wallet balance in whole coins, the smallest unit
 	IsVerified     bool           `gorm:"default:false"`
 	LiveStreams    []LiveStream   `gorm:"foreignKey:UserID"`
 	Followers      []Follow       `gorm:"foreignKey:FollowingID"`
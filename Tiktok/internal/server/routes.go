@@ -3,6 +3,8 @@ package server
 import (
 	controllers "Tiktok/internal/controller"
 	"Tiktok/internal/middleware"
+	"Tiktok/internal/storage"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -19,9 +21,22 @@ func (s *FiberServer) RegisterFiberRoutes() {
 	}))
 
 	// Initialize controllers
-	postController := controllers.NewPostController(s.db) // 🎮 New post controller ready for action!
+	postController := controllers.NewPostController(s.db, s.storage, s.processor) // 🎮 New post controller ready for action!
 
-	authController := controllers.NewAuthController(s.db)
+	authController := controllers.NewAuthController(s.db, s.storage)
+
+	feedController := controllers.NewFeedController(s.db)
+
+	followController := controllers.NewFollowController(s.db)
+	userController := controllers.NewUserController(s.db)
+	likeController := controllers.NewLikController(s.db)
+	commentController := controllers.NewCommentController(s.db)
+	notificationController := controllers.NewNotificationController(s.db)
+	hashtagController := controllers.NewHashtagController(s.db)
+	liveStreamController := controllers.NewLiveStreamController(s.db, s.streaming, s.chat)
+	liveChatController := controllers.NewLiveChatController(s.db, s.chat)
+	walletController := controllers.NewWalletController(s.db, s.payments)
+	engagementController := controllers.NewEngagementController(s.db, s.counters)
 
 	auth := s.App.Group("/auth")
 	auth.Post("/register", authController.Register)
@@ -41,6 +56,73 @@ func (s *FiberServer) RegisterFiberRoutes() {
 	posts.Post("/create", postController.CreatePost) // 🎬 Create amazing new posts
 	posts.Delete("/delete/:id", postController.DeletePost)
 	posts.Put("/edit/:id", postController.UpdatePost)
+	posts.Get("/:id/status", postController.GetPostStatus)
+	posts.Post("/like/:id", likeController.LikeVideos)
+	posts.Post("/comment/:id", commentController.CommentPost)
+	posts.Post("/:id/view", engagementController.RecordView)
+	posts.Post("/:id/share", engagementController.RecordShare)
+	posts.Post("/:id/save", engagementController.SavePost)
+	posts.Delete("/:id/save", engagementController.UnsavePost)
+
+	// Comment routes
+	comments := api.Group("/comments")
+	comments.Put("/edit/:id", commentController.UpdateCommentPost)
+	comments.Delete("/delete/:id", commentController.DeleteComment)
+
+	// User profiles and follows
+	users := api.Group("/users")
+	users.Post("/follow/:id", followController.FollowUser)
+	users.Get("/:id", userController.GetProfile)
+	users.Get("/:id/followers", userController.GetFollowers)
+	users.Get("/:id/following", userController.GetFollowing)
+
+	// Current user
+	me := api.Group("/me")
+	me.Put("/", authController.EditUser)
+	me.Get("/saved", engagementController.GetSavedPosts)
+
+	// Hashtags
+	hashtags := api.Group("/hashtags")
+	hashtags.Get("/trending", hashtagController.TrendingHashtags)
+	hashtags.Get("/search", hashtagController.SearchHashtags)
+	hashtags.Get("/:name/posts", hashtagController.GetHashtagPosts)
+
+	// Live streams
+	live := api.Group("/live")
+	live.Get("/", liveStreamController.ListLiveStreams)
+	live.Post("/start", middleware.CreateLiveStream(s.db), liveStreamController.StartStream)
+	live.Get("/:id", liveStreamController.GetLiveStream)
+	live.Post("/:id/go-live", liveStreamController.GoLive)
+	live.Post("/:id/end", liveStreamController.EndStream)
+	live.Get("/:id/ws", liveChatController.Upgrade, liveChatController.Chat())
+
+	// Wallet
+	wallet := api.Group("/wallet")
+	wallet.Get("/", walletController.GetWallet)
+	wallet.Get("/transactions", walletController.GetTransactions)
+	wallet.Get("/packages", walletController.GetPackages)
+	if s.payments != nil {
+		wallet.Post("/top-up", walletController.TopUp)
+	}
+	wallet.Get("/gifts", walletController.GetGifts)
+
+	// Notifications
+	notifications := api.Group("/notifications")
+	notifications.Get("/", notificationController.GetNotifications)
+	notifications.Get("/unread-count", notificationController.GetUnreadCount)
+	notifications.Patch("/read-all", notificationController.MarkAllRead)
+	notifications.Patch("/:id/read", notificationController.MarkRead)
+
+	// Feeds
+	feeds := api.Group("/feed")
+	feeds.Get("/following", feedController.FollowingFeed)
+	feeds.Get("/foryou", feedController.ForYouFeed)
+
+	// Media written by the local storage backend is served from disk
+	if local, ok := s.storage.(*storage.Local); ok && strings.HasPrefix(local.BaseURL, "/") {
+		s.App.Static(local.BaseURL, local.Dir)
+	}
+
 	s.App.Get("/", s.HelloWorldHandler)
 
 	s.App.Get("/health", s.healthHandler)
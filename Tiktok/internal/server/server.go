@@ -1,26 +1,82 @@
 package server
 
 import (
+	"log"
+	"os"
+
 	"github.com/gofiber/fiber/v2"
 
 	"Tiktok/internal/database"
+	"Tiktok/internal/engagement"
+	"Tiktok/internal/livechat"
+	"Tiktok/internal/livestream"
+	"Tiktok/internal/media"
+	"Tiktok/internal/storage"
+	"Tiktok/internal/wallet"
 )
 
 type FiberServer struct {
 	*fiber.App
 
-	db database.Service
+	db        database.Service
+	storage   storage.Storage
+	processor *media.Processor
+	streaming livestream.Provider
+	chat      *livechat.Registry
+	payments  wallet.Payments
+	counters  *engagement.Buffer
 }
 
 func New() *FiberServer {
+	db := database.New()
+
+	files, err := storage.FromEnv()
+	if err != nil {
+		log.Fatalf("failed to initialise media storage: %v", err)
+	}
+
+	streaming, err := livestream.ProviderFromEnv()
+	if err != nil {
+		log.Fatalf("failed to initialise streaming provider: %v", err)
+	}
+
+	payments, err := wallet.PaymentsFromEnv()
+	if err != nil {
+		log.Fatalf("failed to initialise payment provider: %v", err)
+	}
+
+	prober := media.FFmpeg{
+		FFprobePath: os.Getenv("FFPROBE_PATH"),
+		FFmpegPath:  os.Getenv("FFMPEG_PATH"),
+	}
+
 	server := &FiberServer{
 		App: fiber.New(fiber.Config{
 			ServerHeader: "Tiktok",
 			AppName:      "Tiktok",
+			BodyLimit:    110 * 1024 * 1024, // videos may be up to 100MB
 		}),
 
-		db: database.New(),
+		db:        db,
+		storage:   files,
+		processor: media.NewProcessor(db, files, prober, media.ConfigFromEnv()),
+		streaming: streaming,
+		chat:      livechat.NewRegistry(db),
+		payments:  payments,
+		counters:  engagement.NewBuffer(db, engagement.ConfigFromEnv()),
 	}
 
 	return server
 }
+
+// Counters exposes the engagement counter buffer so main can flush it on
+// shutdown.
+func (s *FiberServer) Counters() *engagement.Buffer {
+	return s.counters
+}
+
+// Processor exposes the video processing queue so main can drain it on
+// shutdown.
+func (s *FiberServer) Processor() *media.Processor {
+	return s.processor
+}
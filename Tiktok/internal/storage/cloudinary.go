@@ -0,0 +1,102 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"github.com/cloudinary/cloudinary-go/v2"
+	"github.com/cloudinary/cloudinary-go/v2/api"
+	"github.com/cloudinary/cloudinary-go/v2/api/uploader"
+)
+
+// Cloudinary stores files in a Cloudinary folder. The storage key, minus its
+// extension, becomes the public ID so files can be found again on delete.
+type Cloudinary struct {
+	cld    *cloudinary.Cloudinary
+	folder string
+}
+
+func NewCloudinary(cld *cloudinary.Cloudinary, folder string) *Cloudinary {
+	return &Cloudinary{cld: cld, folder: folder}
+}
+
+func (s *Cloudinary) Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error) {
+	resourceType := "raw"
+	switch {
+	case strings.HasPrefix(contentType, "video/"):
+		resourceType = "video"
+	case strings.HasPrefix(contentType, "image/"):
+		resourceType = "image"
+	}
+
+	resp, err := s.cld.Upload.Upload(ctx, r, uploader.UploadParams{
+		PublicID:     strings.TrimSuffix(key, path.Ext(key)),
+		Folder:       s.folder,
+		Overwrite:    api.Bool(true),
+		ResourceType: resourceType,
+	})
+	if err != nil {
+		return "", err
+	}
+	if resp.Error.Message != "" {
+		return "", fmt.Errorf("cloudinary upload failed: %s", resp.Error.Message)
+	}
+
+	return resp.SecureURL, nil
+}
+
+func (s *Cloudinary) Delete(ctx context.Context, url string) error {
+	publicID, resourceType, err := cloudinaryAsset(url)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.cld.Upload.Destroy(ctx, uploader.DestroyParams{
+		PublicID:     publicID,
+		ResourceType: resourceType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete %s from Cloudinary: %w", publicID, err)
+	}
+	// "not found" means it is already gone, which is what we wanted.
+	if resp.Result != "ok" && resp.Result != "not found" {
+		return fmt.Errorf("failed to delete %s from Cloudinary, response: %v", publicID, resp.Result)
+	}
+	return nil
+}
+
+// cloudinaryAsset extracts the public ID and resource type from a delivery URL
+// such as https://res.cloudinary.com/<cloud>/video/upload/v123/folder/id.mp4.
+func cloudinaryAsset(url string) (publicID, resourceType string, err error) {
+	const host = "https://res.cloudinary.com/"
+	if !strings.HasPrefix(url, host) {
+		return "", "", ErrNotOwned
+	}
+
+	// <cloud>/<resource type>/upload/[transformations/][v<version>/]<public id>.<ext>
+	parts := strings.Split(strings.TrimPrefix(url, host), "/")
+	if len(parts) < 4 || parts[2] != "upload" {
+		return "", "", fmt.Errorf("invalid Cloudinary URL format: %s", url)
+	}
+	resourceType = parts[1]
+
+	rest := parts[3:]
+	for i, segment := range rest {
+		if len(segment) > 1 && segment[0] == 'v' && strings.Trim(segment[1:], "0123456789") == "" {
+			rest = rest[i+1:]
+			break
+		}
+	}
+	if len(rest) == 0 {
+		return "", "", fmt.Errorf("invalid Cloudinary URL format: %s", url)
+	}
+
+	publicID = strings.Join(rest, "/")
+	if resourceType != "raw" {
+		publicID = strings.TrimSuffix(publicID, path.Ext(publicID))
+	}
+	return publicID, resourceType, nil
+}
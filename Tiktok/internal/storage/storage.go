@@ -0,0 +1,66 @@
+// Package storage abstracts where uploaded media ends up, so the processing
+// pipeline can write to Cloudinary in production and to the local disk in
+// development and tests.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"Tiktok/internal/config"
+)
+
+var ErrNotOwned = errors.New("url does not belong to this storage backend")
+
+// Storage persists media files and hands back the public URL they are served
+// from.
+type Storage interface {
+	// Put stores r under key (e.g. "videos/42.mp4") and returns its public URL.
+	Put(ctx context.Context, key string, r io.Reader, contentType string) (string, error)
+	// Delete removes a file previously returned by Put. URLs that the
+	// backend did not produce yield ErrNotOwned.
+	Delete(ctx context.Context, url string) error
+}
+
+const (
+	defaultLocalDir = "./uploads"
+	defaultLocalURL = "/media"
+)
+
+// FromEnv picks the backend from STORAGE_BACKEND ("local" or "cloudinary").
+// When unset, Cloudinary is used if CLOUDINARY_NAME is configured and the
+// local filesystem otherwise.
+func FromEnv() (Storage, error) {
+	backend := strings.ToLower(os.Getenv("STORAGE_BACKEND"))
+	if backend == "" {
+		backend = "local"
+		if os.Getenv("CLOUDINARY_NAME") != "" {
+			backend = "cloudinary"
+		}
+	}
+
+	switch backend {
+	case "local":
+		dir := os.Getenv("LOCAL_STORAGE_DIR")
+		if dir == "" {
+			dir = defaultLocalDir
+		}
+		baseURL := os.Getenv("LOCAL_STORAGE_URL")
+		if baseURL == "" {
+			baseURL = defaultLocalURL
+		}
+		return NewLocal(dir, baseURL)
+	case "cloudinary":
+		cld, err := config.InitCloudinary()
+		if err != nil {
+			return nil, err
+		}
+		return NewCloudinary(cld, "tiktok-clone"), nil
+	default:
+		return nil, fmt.Errorf("unknown STORAGE_BACKEND %q", backend)
+	}
+}
@@ -0,0 +1,58 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalPutAndDelete(t *testing.T) {
+	dir := t.TempDir()
+	local, err := NewLocal(dir, "/media/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	url, err := local.Put(ctx, "videos/1.mp4", strings.NewReader("data"), "video/mp4")
+	if err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	if url != "/media/videos/1.mp4" {
+		t.Errorf("url = %q", url)
+	}
+	if b, err := os.ReadFile(filepath.Join(dir, "videos", "1.mp4")); err != nil || string(b) != "data" {
+		t.Fatalf("stored file = %q, %v", b, err)
+	}
+
+	if err := local.Delete(ctx, url); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "videos", "1.mp4")); !os.IsNotExist(err) {
+		t.Errorf("file still exists: %v", err)
+	}
+
+	if err := local.Delete(ctx, "https://res.cloudinary.com/x/video/upload/v1/a.mp4"); !errors.Is(err, ErrNotOwned) {
+		t.Errorf("Delete foreign url = %v, want ErrNotOwned", err)
+	}
+	if _, err := local.Put(ctx, "../escape.txt", strings.NewReader("x"), "text/plain"); err == nil {
+		t.Error("Put accepted a key outside the storage dir")
+	}
+}
+
+func TestCloudinaryAsset(t *testing.T) {
+	id, kind, err := cloudinaryAsset("https://res.cloudinary.com/demo/video/upload/v1712345/tiktok-clone/videos/42.mp4")
+	if err != nil {
+		t.Fatalf("cloudinaryAsset: %v", err)
+	}
+	if id != "tiktok-clone/videos/42" || kind != "video" {
+		t.Errorf("got %q (%s)", id, kind)
+	}
+
+	if _, _, err := cloudinaryAsset("/media/videos/42.mp4"); !errors.Is(err, ErrNotOwned) {
+		t.Errorf("local url error = %v, want ErrNotOwned", err)
+	}
+}
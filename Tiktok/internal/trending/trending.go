@@ -0,0 +1,65 @@
+// Package trending ranks hashtags by how fast their usage is growing.
+package trending
+
+import (
+	"sort"
+	"time"
+)
+
+const (
+	// RecentWindow is the window whose usage is compared against the baseline.
+	RecentWindow = 24 * time.Hour
+	// BaselineWindow is the stretch before RecentWindow that defines "normal".
+	BaselineWindow = 7 * 24 * time.Hour
+	// MinRecentUses keeps a tag used once or twice from topping the chart on
+	// the strength of a zero baseline.
+	MinRecentUses = 3
+)
+
+// Usage is how often a tag was used in each window.
+type Usage struct {
+	Name     string `json:"name"`
+	Recent   int64  `json:"recent_uses"`
+	Previous int64  `json:"previous_uses"`
+}
+
+type Trend struct {
+	Usage
+	Velocity float64 `json:"velocity"`
+}
+
+// Velocity compares the recent usage rate with the baseline rate, both
+// expressed per RecentWindow. Add-one smoothing keeps brand new tags finite
+// while still letting them outrank steady ones.
+func Velocity(u Usage) float64 {
+	baseline := float64(u.Previous) * float64(RecentWindow) / float64(BaselineWindow)
+	return (float64(u.Recent) + 1) / (baseline + 1)
+}
+
+// Rank returns up to limit tags that meet MinRecentUses, fastest growing
+// first. Ties go to the tag with more recent uses, then alphabetically.
+func Rank(usages []Usage, limit int) []Trend {
+	trends := make([]Trend, 0, len(usages))
+	for _, u := range usages {
+		if u.Recent < MinRecentUses {
+			continue
+		}
+		trends = append(trends, Trend{Usage: u, Velocity: Velocity(u)})
+	}
+
+	sort.Slice(trends, func(i, j int) bool {
+		a, b := trends[i], trends[j]
+		if a.Velocity != b.Velocity {
+			return a.Velocity > b.Velocity
+		}
+		if a.Recent != b.Recent {
+			return a.Recent > b.Recent
+		}
+		return a.Name < b.Name
+	})
+
+	if limit > 0 && len(trends) > limit {
+		trends = trends[:limit]
+	}
+	return trends
+}
@@ -0,0 +1,33 @@
+package trending
+
+import "testing"
+
+func TestRankFavoursGrowthOverVolume(t *testing.T) {
+	usages := []Usage{
+		{Name: "fyp", Recent: 700, Previous: 4900}, // big but flat
+		{Name: "newdance", Recent: 40, Previous: 7},
+		{Name: "cooking", Recent: 30, Previous: 70},
+		{Name: "typo", Recent: 2, Previous: 0}, // below MinRecentUses
+	}
+
+	got := Rank(usages, 10)
+
+	want := []string{"newdance", "cooking", "fyp"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d trends, want %d: %+v", len(got), len(want), got)
+	}
+	for i, name := range want {
+		if got[i].Name != name {
+			t.Fatalf("position %d = %s, want %s (%+v)", i, got[i].Name, name, got)
+		}
+	}
+}
+
+func TestRankLimit(t *testing.T) {
+	usages := []Usage{{Name: "a", Recent: 5}, {Name: "b", Recent: 5}, {Name: "c", Recent: 9}}
+
+	got := Rank(usages, 2)
+	if len(got) != 2 || got[0].Name != "c" || got[1].Name != "a" {
+		t.Errorf("Rank = %+v", got)
+	}
+}
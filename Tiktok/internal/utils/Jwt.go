@@ -24,7 +24,7 @@ type Claims struct {
 	jwt.RegisteredClaims
 }
 
-func GenerateToken(userID int, email, name string, avatar string, token string, bio string, is_verified bool, following_count uint, follow_count uint) (string, error) {
+func GenerateToken(userID int, email, name string, avatar string, token string, bio string, is_verified bool, follower_count uint, following_count uint) (string, error) {
 	claims := Claims{
 		UserID:         userID,
 		Email:          email,
@@ -32,7 +32,7 @@ func GenerateToken(userID int, email, name string, avatar string, token string,
 		Avatar:         avatar,
 		JWTToken:       token,
 		Bio:            bio,
-		FollowerCount:  follow_count,
+		FollowerCount:  follower_count,
 		FollowingCount: following_count,
 		IsVerified:     is_verified,
 
@@ -0,0 +1,53 @@
+package wallet
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+)
+
+// DeclinedSource is the payment source the fake always declines, so clients
+// can exercise the failure path.
+const DeclinedSource = "tok_declined"
+
+// Fake approves every charge without moving any money. It honours idempotency
+// keys the way a real provider would.
+type Fake struct {
+	mu       sync.Mutex
+	receipts map[string]Receipt // idempotency key -> receipt
+}
+
+func NewFake() *Fake {
+	return &Fake{receipts: make(map[string]Receipt)}
+}
+
+func (f *Fake) Charge(ctx context.Context, charge Charge) (Receipt, error) {
+	if charge.Amount <= 0 {
+		return Receipt{}, errors.New("charge amount must be positive")
+	}
+	if charge.Source == DeclinedSource {
+		return Receipt{}, ErrPaymentDeclined
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if charge.IdempotencyKey != "" {
+		if receipt, ok := f.receipts[charge.IdempotencyKey]; ok {
+			return receipt, nil
+		}
+	}
+
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return Receipt{}, err
+	}
+	receipt := Receipt{Reference: "fake_" + hex.EncodeToString(id)}
+
+	if charge.IdempotencyKey != "" {
+		f.receipts[charge.IdempotencyKey] = receipt
+	}
+	return receipt, nil
+}
@@ -0,0 +1,84 @@
+// Package wallet covers how users buy coins: the top-up packages on sale and
+// the payment provider that charges for them. Balances themselves live in the
+// database, which debits and credits them transactionally.
+package wallet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+)
+
+var (
+	ErrUnknownPackage  = errors.New("unknown coin package")
+	ErrPaymentDeclined = errors.New("payment declined")
+)
+
+// Package is a bundle of coins sold for a fixed price. Price is in the
+// currency's minor units (cents for USD).
+type Package struct {
+	ID       string `json:"id"`
+	Coins    int64  `json:"coins"`
+	Price    int64  `json:"price"`
+	Currency string `json:"currency"`
+}
+
+// Packages is the top-up catalogue.
+var Packages = []Package{
+	{ID: "coins_70", Coins: 70, Price: 99, Currency: "usd"},
+	{ID: "coins_350", Coins: 350, Price: 499, Currency: "usd"},
+	{ID: "coins_1400", Coins: 1400, Price: 1999, Currency: "usd"},
+	{ID: "coins_7000", Coins: 7000, Price: 9999, Currency: "usd"},
+}
+
+func FindPackage(id string) (Package, error) {
+	for _, p := range Packages {
+		if p.ID == id {
+			return p, nil
+		}
+	}
+	return Package{}, ErrUnknownPackage
+}
+
+// Charge asks the payment provider to take money from a user.
+type Charge struct {
+	UserID   uint
+	Amount   int64 // minor units
+	Currency string
+	// Source is the provider's token for the card or wallet to charge.
+	Source string
+	// IdempotencyKey makes retries of the same purchase return the original
+	// receipt instead of charging twice.
+	IdempotencyKey string
+	Description    string
+}
+
+// Receipt identifies a successful charge at the provider.
+type Receipt struct {
+	Reference string
+}
+
+// Payments is the provider that takes real money for coins.
+type Payments interface {
+	Charge(ctx context.Context, charge Charge) (Receipt, error)
+}
+
+// PaymentsFromEnv picks the provider from PAYMENT_PROVIDER. Only the fake is
+// available so far and it has to be asked for explicitly, since it hands out
+// coins without taking money. When the variable is unset it returns a nil
+// provider and coin top-ups are disabled.
+func PaymentsFromEnv() (Payments, error) {
+	switch provider := strings.ToLower(os.Getenv("PAYMENT_PROVIDER")); provider {
+	case "":
+		log.Println("PAYMENT_PROVIDER not set, coin top-ups are disabled")
+		return nil, nil
+	case "fake":
+		log.Println("PAYMENT_PROVIDER is fake, coin top-ups will not take real payments")
+		return NewFake(), nil
+	default:
+		return nil, fmt.Errorf("unsupported payment provider %q", provider)
+	}
+}
@@ -0,0 +1,74 @@
+package wallet
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestFindPackage(t *testing.T) {
+	p, err := FindPackage("coins_350")
+	if err != nil {
+		t.Fatalf("FindPackage: %v", err)
+	}
+	if p.Coins != 350 || p.Price != 499 {
+		t.Errorf("package = %+v", p)
+	}
+
+	if _, err := FindPackage("coins_1"); !errors.Is(err, ErrUnknownPackage) {
+		t.Errorf("unknown package err = %v, want ErrUnknownPackage", err)
+	}
+}
+
+func TestFakeCharge(t *testing.T) {
+	ctx := context.Background()
+	fake := NewFake()
+
+	first, err := fake.Charge(ctx, Charge{UserID: 1, Amount: 499, Currency: "usd", Source: "tok_visa", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatalf("Charge: %v", err)
+	}
+	if first.Reference == "" {
+		t.Fatal("empty receipt reference")
+	}
+
+	retry, err := fake.Charge(ctx, Charge{UserID: 1, Amount: 499, Currency: "usd", Source: "tok_visa", IdempotencyKey: "k1"})
+	if err != nil {
+		t.Fatalf("retry: %v", err)
+	}
+	if retry != first {
+		t.Errorf("retry receipt = %+v, want %+v", retry, first)
+	}
+
+	other, err := fake.Charge(ctx, Charge{UserID: 1, Amount: 499, Currency: "usd", Source: "tok_visa", IdempotencyKey: "k2"})
+	if err != nil {
+		t.Fatalf("second purchase: %v", err)
+	}
+	if other == first {
+		t.Error("different idempotency keys shared a receipt")
+	}
+
+	if _, err := fake.Charge(ctx, Charge{UserID: 1, Amount: 499, Source: DeclinedSource}); !errors.Is(err, ErrPaymentDeclined) {
+		t.Errorf("declined source err = %v, want ErrPaymentDeclined", err)
+	}
+	if _, err := fake.Charge(ctx, Charge{UserID: 1, Amount: 0, Source: "tok_visa"}); err == nil {
+		t.Error("zero amount was accepted")
+	}
+}
+
+func TestPaymentsFromEnv(t *testing.T) {
+	t.Setenv("PAYMENT_PROVIDER", "")
+	if payments, err := PaymentsFromEnv(); err != nil || payments != nil {
+		t.Errorf("unset provider = %v, %v; want top-ups disabled", payments, err)
+	}
+
+	t.Setenv("PAYMENT_PROVIDER", "fake")
+	if payments, err := PaymentsFromEnv(); err != nil || payments == nil {
+		t.Errorf("fake provider = %v, %v; want the fake", payments, err)
+	}
+
+	t.Setenv("PAYMENT_PROVIDER", "stripe")
+	if _, err := PaymentsFromEnv(); err == nil {
+		t.Error("unsupported provider did not fail")
+	}
+}